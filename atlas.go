@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png"
+	"path"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 方块贴图集（PNG + JSON元数据），编译进二进制文件
+//
+//go:embed assets/blocks.png assets/blocks.json
+var assetsFS embed.FS
+
+// BlockAtlasMetaPath 贴图集元数据在assetsFS中的路径
+const BlockAtlasMetaPath = "assets/blocks.json"
+
+// 连接纹理变体名称（草地边缘）
+const (
+	VariantLeft  = "left"  // 左侧暴露
+	VariantRight = "right" // 右侧暴露
+	VariantBoth  = "both"  // 两侧暴露
+)
+
+// spriteRect 贴图在图集中的左上角像素坐标
+type spriteRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// spriteMeta 单个方块类型的贴图及其变体
+type spriteMeta struct {
+	spriteRect
+	Variants map[string]spriteRect `json:"variants"`
+}
+
+// atlasMeta 贴图集元数据
+type atlasMeta struct {
+	Image    string                `json:"image"`    // 相对于元数据文件的图片路径
+	TileSize int                   `json:"tileSize"` // 每个贴图的边长（像素）
+	Sprites  map[string]spriteMeta `json:"sprites"`  // 以物品名称为键
+}
+
+// BlockAtlas 按方块类型和变体查找贴图
+type BlockAtlas struct {
+	sheet    *ebiten.Image // 整张贴图集，批量绘制时作为唯一纹理
+	tileSize int
+	sprites  map[ItemType]map[string]image.Rectangle // 变体名 -> 贴图区域，空字符串为基础贴图
+}
+
+// loadBlockAtlas 从嵌入的资源中加载方块贴图集
+func loadBlockAtlas() (*BlockAtlas, error) {
+	data, err := assetsFS.ReadFile(BlockAtlasMetaPath)
+	if err != nil {
+		return nil, err
+	}
+	var meta atlasMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", BlockAtlasMetaPath, err)
+	}
+	if meta.TileSize <= 0 {
+		return nil, fmt.Errorf("%s: invalid tile size %d", BlockAtlasMetaPath, meta.TileSize)
+	}
+
+	imgData, err := assetsFS.ReadFile(path.Join(path.Dir(BlockAtlasMetaPath), meta.Image))
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(imgData))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", meta.Image, err)
+	}
+	// 物品名称 -> 类型
+	byName := make(map[string]ItemType, len(itemRegistry))
+	for t, item := range itemRegistry {
+		byName[item.Name] = t
+	}
+
+	atlas := &BlockAtlas{
+		sheet:    ebiten.NewImageFromImage(img),
+		tileSize: meta.TileSize,
+		sprites:  make(map[ItemType]map[string]image.Rectangle),
+	}
+	sub := func(r spriteRect) image.Rectangle {
+		return image.Rect(r.X, r.Y, r.X+meta.TileSize, r.Y+meta.TileSize)
+	}
+	for name, sm := range meta.Sprites {
+		t, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown item %q", BlockAtlasMetaPath, name)
+		}
+		variants := map[string]image.Rectangle{"": sub(sm.spriteRect)}
+		for v, r := range sm.Variants {
+			variants[v] = sub(r)
+		}
+		atlas.sprites[t] = variants
+	}
+	return atlas, nil
+}
+
+// Sprite 返回方块类型的贴图在贴图集中的区域，变体不存在时退回基础贴图，
+// 没有贴图时返回false
+func (a *BlockAtlas) Sprite(t ItemType, variant string) (image.Rectangle, bool) {
+	if a == nil {
+		return image.Rectangle{}, false
+	}
+	variants, ok := a.sprites[t]
+	if !ok {
+		return image.Rectangle{}, false
+	}
+	if r, ok := variants[variant]; ok {
+		return r, true
+	}
+	r, ok := variants[""]
+	return r, ok
+}
+
+// grassVariant 根据左右邻居是否为空气选择草地边缘变体
+func grassVariant(grid PathGrid, x, y int) string {
+	left := !grid.Solid(x-1, y)
+	right := !grid.Solid(x+1, y)
+	switch {
+	case left && right:
+		return VariantBoth
+	case left:
+		return VariantLeft
+	case right:
+		return VariantRight
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 摄像机缩放常量
+const (
+	DefaultZoom = 1.0
+	MinZoom     = 0.25 // 最小缩放
+	MaxZoom     = 3.0  // 最大缩放
+	ZoomStep    = 1.1  // 每次按键或滚轮缩放的倍数
+)
+
+// 摄像机跟随常量（世界像素与帧）
+const (
+	CameraDeadZoneW    = 100.0 // 死区宽度：目标在死区内移动时摄像机不动
+	CameraDeadZoneH    = 80.0  // 死区高度
+	CameraLookAhead    = 20.0  // 前视距离 = 水平速度 × 该帧数
+	CameraMaxLookAhead = 120.0 // 最大前视距离
+	CameraLookLerp     = 0.05  // 前视偏移的平滑速度
+	CameraZoomLerp     = 0.2   // 缩放向目标缩放靠近的速度（按倍数）
+	CameraFallSpeed    = 4.0   // 下落速度超过该值时视野向下偏移
+	CameraFallBias     = 15.0  // 向下偏移 = 下落速度 × 该帧数
+	CameraMaxFallBias  = 150.0 // 最大向下偏移
+
+	CameraTraumaDecay = 0.02 // 每帧衰减的震动强度
+	CameraMaxShake    = 12.0 // 最大震动位移（屏幕像素）
+	CameraMaxRoll     = 0.05 // 最大震动旋转（弧度）
+)
+
+// clampZoom 将缩放限制在[MinZoom, MaxZoom]内
+func clampZoom(zoom float64) float64 {
+	return math.Max(MinZoom, math.Min(MaxZoom, zoom))
+}
+
+// CameraTarget 摄像机跟随的目标（中心位置与速度）
+type CameraTarget struct {
+	X, Y   float64
+	VX, VY float64
+}
+
+// Camera 跟随目标的二维摄像机，支持死区、前视、下落偏移、世界边界和震动
+type Camera struct {
+	X, Y       float64 // 视野中心的世界坐标
+	Zoom       float64 // 当前缩放倍数
+	TargetZoom float64 // 目标缩放倍数，Update中Zoom逐帧向其靠近（为0时不缩放）
+
+	// 跟随参数（NewCamera中设为默认值，可单独调整）
+	DeadZoneW, DeadZoneH float64
+	LookAhead            float64
+	MaxLookAhead         float64
+	FallBias             float64
+	MaxFallBias          float64
+	Lerp                 float64
+
+	// 世界边界，Min >= Max的方向不限制
+	MinX, MinY, MaxX, MaxY float64
+
+	lookX, biasY float64 // 平滑后的前视与下落偏移
+
+	trauma                 float64 // 震动强度（0~1）
+	shakeX, shakeY, shakeR float64 // 本帧的震动位移与旋转
+	rng                    *rand.Rand
+}
+
+// NewCamera 创建以(x, y)为中心的摄像机
+func NewCamera(x, y float64) *Camera {
+	return &Camera{
+		X:            x,
+		Y:            y,
+		Zoom:         DefaultZoom,
+		TargetZoom:   DefaultZoom,
+		DeadZoneW:    CameraDeadZoneW,
+		DeadZoneH:    CameraDeadZoneH,
+		LookAhead:    CameraLookAhead,
+		MaxLookAhead: CameraMaxLookAhead,
+		FallBias:     CameraFallBias,
+		MaxFallBias:  CameraMaxFallBias,
+		Lerp:         CameraLerp,
+		rng:          rand.New(rand.NewSource(1)),
+	}
+}
+
+// SetBounds 设置摄像机视野不能超出的世界边界
+func (c *Camera) SetBounds(minX, minY, maxX, maxY float64) {
+	c.MinX, c.MinY, c.MaxX, c.MaxY = minX, minY, maxX, maxY
+}
+
+// SnapTo 立即移动到指定位置，清除平滑状态
+func (c *Camera) SnapTo(x, y float64) {
+	c.X, c.Y = x, y
+	c.lookX, c.biasY = 0, 0
+}
+
+// AddTrauma 增加震动强度（0~1），震动幅度与强度的平方成正比并逐帧衰减
+func (c *Camera) AddTrauma(amount float64) {
+	c.trauma = math.Min(1, c.trauma+amount)
+}
+
+// Trauma 返回当前震动强度
+func (c *Camera) Trauma() float64 {
+	return c.trauma
+}
+
+// Update 跟随目标前进一帧，viewW/viewH为屏幕的逻辑像素尺寸
+func (c *Camera) Update(target CameraTarget, viewW, viewH float64) {
+	// 1. 前视：沿水平速度方向多看一段距离
+	look := clampAbs(target.VX*c.LookAhead, c.MaxLookAhead)
+	c.lookX += (look - c.lookX) * CameraLookLerp
+
+	// 2. 下落时视野向下偏移，便于看到落点
+	bias := 0.0
+	if target.VY > CameraFallSpeed {
+		bias = math.Min(target.VY*c.FallBias, c.MaxFallBias)
+	}
+	c.biasY += (bias - c.biasY) * CameraLookLerp
+
+	// 3. 死区：焦点离开死区时才移动摄像机，使焦点回到死区边缘
+	goalX := deadZone(c.X, target.X+c.lookX, c.DeadZoneW/2)
+	goalY := deadZone(c.Y, target.Y+c.biasY, c.DeadZoneH/2)
+	c.X += (goalX - c.X) * c.Lerp
+	c.Y += (goalY - c.Y) * c.Lerp
+
+	// 4. 缩放平滑地靠近目标
+	c.Zoom = easeZoom(c.Zoom, c.TargetZoom)
+
+	// 5. 限制在世界边界内
+	halfW, halfH := viewW/(2*c.Zoom), viewH/(2*c.Zoom)
+	c.X = clampView(c.X, c.MinX, c.MaxX, halfW)
+	c.Y = clampView(c.Y, c.MinY, c.MaxY, halfH)
+
+	// 6. 震动
+	c.trauma = math.Max(0, c.trauma-CameraTraumaDecay)
+	shake := c.trauma * c.trauma
+	c.shakeX = CameraMaxShake * shake * (c.rng.Float64()*2 - 1)
+	c.shakeY = CameraMaxShake * shake * (c.rng.Float64()*2 - 1)
+	c.shakeR = CameraMaxRoll * shake * (c.rng.Float64()*2 - 1)
+}
+
+// easeZoom 返回zoom按倍数向target靠近一帧后的缩放，足够接近时直接取target
+func easeZoom(zoom, target float64) float64 {
+	if target <= 0 {
+		return zoom
+	}
+	zoom *= math.Pow(target/zoom, CameraZoomLerp)
+	if math.Abs(zoom/target-1) < 0.001 {
+		return target
+	}
+	return zoom
+}
+
+// deadZone 焦点在中心±half内时保持不动，否则返回使焦点恰好位于死区边缘的位置
+func deadZone(center, focus, half float64) float64 {
+	switch {
+	case focus > center+half:
+		return focus - half
+	case focus < center-half:
+		return focus + half
+	default:
+		return center
+	}
+}
+
+// clampView 将视野中心限制在[min, max]内，边界比视野小时居中
+func clampView(center, min, max, half float64) float64 {
+	if min >= max {
+		return center
+	}
+	if max-min <= 2*half {
+		return (min + max) / 2
+	}
+	return math.Max(min+half, math.Min(max-half, center))
+}
+
+// clampAbs 将v限制在[-limit, limit]内
+func clampAbs(v, limit float64) float64 {
+	return math.Max(-limit, math.Min(limit, v))
+}
+
+// GeoM 返回世界坐标到屏幕坐标的变换，screenW/screenH为屏幕的设备像素尺寸，
+// pixelScale为高DPI缩放倍数（方块保持相同的逻辑大小）
+func (c *Camera) GeoM(screenW, screenH, pixelScale float64) ebiten.GeoM {
+	scale := c.Zoom * pixelScale
+	var geoM ebiten.GeoM
+	geoM.Translate(-c.X, -c.Y)
+	geoM.Scale(scale, scale)
+	geoM.Rotate(c.shakeR)
+	geoM.Translate(screenW/2+c.shakeX*pixelScale, screenH/2+c.shakeY*pixelScale)
+	return geoM
+}
+
+// cameraGeoM 返回当前帧世界坐标到屏幕坐标的变换
+func (g *Game) cameraGeoM() ebiten.GeoM {
+	w, h := g.viewSize()
+	return g.camera.GeoM(w, h, g.pixelScale())
+}
+
+// screenToWorld 将屏幕坐标转换为世界坐标
+func (g *Game) screenToWorld(x, y float64) (float64, float64) {
+	inv := g.cameraGeoM()
+	inv.Invert()
+	return inv.Apply(x, y)
+}
+
+// updateCamera 让摄像机跟随玩家
+func (g *Game) updateCamera() {
+	w, h := g.viewSize()
+	scale := g.pixelScale()
+	g.camera.SetBounds(g.worldMinX, g.worldMinY, g.worldMaxX, g.worldMaxY)
+	g.camera.Update(CameraTarget{
+		X:  g.player.CenterX(),
+		Y:  g.player.CenterY(),
+		VX: g.player.VX,
+		VY: g.player.VY,
+	}, w/scale, h/scale)
+}
+
+// setWorldBounds 按生成区块的高度范围设置世界的垂直边界，摄像机不显示最高的树以上的天空
+// 和最深的地形以下；世界水平方向无限，不设边界
+func (g *Game) setWorldBounds() {
+	limits := g.chunkRegion().Limits()
+	g.worldMinX, g.worldMaxX = 0, 0
+	g.worldMinY = float64(limits.MinChunkY * ChunkSize * BlockSize)
+	g.worldMaxY = float64((limits.MaxChunkY + 1) * ChunkSize * BlockSize)
+}
+
+// updateZoom 处理缩放输入：+/-键和Ctrl+滚轮缩放，0键恢复默认
+//
+// 输入只改变目标缩放，摄像机在之后几帧内平滑缩放。
+// 不按Ctrl时滚轮仍用于切换物品栏，返回是否消耗了滚轮输入。
+func (g *Game) updateZoom() bool {
+	zoom := g.camera.TargetZoom
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		zoom *= ZoomStep
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		zoom /= ZoomStep
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key0) {
+		zoom = DefaultZoom
+	}
+
+	wheelUsed := false
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		if _, wheelY := ebiten.Wheel(); wheelY != 0 {
+			zoom *= math.Pow(ZoomStep, wheelY)
+			wheelUsed = true
+		}
+	}
+	g.camera.TargetZoom = clampZoom(zoom)
+	return wheelUsed
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// settle 让摄像机以固定目标更新足够多帧直到稳定
+func settle(c *Camera, target CameraTarget) {
+	for i := 0; i < 1000; i++ {
+		c.Update(target, ScreenWidth, ScreenHeight)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.5
+}
+
+func TestCameraDeadZone(t *testing.T) {
+	c := NewCamera(0, 0)
+
+	// 死区内移动不影响摄像机
+	settle(c, CameraTarget{X: c.DeadZoneW/2 - 1, Y: -(c.DeadZoneH/2 - 1)})
+	if c.X != 0 || c.Y != 0 {
+		t.Fatalf("camera moved to (%.1f, %.1f) for target inside dead zone", c.X, c.Y)
+	}
+
+	// 离开死区后目标停在死区边缘
+	settle(c, CameraTarget{X: 500, Y: -300})
+	if !near(c.X, 500-c.DeadZoneW/2) || !near(c.Y, -300+c.DeadZoneH/2) {
+		t.Fatalf("camera = (%.1f, %.1f), want target on dead zone edge", c.X, c.Y)
+	}
+}
+
+func TestCameraLookAhead(t *testing.T) {
+	c := NewCamera(0, 0)
+	c.DeadZoneW, c.DeadZoneH = 0, 0
+
+	settle(c, CameraTarget{VX: PlayerSpeed})
+	if want := PlayerSpeed * c.LookAhead; !near(c.X, want) {
+		t.Fatalf("look-ahead to the right = %.1f, want %.1f", c.X, want)
+	}
+
+	// 速度很大时不超过最大前视距离
+	settle(c, CameraTarget{VX: -100})
+	if !near(c.X, -c.MaxLookAhead) {
+		t.Fatalf("look-ahead to the left = %.1f, want %.1f", c.X, -c.MaxLookAhead)
+	}
+}
+
+func TestCameraFallBias(t *testing.T) {
+	c := NewCamera(0, 0)
+	c.DeadZoneW, c.DeadZoneH = 0, 0
+
+	// 起跳和缓慢下落不偏移
+	settle(c, CameraTarget{VY: -JumpPower})
+	if !near(c.Y, 0) {
+		t.Fatalf("camera biased to %.1f while rising", c.Y)
+	}
+	settle(c, CameraTarget{VY: CameraFallSpeed})
+	if !near(c.Y, 0) {
+		t.Fatalf("camera biased to %.1f below fall threshold", c.Y)
+	}
+
+	// 快速下落时视野向下（Y增大）偏移，且不超过上限
+	settle(c, CameraTarget{VY: PlayerMaxFall})
+	if want := math.Min(PlayerMaxFall*c.FallBias, c.MaxFallBias); !near(c.Y, want) {
+		t.Fatalf("fall bias = %.1f, want %.1f", c.Y, want)
+	}
+}
+
+func TestCameraBounds(t *testing.T) {
+	c := NewCamera(0, 0)
+	c.DeadZoneW, c.DeadZoneH = 0, 0
+	c.SetBounds(0, 0, 2000, 1000)
+
+	// 视野不能越过左上边界
+	settle(c, CameraTarget{X: -500, Y: -500})
+	if !near(c.X, ScreenWidth/2) || !near(c.Y, ScreenHeight/2) {
+		t.Fatalf("camera = (%.1f, %.1f), want clamped to (%d, %d)", c.X, c.Y, ScreenWidth/2, ScreenHeight/2)
+	}
+
+	// 缩小后视野比边界宽时居中
+	c.TargetZoom = 0.25
+	settle(c, CameraTarget{X: 1800, Y: 500})
+	if !near(c.X, 1000) || !near(c.Y, 500) {
+		t.Fatalf("camera = (%.1f, %.1f), want centred (1000, 500)", c.X, c.Y)
+	}
+
+	// 边界为空时不限制
+	c.SetBounds(0, 0, 0, 0)
+	c.TargetZoom = 1
+	settle(c, CameraTarget{X: -5000})
+	if !near(c.X, -5000) {
+		t.Fatalf("unbounded camera = %.1f, want -5000", c.X)
+	}
+}
+
+func TestGameCameraBounds(t *testing.T) {
+	// 区块行-4~7：世界的垂直范围为-2000~4000
+	g := newStreamingGame()
+	g.setWorldBounds()
+	if g.worldMinY != -2000 || g.worldMaxY != 4000 {
+		t.Fatalf("world bounds %v-%v, want -2000-4000", g.worldMinY, g.worldMaxY)
+	}
+	follow := func(x, y float64) {
+		g.player.X, g.player.Y = x, y
+		for i := 0; i < 1000; i++ {
+			g.updateCamera()
+		}
+	}
+
+	// 摄像机不显示最深的区块以下和最高的区块以上
+	follow(0, 100000)
+	if !near(g.camera.Y, 4000-ScreenHeight/2) {
+		t.Errorf("camera y = %.1f below the terrain, want %d", g.camera.Y, 4000-ScreenHeight/2)
+	}
+	follow(0, -100000)
+	if !near(g.camera.Y, -2000+ScreenHeight/2) {
+		t.Errorf("camera y = %.1f above the sky, want %d", g.camera.Y, -2000+ScreenHeight/2)
+	}
+
+	// 水平方向不限制
+	follow(100000, 0)
+	if g.camera.X < 99000 {
+		t.Errorf("camera x = %.1f, want following the player to 100000", g.camera.X)
+	}
+}
+
+func TestCameraZoomEasing(t *testing.T) {
+	c := NewCamera(0, 0)
+	c.TargetZoom = 2
+
+	// 缩放逐帧靠近目标，不会一次跳到目标或越过目标
+	prev := c.Zoom
+	for i := 0; i < 5; i++ {
+		c.Update(CameraTarget{}, ScreenWidth, ScreenHeight)
+		if c.Zoom <= prev || c.Zoom >= 2 {
+			t.Fatalf("frame %d: zoom %v after %v, want between them and 2", i, c.Zoom, prev)
+		}
+		prev = c.Zoom
+	}
+
+	// 最终停在目标上
+	settle(c, CameraTarget{})
+	if c.Zoom != 2 {
+		t.Fatalf("zoom settled at %v, want 2", c.Zoom)
+	}
+	c.TargetZoom = 0.5
+	settle(c, CameraTarget{})
+	if c.Zoom != 0.5 {
+		t.Fatalf("zoom settled at %v, want 0.5", c.Zoom)
+	}
+}
+
+func TestCameraTrauma(t *testing.T) {
+	c := NewCamera(0, 0)
+	c.AddTrauma(0.7)
+	c.AddTrauma(0.7)
+	if c.Trauma() != 1 {
+		t.Fatalf("trauma = %.2f, want clamped to 1", c.Trauma())
+	}
+
+	c.Update(CameraTarget{}, ScreenWidth, ScreenHeight)
+	if c.shakeX == 0 && c.shakeY == 0 {
+		t.Fatalf("no shake with trauma %.2f", c.Trauma())
+	}
+	if math.Abs(c.shakeX) > CameraMaxShake || math.Abs(c.shakeY) > CameraMaxShake || math.Abs(c.shakeR) > CameraMaxRoll {
+		t.Fatalf("shake (%.2f, %.2f, %.3f) exceeds limits", c.shakeX, c.shakeY, c.shakeR)
+	}
+
+	// 震动逐帧衰减到0
+	frames := 0
+	for c.Trauma() > 0 {
+		c.Update(CameraTarget{}, ScreenWidth, ScreenHeight)
+		frames++
+	}
+	if want := int(math.Ceil(1 / CameraTraumaDecay)); frames > want {
+		t.Fatalf("trauma took %d frames to decay, want at most %d", frames, want)
+	}
+	if c.shakeX != 0 || c.shakeY != 0 || c.shakeR != 0 {
+		t.Fatalf("shake (%.2f, %.2f, %.3f) without trauma", c.shakeX, c.shakeY, c.shakeR)
+	}
+}
+
+func TestCameraGeoM(t *testing.T) {
+	c := NewCamera(300, -200)
+	c.Zoom = 2
+	geoM := c.GeoM(ScreenWidth*2, ScreenHeight*2, 2)
+
+	// 摄像机中心位于屏幕中心
+	if x, y := geoM.Apply(300, -200); !near(x, ScreenWidth) || !near(y, ScreenHeight) {
+		t.Fatalf("centre maps to (%.1f, %.1f), want (%d, %d)", x, y, ScreenWidth, ScreenHeight)
+	}
+	// 一个方块在屏幕上为 BlockSize × 缩放 × 设备倍数
+	x0, _ := geoM.Apply(0, 0)
+	x1, _ := geoM.Apply(BlockSize, 0)
+	if !near(x1-x0, BlockSize*4) {
+		t.Fatalf("block width on screen = %.1f, want %d", x1-x0, BlockSize*4)
+	}
+	// 屏幕坐标可以还原为世界坐标
+	inv := geoM
+	inv.Invert()
+	if x, y := inv.Apply(geoM.Apply(123, 456)); !near(x, 123) || !near(y, 456) {
+		t.Fatalf("round trip = (%.1f, %.1f), want (123, 456)", x, y)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"2d.go/region"
+	"2d.go/world"
+)
+
+// cellEdit 玩家对格子的修改：放置的方块（记录在方块左上角的格子上）或被清空的格子
+type cellEdit struct {
+	block   Block
+	removed bool
+}
+
+// chunkRegion 返回按玩家位置生成和卸载区块的区域
+func (g *Game) chunkRegion() *region.Region {
+	if g.region == nil {
+		g.region = region.New(g.generator(), region.LimitsFor(g.generator()))
+	}
+	return g.region
+}
+
+// chunkAnchors 返回决定加载范围的格子：玩家所在的格子和视野的四角
+// （窗口放大或缩小视野时加载范围扩展到整个视野）
+func (g *Game) chunkAnchors() []world.GridPos {
+	player := toGridPos(g.player.CenterX(), g.player.CenterY())
+	anchors := []world.GridPos{{X: player.X, Y: player.Y}}
+	viewW, viewH := g.viewSize()
+	view := viewChunks(g.cameraGeoM(), viewW, viewH)
+	for _, x := range []int{view.minX, view.maxX} {
+		for _, y := range []int{view.minY, view.maxY} {
+			anchors = append(anchors, world.GridPos{X: x * ChunkSize, Y: y * ChunkSize})
+		}
+	}
+	return anchors
+}
+
+// updateChunks 生成玩家和视野附近、高度范围内的区块，并卸载远处的区块
+func (g *Game) updateChunks() {
+	r := g.chunkRegion()
+	anchors := g.chunkAnchors()
+	for _, c := range r.Update(anchors...) {
+		g.addChunk(chunkFromWorld(c))
+	}
+	for _, p := range r.Unload(anchors...) {
+		g.unloadChunk(p.X, p.Y)
+	}
+}
+
+// loadChunk 通过区域加载单个区块（如果尚未加载），不考虑玩家位置
+func (g *Game) loadChunk(chunkX, chunkY int) {
+	if c, ok := g.chunkRegion().Load(chunkX, chunkY); ok {
+		g.addChunk(chunkFromWorld(c))
+	}
+}
+
+// addChunk 加入新生成的区块，区块中被修改过的格子使用修改后的方块
+func (g *Game) addChunk(chunk *Chunk) {
+	key := chunkKey(chunk.X, chunk.Y)
+	if _, exists := g.chunks[key]; exists {
+		return
+	}
+	chunk.Blocks = g.applyEdits(chunk)
+	g.chunks[key] = chunk
+	g.blocks = append(g.blocks, chunk.Blocks...)
+	g.onBlocksChanged(chunk.Blocks, nil)
+}
+
+// applyEdits 返回应用修改后的区块方块：去掉占据被修改格子的生成方块，
+// 加入玩家放置在区块中的方块
+func (g *Game) applyEdits(chunk *Chunk) []Block {
+	if len(g.edits) == 0 {
+		return chunk.Blocks
+	}
+	var blocks []Block
+	for _, block := range chunk.Blocks {
+		edited := false
+		for _, p := range blockCells(block) {
+			if _, ok := g.edits[p]; ok {
+				edited = true
+				break
+			}
+		}
+		if !edited {
+			blocks = append(blocks, block)
+		}
+	}
+	for x := chunk.X * ChunkSize; x < (chunk.X+1)*ChunkSize; x++ {
+		for y := chunk.Y * ChunkSize; y < (chunk.Y+1)*ChunkSize; y++ {
+			if e, ok := g.edits[GridPos{x, y}]; ok && !e.removed {
+				blocks = append(blocks, e.block)
+			}
+		}
+	}
+	return blocks
+}
+
+// unloadChunk 移除区块生成的方块和玩家放置在区块中的方块，修改保留在g.edits中
+func (g *Game) unloadChunk(chunkX, chunkY int) {
+	key := chunkKey(chunkX, chunkY)
+	chunk, ok := g.chunks[key]
+	if !ok {
+		return
+	}
+	if g.worldMap != nil {
+		g.worldMap.Refresh(g.grid, GridPos{chunkX, chunkY})
+	}
+	delete(g.chunks, key)
+	owned := make(map[Block]bool, len(chunk.Blocks))
+	for _, block := range chunk.Blocks {
+		owned[block] = true
+	}
+	var kept, removed []Block
+	for _, block := range g.blocks {
+		p := toGridPos(block.X, block.Y)
+		e, edited := g.edits[p]
+		placed := edited && !e.removed && e.block == block && chunkOf(p.X, p.Y) == GridPos{chunkX, chunkY}
+		if owned[block] || placed {
+			removed = append(removed, block)
+		} else {
+			kept = append(kept, block)
+		}
+	}
+	g.blocks = kept
+	g.onBlocksChanged(nil, removed)
+}
+
+// editBlocks 记录玩家的方块修改并同步网格、光照和渲染缓存
+//
+// 修改按格子记录，区块卸载后重新生成时仍然有效。
+func (g *Game) editBlocks(added, removed []Block) {
+	g.recordEdits(added, removed)
+	g.onBlocksChanged(added, removed)
+}
+
+// recordEdits 只记录方块修改，不改变已加载的方块（用于已卸载的区块）
+func (g *Game) recordEdits(added, removed []Block) {
+	if g.edits == nil {
+		g.edits = make(map[GridPos]cellEdit)
+	}
+	for _, block := range removed {
+		for _, p := range blockCells(block) {
+			g.edits[p] = cellEdit{removed: true}
+		}
+	}
+	for _, block := range added {
+		g.edits[toGridPos(block.X, block.Y)] = cellEdit{block: block}
+	}
+}
+
+// chunkUnloaded 判断格子所在的区块是否已被区域卸载（不按区块加载时总是返回false）
+func (g *Game) chunkUnloaded(p GridPos) bool {
+	if g.region == nil {
+		return false
+	}
+	c := chunkOf(p.X, p.Y)
+	_, loaded := g.region.Chunk(c.X, c.Y)
+	return !loaded
+}
+
+// loadedCells 返回位于已加载区块中的格子
+func (g *Game) loadedCells(cells []GridPos) []GridPos {
+	loaded := make(map[GridPos]bool)
+	var out []GridPos
+	for _, p := range cells {
+		c := chunkOf(p.X, p.Y)
+		isLoaded, ok := loaded[c]
+		if !ok {
+			_, isLoaded = g.chunks[chunkKey(c.X, c.Y)]
+			loaded[c] = isLoaded
+		}
+		if isLoaded {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+
+	"2d.go/region"
+)
+
+// newStreamingGame 创建按玩家位置加载区块的游戏，区块范围较小以便测试卸载
+func newStreamingGame() *Game {
+	g := &Game{
+		chunks:   make(map[string]*Chunk),
+		entities: NewEntityManager(),
+		clock:    NewWorldClock(0),
+		gameMode: GameModeCreative,
+	}
+	g.player = g.entities.Spawn(newPlayerEntity(0, 0))
+	g.camera = NewCamera(g.player.CenterX(), g.player.CenterY())
+	g.grid = newBlockGrid(nil)
+	g.light = NewLightEngine(g.grid)
+	g.renderer = NewBlockRenderer()
+	g.region = region.New(g.generator(), region.Limits{MinChunkY: -4, MaxChunkY: 7, RadiusX: 3, RadiusY: 2})
+	return g
+}
+
+// moveTo 把玩家和摄像机移动到方块坐标(x, y)并更新区块
+func (g *Game) moveTo(x, y int) {
+	g.player.X, g.player.Y = float64(x*BlockSize), float64(y*BlockSize)
+	g.camera.SnapTo(g.player.CenterX(), g.player.CenterY())
+	g.updateChunks()
+}
+
+func TestChunksFollowPlayer(t *testing.T) {
+	g := newStreamingGame()
+	surface := g.terrain().SurfaceHeight(0)
+	g.moveTo(0, surface-1)
+	if _, ok := g.chunks[chunkKey(0, chunkOf(0, surface).Y)]; !ok {
+		t.Fatal("spawn chunk not loaded")
+	}
+	for key, c := range g.chunks {
+		if c.Y < -4 || c.Y > 7 {
+			t.Errorf("chunk %s outside the height limits", key)
+		}
+	}
+
+	// 挖掉地表方块并在上方放置一个方块
+	if _, ok := g.removeBlock(0, float64(surface*BlockSize)); !ok {
+		t.Fatal("no surface block to remove")
+	}
+	g.currentItemType = ItemTypeWood
+	g.addBlock(0, float64((surface-3)*BlockSize))
+	loaded := len(g.blocks)
+
+	// 玩家离开后出生点的区块被卸载，方块、网格和光照随之移除
+	g.moveTo(1000, surface-1)
+	if _, ok := g.chunks[chunkKey(0, chunkOf(0, surface).Y)]; ok {
+		t.Fatal("spawn chunk still loaded after the player left")
+	}
+	if g.grid.Solid(0, surface+1) {
+		t.Error("grid still has blocks of the unloaded chunk")
+	}
+	if n := len(g.blocks); n > loaded {
+		t.Errorf("%d blocks loaded after moving, %d at spawn: old chunks not unloaded", n, loaded)
+	}
+	for _, block := range g.blocks {
+		if block.X < 500*BlockSize {
+			t.Fatalf("block %v of an unloaded chunk is still in the world", block)
+		}
+	}
+
+	// 回到出生点后重新生成的区块保留修改
+	g.moveTo(0, surface-1)
+	if g.grid.Solid(0, surface) {
+		t.Error("removed surface block came back after reload")
+	}
+	if typ, ok := g.grid[GridPos{0, surface - 3}]; !ok || typ != ItemTypeWood {
+		t.Error("placed block lost after reload")
+	}
+	if !g.grid.Solid(0, surface+1) || !g.grid.Solid(1, surface) {
+		t.Error("unedited terrain missing after reload")
+	}
+	if n := len(g.blocks); n != loaded {
+		t.Errorf("%d blocks after returning, want %d", n, loaded)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"2d.go/command"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ClipboardDir 剪贴板导出文件（结构文件格式）的默认目录
+const ClipboardDir = "saves/clipboard"
+
+// Clipboard 复制的方块区域，坐标相对于区域左上角
+type Clipboard struct {
+	W, H   int
+	Blocks map[GridPos]ItemType // 空气格不记录，粘贴时保留原有方块
+}
+
+// copyRegion 复制方块网格中rect区域的方块
+func copyRegion(grid blockGrid, rect gridRect) *Clipboard {
+	c := &Clipboard{
+		W:      rect.maxX - rect.minX + 1,
+		H:      rect.maxY - rect.minY + 1,
+		Blocks: make(map[GridPos]ItemType),
+	}
+	for y := rect.minY; y <= rect.maxY; y++ {
+		for x := rect.minX; x <= rect.maxX; x++ {
+			if t, ok := grid[GridPos{x, y}]; ok {
+				c.Blocks[GridPos{x - rect.minX, y - rect.minY}] = t
+			}
+		}
+	}
+	return c
+}
+
+// transform 按映射函数重新排列方块，w/h为变换后的尺寸
+func (c *Clipboard) transform(w, h int, fn func(p GridPos) GridPos) {
+	blocks := make(map[GridPos]ItemType, len(c.Blocks))
+	for p, t := range c.Blocks {
+		blocks[fn(p)] = t
+	}
+	c.W, c.H, c.Blocks = w, h, blocks
+}
+
+// Rotate 顺时针旋转90°
+func (c *Clipboard) Rotate() {
+	h := c.H
+	c.transform(c.H, c.W, func(p GridPos) GridPos { return GridPos{h - 1 - p.Y, p.X} })
+}
+
+// MirrorX 水平翻转（左右对调）
+func (c *Clipboard) MirrorX() {
+	w := c.W
+	c.transform(c.W, c.H, func(p GridPos) GridPos { return GridPos{w - 1 - p.X, p.Y} })
+}
+
+// MirrorY 竖直翻转（上下对调）
+func (c *Clipboard) MirrorY() {
+	h := c.H
+	c.transform(c.W, c.H, func(p GridPos) GridPos { return GridPos{p.X, h - 1 - p.Y} })
+}
+
+// cells 返回按位置排序的相对坐标，使粘贴和导出的顺序稳定
+func (c *Clipboard) cells() []GridPos {
+	cells := make([]GridPos, 0, len(c.Blocks))
+	for p := range c.Blocks {
+		cells = append(cells, p)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+	return cells
+}
+
+// setBlocks 将一批格子设置为指定方块（已有不同方块的先移除），作为一步撤销历史，
+// 不检查放置规则；types中没有的格子只移除
+func (g *Game) setBlocks(cells []GridPos, types map[GridPos]ItemType) {
+	target := make(map[GridPos]bool, len(cells))
+	for _, p := range cells {
+		target[p] = true
+	}
+
+	var added, removed []Block
+	kept := g.blocks[:0:0]
+	for _, block := range g.blocks {
+		p := toGridPos(block.X, block.Y)
+		if target[p] {
+			if t, ok := types[p]; !ok || t != block.Type {
+				removed = append(removed, block)
+				continue
+			}
+			delete(target, p) // 已是目标方块，不需要放置
+		}
+		kept = append(kept, block)
+	}
+	for _, p := range cells {
+		if t, ok := types[p]; ok && target[p] {
+			block := Block{float64(p.X) * BlockSize, float64(p.Y) * BlockSize, BlockSize, BlockSize, t}
+			kept = append(kept, block)
+			added = append(added, block)
+			delete(target, p)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	g.blocks = kept
+	g.editBlocks(added, removed)
+
+	g.history.Begin()
+	defer g.history.End()
+	for _, block := range removed {
+		g.history.Record(block, false)
+	}
+	for _, block := range added {
+		g.history.Record(block, true)
+	}
+}
+
+// copySelection 复制选区到剪贴板，cut为true时同时清除选区内的方块
+func (g *Game) copySelection(cut bool) bool {
+	if !g.hasSelection {
+		return false
+	}
+	g.clipboard = copyRegion(g.pathGrid(), g.selection)
+	if cut {
+		var cells []GridPos
+		for p := range g.clipboard.Blocks {
+			cells = append(cells, GridPos{p.X + g.selection.minX, p.Y + g.selection.minY})
+		}
+		g.setBlocks(cells, nil)
+	}
+	return true
+}
+
+// pasteBlocks 将剪贴板粘贴到以at为左上角的位置
+func (g *Game) pasteBlocks(c *Clipboard, at GridPos) {
+	cells := make([]GridPos, 0, len(c.Blocks))
+	types := make(map[GridPos]ItemType, len(c.Blocks))
+	for _, p := range c.cells() {
+		world := GridPos{p.X + at.X, p.Y + at.Y}
+		cells = append(cells, world)
+		types[world] = c.Blocks[p]
+	}
+	g.setBlocks(cells, types)
+}
+
+// pasteOrigin 返回粘贴预览的左上角（鼠标所在格子）
+func (g *Game) pasteOrigin() GridPos {
+	return toGridPos(g.getMouseWorldPosition())
+}
+
+// updateClipboard 处理复制（Ctrl+C）、剪切（Ctrl+X）和粘贴（Ctrl+V）
+//
+// 粘贴模式下左键放置（可多次放置），R旋转，H/V水平/竖直翻转，Esc退出。
+// 剪贴板会复制方块，因此只在创造模式下可用。返回本帧是否处于粘贴模式（鼠标不再破坏或放置方块）。
+func (g *Game) updateClipboard() bool {
+	if g.gameMode != GameModeCreative {
+		g.pasting = false
+		return false
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyControl) {
+		switch {
+		case inpututil.IsKeyJustPressed(ebiten.KeyC):
+			g.copySelection(false)
+		case inpututil.IsKeyJustPressed(ebiten.KeyX):
+			g.copySelection(true)
+		case inpututil.IsKeyJustPressed(ebiten.KeyV):
+			g.pasting = g.clipboard != nil && !g.pasting
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if g.pasting {
+			g.pasting = false
+		} else {
+			g.hasSelection = false
+		}
+	}
+	if !g.pasting {
+		return false
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.clipboard.Rotate()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		g.clipboard.MirrorX()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) && !ebiten.IsKeyPressed(ebiten.KeyControl) {
+		g.clipboard.MirrorY()
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.pasteBlocks(g.clipboard, g.pasteOrigin())
+	}
+	return true
+}
+
+// drawClipboard 绘制选区边框和粘贴预览
+func (g *Game) drawClipboard(screen *ebiten.Image, geoM ebiten.GeoM) {
+	if g.hasSelection && !g.selecting {
+		drawRectBorder(screen, geoM, g.selection)
+	}
+	if !g.pasting {
+		return
+	}
+	c := g.clipboard
+	at := g.pasteOrigin()
+	batch := ghostBatch{screen: screen, geoM: geoM}
+	for _, p := range c.cells() {
+		batch.add(GridPos{p.X + at.X, p.Y + at.Y}, ghostColor(c.Blocks[p]))
+	}
+	batch.flush()
+	drawRectBorder(screen, geoM, gridRect{at.X, at.Y, at.X + c.W - 1, at.Y + c.H - 1})
+}
+
+// drawRectBorder 绘制格子矩形的边框
+func drawRectBorder(screen *ebiten.Image, geoM ebiten.GeoM, rect gridRect) {
+	x0, y0 := geoM.Apply(float64(rect.minX)*BlockSize, float64(rect.minY)*BlockSize)
+	x1, y1 := geoM.Apply(float64(rect.maxX+1)*BlockSize, float64(rect.maxY+1)*BlockSize)
+	ebitenutil.DrawLine(screen, x0, y0, x1, y0, selectionBorder)
+	ebitenutil.DrawLine(screen, x0, y1, x1, y1, selectionBorder)
+	ebitenutil.DrawLine(screen, x0, y0, x0, y1, selectionBorder)
+	ebitenutil.DrawLine(screen, x1, y0, x1, y1, selectionBorder)
+}
+
+// clipboardStatus 返回粘贴模式下界面上显示的提示
+func (g *Game) clipboardStatus() string {
+	c := g.clipboard
+	return fmt.Sprintf("Paste %dx%d (%d blocks) - Click: Place  R: Rotate  H/V: Mirror  Esc: Cancel", c.W, c.H, len(c.Blocks))
+}
+
+// cmdClip 将剪贴板导出为结构文件或从结构文件导入
+func cmdClip(g *Game, args []string) (string, error) {
+	if len(args) != 2 {
+		return "", command.ErrUsage
+	}
+	path := schematicPath(ClipboardDir, args[1])
+	switch args[0] {
+	case "save":
+		if g.clipboard == nil {
+			return "", fmt.Errorf("clipboard is empty")
+		}
+		if err := SaveSchematic(path, NewSchematic(g.clipboard, nil)); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("saved %dx%d clipboard to %s", g.clipboard.W, g.clipboard.H, path), nil
+	case "load":
+		s, err := LoadSchematic(path)
+		if err != nil {
+			return "", err
+		}
+		c, err := s.Clipboard()
+		if err != nil {
+			return "", err
+		}
+		g.clipboard = c
+		return fmt.Sprintf("loaded %dx%d clipboard from %s (Ctrl+V to paste)", c.W, c.H, path), nil
+	default:
+		return "", command.ErrUsage
+	}
+}
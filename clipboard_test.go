@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+)
+
+// lShape 返回一个3x2的L形剪贴板：
+//
+//	S..
+//	SSD
+func lShape() *Clipboard {
+	return &Clipboard{W: 3, H: 2, Blocks: map[GridPos]ItemType{
+		{0, 0}: ItemTypeStone,
+		{0, 1}: ItemTypeStone,
+		{1, 1}: ItemTypeStone,
+		{2, 1}: ItemTypeDirt,
+	}}
+}
+
+func TestClipboardTransforms(t *testing.T) {
+	c := lShape()
+	c.Rotate()
+	// 顺时针旋转后：
+	//	SS
+	//	S.
+	//	D.
+	want := map[GridPos]ItemType{{0, 0}: ItemTypeStone, {1, 0}: ItemTypeStone, {0, 1}: ItemTypeStone, {0, 2}: ItemTypeDirt}
+	if c.W != 2 || c.H != 3 || len(c.Blocks) != len(want) {
+		t.Fatalf("rotated clipboard %dx%d with %d blocks, want 2x3 with %d", c.W, c.H, len(c.Blocks), len(want))
+	}
+	for p, typ := range want {
+		if c.Blocks[p] != typ {
+			t.Errorf("rotated block %v = %v, want %v", p, c.Blocks[p], typ)
+		}
+	}
+
+	// 旋转四次回到原样
+	for i := 0; i < 3; i++ {
+		c.Rotate()
+	}
+	if c.W != 3 || c.H != 2 || c.Blocks[GridPos{2, 1}] != ItemTypeDirt || c.Blocks[GridPos{0, 0}] != ItemTypeStone {
+		t.Fatalf("four rotations changed the clipboard: %+v", c)
+	}
+
+	c.MirrorX()
+	if c.Blocks[GridPos{0, 1}] != ItemTypeDirt || c.Blocks[GridPos{2, 0}] != ItemTypeStone {
+		t.Fatalf("MirrorX result %+v", c.Blocks)
+	}
+	c.MirrorX()
+	c.MirrorY()
+	if c.Blocks[GridPos{2, 0}] != ItemTypeDirt || c.Blocks[GridPos{0, 1}] != ItemTypeStone {
+		t.Fatalf("MirrorY result %+v", c.Blocks)
+	}
+}
+
+func TestCopyCutPaste(t *testing.T) {
+	g := newToolGame([]Block{
+		blockAtCell(0, 0, ItemTypeStone),
+		blockAtCell(1, 0, ItemTypeDirt),
+		blockAtCell(5, 0, ItemTypeSand), // 将被粘贴覆盖
+	}, GridPos{}, GridPos{})
+	g.history = NewEditHistory(0)
+
+	g.selection, g.hasSelection = gridRect{0, -1, 1, 0}, true
+	if !g.copySelection(true) {
+		t.Fatal("copySelection returned false with a selection")
+	}
+	if c := g.clipboard; c.W != 2 || c.H != 2 || len(c.Blocks) != 2 {
+		t.Fatalf("clipboard %dx%d with %d blocks, want 2x2 with 2", c.W, c.H, len(c.Blocks))
+	}
+	if len(g.blocks) != 1 {
+		t.Fatalf("%d blocks after cut, want 1", len(g.blocks))
+	}
+
+	g.pasteBlocks(g.clipboard, GridPos{5, -1})
+	want := map[GridPos]ItemType{{5, 0}: ItemTypeStone, {6, 0}: ItemTypeDirt}
+	for p, typ := range want {
+		if got, ok := g.grid[p]; !ok || got != typ {
+			t.Errorf("pasted cell %v = %v, want %v", p, got, typ)
+		}
+	}
+	if len(g.blocks) != 2 {
+		t.Fatalf("%d blocks after paste, want 2", len(g.blocks))
+	}
+
+	// 剪切和粘贴各为一步撤销
+	g.undo()
+	if g.grid[GridPos{5, 0}] != ItemTypeSand || len(g.blocks) != 1 {
+		t.Fatal("undoing the paste did not restore the overwritten block")
+	}
+	g.undo()
+	if len(g.blocks) != 3 {
+		t.Fatalf("%d blocks after undoing the cut, want 3", len(g.blocks))
+	}
+}
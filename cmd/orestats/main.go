@@ -0,0 +1,41 @@
+// orestats 在不启动游戏的情况下统计矿石在各深度的出现频率，
+// 用于检查矿石生成参数的修改效果。
+//
+//	go run ./cmd/orestats -seed 42 -chunks 2000 -biomes desert,savanna
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"2d.go/world"
+)
+
+func main() {
+	seed := flag.Int64("seed", world.TerrainSeed, "terrain seed")
+	worldType := flag.String("world", world.WorldDefault, "world type: default, amplified or single:<biome> (other types have no ores)")
+	chunks := flag.Int("chunks", 1000, "number of chunk columns to sample, centred on the spawn")
+	biomeList := flag.String("biomes", "", "comma-separated terrain types to include (default all)")
+	flag.Parse()
+
+	if *chunks <= 0 {
+		log.Fatal("need -chunks > 0")
+	}
+	biomes, err := world.ParseBiomes(*biomeList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gen, err := world.NewChunkGenerator(*worldType, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tg, ok := gen.(*world.TerrainGenerator)
+	if !ok {
+		log.Fatalf("the %s world has no ores", *worldType)
+	}
+	stats := world.SampleOreStats(tg, -*chunks/2, *chunks, biomes)
+	if err := stats.Write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,45 @@
+// server 运行本地多人游戏的无界面服务器：服务器拥有世界并以固定的tick运行模拟，
+// 客户端通过TCP连接，协议见server包。
+//
+//	go run ./cmd/server -addr :7777 -world default -seed 42
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+
+	"2d.go/server"
+	"2d.go/world"
+)
+
+func main() {
+	addr := flag.String("addr", ":7777", "TCP address to listen on")
+	seed := flag.Int64("seed", world.TerrainSeed, "terrain seed")
+	worldType := flag.String("world", world.WorldDefault, "world type: default, amplified, flat[:layers], skyblock, void or single:<biome>")
+	tps := flag.Int("tps", server.DefaultTickRate, "simulation ticks per second")
+	flag.Parse()
+
+	if *tps <= 0 {
+		log.Fatal("need -tps > 0")
+	}
+	s, err := server.New(server.Config{World: *worldType, Seed: *seed, TickRate: *tps})
+	if err != nil {
+		log.Fatal(err)
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("serving %s world (seed %d) on %s at %d ticks per second", *worldType, *seed, l.Addr(), *tps)
+	if err := s.Serve(ctx, l); err != nil {
+		log.Fatal(err)
+	}
+	log.Print("server stopped")
+}
@@ -0,0 +1,62 @@
+// worldpreview 在不启动游戏的情况下生成区块，并把方块按注册表颜色绘制成PNG图片，
+// 用于检查地形生成参数的修改效果。
+//
+//	go run ./cmd/worldpreview -seed 42 -from -500 -to 500 -biomes -o world.png
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+
+	"2d.go/world"
+)
+
+func main() {
+	seed := flag.Int64("seed", world.TerrainSeed, "terrain seed")
+	worldType := flag.String("world", world.WorldDefault, "world type: default, amplified, flat[:layers], skyblock, void or single:<biome>")
+	from := flag.Int("from", -500, "leftmost block column")
+	to := flag.Int("to", 500, "rightmost block column")
+	top := flag.Int("top", 0, "first block row (default: above the highest surface in the range)")
+	bottom := flag.Int("bottom", 0, "last block row (default: deepest terrain row in the range)")
+	scale := flag.Int("scale", 2, "pixels per block")
+	biomes := flag.Bool("biomes", false, "draw a terrain type strip above the map")
+	out := flag.String("o", "world.png", "output PNG file")
+	flag.Parse()
+
+	if *to < *from || *scale <= 0 {
+		log.Fatal("need -from <= -to and -scale > 0")
+	}
+	gen, err := world.NewChunkGenerator(*worldType, *seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p := Preview{MinX: *from, MaxX: *to, Scale: *scale, Biomes: *biomes}
+	p.MinY, p.MaxY = autoRows(gen, *from, *to)
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "top":
+			p.MinY = *top
+		case "bottom":
+			p.MaxY = *bottom
+		}
+	})
+	if p.MaxY < p.MinY {
+		log.Fatal("need -top <= -bottom")
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := png.Encode(f, p.Render(gen)); err != nil {
+		f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("wrote %s: columns %d..%d, rows %d..%d\n", *out, p.MinX, p.MaxX, p.MinY, p.MaxY)
+}
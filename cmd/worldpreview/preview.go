@@ -0,0 +1,115 @@
+package main
+
+import (
+	"image"
+	"image/color"
+
+	"2d.go/world"
+)
+
+// 预览图的颜色
+var (
+	skyColor     = color.RGBA{135, 190, 235, 255} // 空气
+	unknownColor = color.RGBA{255, 0, 255, 255}   // 注册表中没有的方块类型
+)
+
+// biomeColors 地形类型图中各地形的颜色
+var biomeColors = map[world.TerrainType]color.RGBA{
+	world.TerrainTypePlains:      {120, 190, 80, 255},
+	world.TerrainTypeHills:       {90, 150, 70, 255},
+	world.TerrainTypeMountains:   {130, 130, 130, 255},
+	world.TerrainTypeDesert:      {230, 200, 120, 255},
+	world.TerrainTypeForest:      {40, 120, 50, 255},
+	world.TerrainTypeSnowyPlains: {235, 240, 250, 255},
+	world.TerrainTypeSwamp:       {80, 100, 60, 255},
+	world.TerrainTypeJungle:      {30, 160, 40, 255},
+	world.TerrainTypeTaiga:       {50, 100, 80, 255},
+	world.TerrainTypeSavanna:     {190, 180, 90, 255},
+	world.TerrainTypeCanyon:      {190, 110, 60, 255},
+	world.TerrainTypeOcean:       {30, 70, 170, 255},
+	world.TerrainTypeBeach:       {245, 225, 160, 255},
+}
+
+// BiomeStripHeight 地形类型图的高度（格数）
+const BiomeStripHeight = 8
+
+// Preview 预览的范围和选项，坐标为方块格子坐标（包含边界）
+type Preview struct {
+	MinX, MaxX int
+	MinY, MaxY int
+	Scale      int  // 每格的像素数
+	Biomes     bool // 在方块图上方绘制地形类型图
+}
+
+// autoRows 根据各列地表高度选择包含整个地形柱、树木和海面的行范围
+func autoRows(gen world.ChunkGenerator, minX, maxX int) (minY, maxY int) {
+	low, high := gen.SurfaceHeight(minX), gen.SurfaceHeight(minX)
+	for x := minX + 1; x <= maxX; x++ {
+		h := gen.SurfaceHeight(x)
+		low, high = min(low, h), max(high, h)
+	}
+	return min(low, world.SeaLevel) - 16, high + world.TerrainDepth - 1
+}
+
+// Render 生成范围内的区块并绘制预览图
+//
+// 与游戏中相同，世界y越大在图中越靠下。
+func (p Preview) Render(gen world.ChunkGenerator) *image.RGBA {
+	w, h := p.MaxX-p.MinX+1, p.MaxY-p.MinY+1
+	top := 0
+	if p.Biomes {
+		top = BiomeStripHeight
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w*p.Scale, (top+h)*p.Scale))
+	fill := func(cx, cy int, c color.RGBA) {
+		for py := cy * p.Scale; py < (cy+1)*p.Scale; py++ {
+			for px := cx * p.Scale; px < (cx+1)*p.Scale; px++ {
+				img.SetRGBA(px, py, c)
+			}
+		}
+	}
+	for cy := 0; cy < h; cy++ {
+		for cx := 0; cx < w; cx++ {
+			fill(cx, top+cy, skyColor)
+		}
+	}
+
+	if p.Biomes {
+		for x := p.MinX; x <= p.MaxX; x++ {
+			c, ok := biomeColors[gen.Biome(x)]
+			if !ok {
+				c = unknownColor
+			}
+			for cy := 0; cy < BiomeStripHeight; cy++ {
+				fill(x-p.MinX, cy, c)
+			}
+		}
+	}
+
+	bounds := world.Rect{MinX: p.MinX, MinY: p.MinY, MaxX: p.MaxX, MaxY: p.MaxY}
+	from, to := world.ChunkOf(p.MinX, p.MinY), world.ChunkOf(p.MaxX, p.MaxY)
+	for chunkX := from.X; chunkX <= to.X; chunkX++ {
+		for chunkY := from.Y; chunkY <= to.Y; chunkY++ {
+			for _, block := range gen.GenerateChunk(chunkX, chunkY).Blocks {
+				c := blockColor(block.Type)
+				for _, cell := range world.BlockCells(block) {
+					if bounds.Contains(cell) {
+						fill(cell.X-p.MinX, top+cell.Y-p.MinY, c)
+					}
+				}
+			}
+		}
+	}
+	return img
+}
+
+// blockColor 返回方块类型在注册表中的颜色（不透明）
+func blockColor(t world.ItemType) color.RGBA {
+	item, ok := world.ItemRegistry[t]
+	if !ok {
+		return unknownColor
+	}
+	c := item.Color
+	c.A = 255
+	return c
+}
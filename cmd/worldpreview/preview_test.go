@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image/color"
+	"testing"
+
+	"2d.go/world"
+)
+
+func TestRenderFlat(t *testing.T) {
+	gen, err := world.NewChunkGenerator("flat:grass,dirt*2,stone", world.TerrainSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Preview{MinX: -15, MaxX: 14, Scale: 3, Biomes: true}
+	p.MinY, p.MaxY = autoRows(gen, p.MinX, p.MaxX)
+	img := p.Render(gen)
+	if w, h := img.Bounds().Dx(), img.Bounds().Dy(); w != 30*3 || h != (BiomeStripHeight+p.MaxY-p.MinY+1)*3 {
+		t.Fatalf("image is %dx%d", w, h)
+	}
+
+	// at 返回格子(x, y)中心像素的颜色
+	at := func(x, y int) color.RGBA {
+		return img.RGBAAt((x-p.MinX)*p.Scale+1, (BiomeStripHeight+y-p.MinY)*p.Scale+1)
+	}
+	for _, x := range []int{-15, 0, 14} {
+		want := map[int]color.RGBA{
+			world.FlatSurface - 1: skyColor,
+			world.FlatSurface:     blockColor(world.ItemTypeGrass),
+			world.FlatSurface + 1: blockColor(world.ItemTypeDirt),
+			world.FlatSurface + 2: blockColor(world.ItemTypeDirt),
+			world.FlatSurface + 3: blockColor(world.ItemTypeStone),
+			world.FlatSurface + 4: skyColor,
+		}
+		for y, c := range want {
+			if got := at(x, y); got != c {
+				t.Errorf("cell (%d, %d) = %v, want %v", x, y, got, c)
+			}
+		}
+	}
+
+	// 地形类型图使用平原的颜色
+	plains := biomeColors[world.TerrainTypePlains]
+	if c := img.RGBAAt(0, 0); c != plains {
+		t.Errorf("biome strip = %v, want %v", c, plains)
+	}
+}
+
+func TestAutoRows(t *testing.T) {
+	gen := world.NewTerrainGenerator(world.TerrainSeed)
+	minY, maxY := autoRows(gen, -100, 100)
+	for x := -100; x <= 100; x++ {
+		h := gen.SurfaceHeight(x)
+		if h < minY || h+world.TerrainDepth-1 > maxY {
+			t.Fatalf("column %d (surface %d) is outside rows %d..%d", x, h, minY, maxY)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package command 实现控制台命令：命令注册表、命令行分词、时间解析，
+// 以及只通过State接口访问游戏的内置命令。
+//
+// 包不依赖图形界面，游戏把操作自身的命令绑定后注册到Registry中。
+package command
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"2d.go/world"
+)
+
+// ErrUsage 命令参数错误，由Registry转换为用法提示
+var ErrUsage = errors.New("invalid arguments")
+
+// Command 控制台命令
+type Command struct {
+	Name  string
+	Usage string // 参数格式，如 "<x> <y>"
+	Help  string
+	Run   func(args []string) (string, error)
+}
+
+// State 内置命令操作的游戏状态
+type State interface {
+	Seed() int64                      // 世界种子
+	Give(t world.ItemType, count int) // 向物品栏添加物品
+}
+
+// Registry 命令注册表，负责解析命令行并分发给对应的命令
+type Registry struct {
+	commands map[string]*Command
+}
+
+// NewRegistry 创建空的命令注册表
+func NewRegistry() *Registry {
+	return &Registry{commands: make(map[string]*Command)}
+}
+
+// Register 注册命令，同名命令会被替换
+func (r *Registry) Register(cmd *Command) {
+	r.commands[cmd.Name] = cmd
+}
+
+// Lookup 按名称查找命令
+func (r *Registry) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// Names 返回按字母排序的命令名称
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.commands))
+	for name := range r.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Fields 把命令行拆分为命令名和参数，忽略前导“/”和多余的空白
+func Fields(line string) []string {
+	return strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "/"))
+}
+
+// Execute 解析并执行一行命令，返回命令输出
+func (r *Registry) Execute(line string) (string, error) {
+	fields := Fields(line)
+	if len(fields) == 0 {
+		return "", nil
+	}
+	name := strings.ToLower(fields[0])
+	cmd, ok := r.commands[name]
+	if !ok {
+		return "", fmt.Errorf("unknown command %q (try 'help')", name)
+	}
+	out, err := cmd.Run(fields[1:])
+	if errors.Is(err, ErrUsage) {
+		return "", fmt.Errorf("usage: %s %s", cmd.Name, cmd.Usage)
+	}
+	return out, err
+}
+
+// RegisterBuiltins 注册help以及操作s的内置命令seed和give
+func (r *Registry) RegisterBuiltins(s State) {
+	r.Register(&Command{Name: "help", Usage: "[command]", Help: "list commands or show usage of one command", Run: r.help})
+	r.Register(&Command{Name: "seed", Usage: "", Help: "show the world seed", Run: func(args []string) (string, error) {
+		if len(args) != 0 {
+			return "", ErrUsage
+		}
+		return fmt.Sprintf("seed: %d", s.Seed()), nil
+	}})
+	r.Register(&Command{Name: "give", Usage: "<item> [count]", Help: "add items to the inventory", Run: func(args []string) (string, error) {
+		return give(s, args)
+	}})
+}
+
+// help 列出所有命令或显示一个命令的用法
+func (r *Registry) help(args []string) (string, error) {
+	if len(args) == 0 {
+		return "commands: " + strings.Join(r.Names(), ", "), nil
+	}
+	cmd, ok := r.Lookup(strings.ToLower(args[0]))
+	if !ok {
+		return "", fmt.Errorf("unknown command %q", args[0])
+	}
+	return fmt.Sprintf("%s %s - %s", cmd.Name, cmd.Usage, cmd.Help), nil
+}
+
+// give 向物品栏添加物品
+func give(s State, args []string) (string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", ErrUsage
+	}
+	itemType, ok := world.ItemTypeByName(args[0])
+	if !ok {
+		return "", fmt.Errorf("unknown item %q", args[0])
+	}
+	count := 1
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			return "", ErrUsage
+		}
+		count = n
+	}
+	s.Give(itemType, count)
+	return fmt.Sprintf("gave %d %s", count, world.ItemRegistry[itemType].Name), nil
+}
+
+// ParseTimeOfDay 解析一天中的时间：named中的名称（不区分大小写）、HH:MM或[0,1)内的小数
+func ParseTimeOfDay(s string, named map[string]float64) (float64, bool) {
+	if t, ok := named[strings.ToLower(s)]; ok {
+		return t, true
+	}
+	if h, m, found := strings.Cut(s, ":"); found {
+		hours, errH := strconv.Atoi(h)
+		minutes, errM := strconv.Atoi(m)
+		if errH != nil || errM != nil || hours < 0 || hours > 23 || minutes < 0 || minutes > 59 {
+			return 0, false
+		}
+		return float64(hours*60+minutes) / (24 * 60), true
+	}
+	t, err := strconv.ParseFloat(s, 64)
+	if err != nil || t < 0 || t >= 1 {
+		return 0, false
+	}
+	return t, true
+}
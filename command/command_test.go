@@ -0,0 +1,133 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"2d.go/world"
+)
+
+// fakeState 记录内置命令对游戏状态的操作
+type fakeState struct {
+	inventory map[world.ItemType]int
+}
+
+func (s *fakeState) Seed() int64 { return 42 }
+
+func (s *fakeState) Give(t world.ItemType, count int) { s.inventory[t] += count }
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"/tp 3  -7", []string{"tp", "3", "-7"}},
+		{"  time set noon ", []string{"time", "set", "noon"}},
+	}
+	for _, tt := range tests {
+		got := Fields(tt.line)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Fields(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryExecute(t *testing.T) {
+	r := NewRegistry()
+	var got []string
+	r.Register(&Command{Name: "echo", Usage: "<text>", Run: func(args []string) (string, error) {
+		if len(args) == 0 {
+			return "", ErrUsage
+		}
+		got = args
+		return strings.Join(args, " "), nil
+	}})
+
+	tests := []struct {
+		line    string
+		want    string
+		wantErr string
+	}{
+		{line: "", want: ""},
+		{line: "echo hello  world", want: "hello world"},
+		{line: "/ECHO hi", want: "hi"},
+		{line: "echo", wantErr: "usage: echo <text>"},
+		{line: "nope", wantErr: `unknown command "nope"`},
+	}
+	for _, tt := range tests {
+		out, err := r.Execute(tt.line)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute(%q) error = %v, want %q", tt.line, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || out != tt.want {
+			t.Errorf("Execute(%q) = %q, %v, want %q", tt.line, out, err, tt.want)
+		}
+	}
+	if len(got) != 1 || got[0] != "hi" {
+		t.Errorf("last args = %q, want [hi]", got)
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	s := &fakeState{inventory: make(map[world.ItemType]int)}
+	r := NewRegistry()
+	r.RegisterBuiltins(s)
+	run := func(line string) string {
+		t.Helper()
+		out, err := r.Execute(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		return out
+	}
+
+	run("give stone 5")
+	run("give Stone")
+	if n := s.inventory[world.ItemTypeStone]; n != 6 {
+		t.Errorf("stone count = %d, want 6", n)
+	}
+	for _, line := range []string{"give diamond", "give stone 0", "give"} {
+		if _, err := r.Execute(line); err == nil {
+			t.Errorf("%s succeeded", line)
+		}
+	}
+
+	if out := run("seed"); out != "seed: 42" {
+		t.Errorf("seed = %q", out)
+	}
+	if out := run("help"); out != "commands: give, help, seed" {
+		t.Errorf("help = %q", out)
+	}
+	if out := run("help give"); out != "give <item> [count] - add items to the inventory" {
+		t.Errorf("help give = %q", out)
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	named := map[string]float64{"noon": 0.5, "midnight": 0}
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"noon", 0.5, true},
+		{"Midnight", 0, true},
+		{"06:00", 0.25, true},
+		{"18:30", 18.5 / 24, true},
+		{"0.75", 0.75, true},
+		{"24:00", 0, false},
+		{"1.5", 0, false},
+		{"later", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTimeOfDay(tt.in, named)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParseTimeOfDay(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
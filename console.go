@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"2d.go/command"
+	"2d.go/world"
+)
+
+// 控制台相关常量
+const (
+	ConsoleMaxLines   = 12 // 保留的输出行数
+	ConsoleMaxHistory = 50 // 保留的历史命令数
+)
+
+// newDefaultCommands 创建包含所有内置命令的注册表，命令操作g
+func newDefaultCommands(g *Game) *command.Registry {
+	r := command.NewRegistry()
+	r.RegisterBuiltins(g)
+	for _, cmd := range []struct {
+		name, usage, help string
+		run               func(g *Game, args []string) (string, error)
+	}{
+		{"tp", "<x> <y> | spawn", "teleport the player (block coordinates)", cmdTeleport},
+		{"time", "set <day|noon|sunset|night|midnight|HH:MM|0-1> | query", "query or change the time of day", cmdTime},
+		{"gamemode", "<creative|survival>", "switch game mode", cmdGameMode},
+		{"regen", "chunk [x y]", "regenerate a chunk (default: the player's chunk)", cmdRegen},
+		{"locate", "<hut|ruins|well|dungeon>", "find the nearest generated structure", cmdLocate},
+		{"clip", "<save|load> <name>", "export the clipboard to a file or import it", cmdClip},
+		{"schem", "save <name> [description] | load <name> | place <name> | info <name> | list", "manage the schematic library", cmdSchem},
+	} {
+		run := cmd.run
+		r.Register(&command.Command{Name: cmd.name, Usage: cmd.usage, Help: cmd.help, Run: func(args []string) (string, error) {
+			return run(g, args)
+		}})
+	}
+	return r
+}
+
+// Seed 实现command.State接口，返回地形种子
+func (g *Game) Seed() int64 {
+	return g.terrain().Seed()
+}
+
+// Give 实现command.State接口，向物品栏添加物品
+func (g *Game) Give(t ItemType, count int) {
+	g.inventory[t] += count
+}
+
+// cmdTeleport 将玩家传送到方块坐标或出生点
+func cmdTeleport(g *Game, args []string) (string, error) {
+	var x, y float64
+	switch {
+	case len(args) == 1 && strings.EqualFold(args[0], "spawn"):
+		x, y = g.spawnX-g.player.W/2, g.spawnY-g.player.H/2
+	case len(args) == 2:
+		bx, errX := strconv.ParseFloat(args[0], 64)
+		by, errY := strconv.ParseFloat(args[1], 64)
+		if errX != nil || errY != nil {
+			return "", command.ErrUsage
+		}
+		x, y = bx*BlockSize, by*BlockSize
+	default:
+		return "", command.ErrUsage
+	}
+	g.player.X, g.player.Y = x, y
+	g.player.VX, g.player.VY = 0, 0
+	if g.camera != nil {
+		g.camera.SnapTo(g.player.CenterX(), g.player.CenterY())
+	}
+	return fmt.Sprintf("teleported to (%.0f, %.0f)", x/BlockSize, y/BlockSize), nil
+}
+
+// namedTimes 可以按名称设置的时间
+var namedTimes = map[string]float64{
+	"sunrise":  SunriseTime,
+	"day":      SunriseTime + TwilightLen,
+	"noon":     0.5,
+	"sunset":   SunsetTime,
+	"night":    SunsetTime + TwilightLen,
+	"midnight": 0,
+}
+
+// cmdTime 查询或设置一天中的时间
+func cmdTime(g *Game, args []string) (string, error) {
+	if len(args) == 1 && args[0] == "query" {
+		return g.clock.String(), nil
+	}
+	if len(args) != 2 || args[0] != "set" {
+		return "", command.ErrUsage
+	}
+	t, ok := command.ParseTimeOfDay(args[1], namedTimes)
+	if !ok {
+		return "", command.ErrUsage
+	}
+	g.clock.SetTimeOfDay(t)
+	return "time set to " + g.clock.String(), nil
+}
+
+// cmdGameMode 切换游戏模式
+func cmdGameMode(g *Game, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", command.ErrUsage
+	}
+	switch strings.ToLower(args[0]) {
+	case "creative", "c", "0":
+		g.gameMode = GameModeCreative
+		return "game mode: creative", nil
+	case "survival", "s", "1":
+		g.gameMode = GameModeSurvival
+		return "game mode: survival", nil
+	default:
+		return "", command.ErrUsage
+	}
+}
+
+// cmdRegen 重新生成区块：丢弃区块内的方块（包括修改）后重新生成
+func cmdRegen(g *Game, args []string) (string, error) {
+	if len(args) == 0 || args[0] != "chunk" {
+		return "", command.ErrUsage
+	}
+	cx := int(math.Floor(g.player.X / ChunkWorldSize))
+	cy := int(math.Floor(g.player.Y / ChunkWorldSize))
+	switch len(args) {
+	case 1:
+	case 3:
+		x, errX := strconv.Atoi(args[1])
+		y, errY := strconv.Atoi(args[2])
+		if errX != nil || errY != nil {
+			return "", command.ErrUsage
+		}
+		cx, cy = x, y
+	default:
+		return "", command.ErrUsage
+	}
+	n, err := g.regenerateChunk(cx, cy)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("regenerated chunk (%d, %d): %d blocks", cx, cy, n), nil
+}
+
+// regenerateChunk 丢弃已加载区块内的方块（包括修改）并重新生成，返回重新生成的方块数
+//
+// 未加载的区块不能重新生成：区域不知道这些区块，生成的方块将永远不会被卸载。
+func (g *Game) regenerateChunk(chunkX, chunkY int) (int, error) {
+	if _, ok := g.chunkRegion().Chunk(chunkX, chunkY); !ok {
+		return 0, fmt.Errorf("chunk (%d, %d) is not loaded", chunkX, chunkY)
+	}
+	chunk := g.generateChunk(chunkX, chunkY)
+	if len(chunk.Blocks) == 0 {
+		return 0, fmt.Errorf("chunk (%d, %d) has no terrain", chunkX, chunkY)
+	}
+
+	// 保留相邻区块生成、伸入本区块的方块（树冠）
+	protected := make(map[Block]bool)
+	for _, dx := range []int{-1, 1} {
+		for _, block := range g.generateChunk(chunkX+dx, chunkY).Blocks {
+			protected[block] = true
+		}
+	}
+	// 本区块生成、伸出区块的方块也会重新加入，先移除旧的一份
+	fresh := make(map[Block]bool, len(chunk.Blocks))
+	for _, block := range chunk.Blocks {
+		fresh[block] = true
+	}
+
+	// 方块按左上角所在的区块归属
+	bounds := gridRect{chunkX * ChunkSize, chunkY * ChunkSize, chunkX*ChunkSize + ChunkSize - 1, chunkY*ChunkSize + ChunkSize - 1}
+	var kept, removed, added []Block
+	for _, block := range g.blocks {
+		inChunk := bounds.contains(toGridPos(block.X, block.Y))
+		switch {
+		case fresh[block] || inChunk && !protected[block]:
+			removed = append(removed, block)
+		case inChunk:
+			// 与被移除的方块可能占据相同的格子，稍后重新加入网格
+			added = append(added, block)
+			kept = append(kept, block)
+		default:
+			kept = append(kept, block)
+		}
+	}
+	g.blocks = append(kept, chunk.Blocks...)
+	g.chunks[chunkKey(chunkX, chunkY)] = chunk
+	for p := range g.edits {
+		if bounds.contains(p) {
+			delete(g.edits, p)
+		}
+	}
+	added = append(added, chunk.Blocks...)
+	g.onBlocksChanged(added, removed)
+	return len(chunk.Blocks), nil
+}
+
+// Console 游戏内控制台：输入行、历史命令与输出
+type Console struct {
+	Open     bool
+	Input    string
+	commands *command.Registry
+	history  []string
+	histPos  int // 浏览历史时的位置，等于len(history)表示正在输入新命令
+	lines    []string
+	blink    int // 光标闪烁计时（帧）
+}
+
+// NewConsole 创建使用内置命令操作g的控制台
+func NewConsole(g *Game) *Console {
+	return &Console{commands: newDefaultCommands(g)}
+}
+
+// Print 向控制台输出一行
+func (c *Console) Print(line string) {
+	c.lines = append(c.lines, line)
+	if len(c.lines) > ConsoleMaxLines {
+		c.lines = c.lines[len(c.lines)-ConsoleMaxLines:]
+	}
+}
+
+// Lines 返回保留的输出行
+func (c *Console) Lines() []string {
+	return c.lines
+}
+
+// Submit 执行当前输入行并记录历史与输出
+func (c *Console) Submit() {
+	line := strings.TrimSpace(c.Input)
+	c.Input = ""
+	if line == "" {
+		return
+	}
+	c.history = append(c.history, line)
+	if len(c.history) > ConsoleMaxHistory {
+		c.history = c.history[len(c.history)-ConsoleMaxHistory:]
+	}
+	c.histPos = len(c.history)
+
+	c.Print("> " + line)
+	out, err := c.commands.Execute(line)
+	switch {
+	case err != nil:
+		c.Print("error: " + err.Error())
+	case out != "":
+		c.Print(out)
+	}
+}
+
+// HistoryPrev 在输入行中显示上一条历史命令
+func (c *Console) HistoryPrev() {
+	if c.histPos > 0 {
+		c.histPos--
+		c.Input = c.history[c.histPos]
+	}
+}
+
+// HistoryNext 在输入行中显示下一条历史命令，越过最后一条时清空输入
+func (c *Console) HistoryNext() {
+	if c.histPos < len(c.history) {
+		c.histPos++
+	}
+	if c.histPos == len(c.history) {
+		c.Input = ""
+	} else {
+		c.Input = c.history[c.histPos]
+	}
+}
+
+// LocateRadius locate命令向两侧搜索的区域数
+const LocateRadius = 200
+
+// cmdLocate 查找离玩家最近的指定结构
+func cmdLocate(g *Game, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", command.ErrUsage
+	}
+	s, ok := world.StructureByName(args[0])
+	if !ok {
+		return "", fmt.Errorf("unknown structure %q", args[0])
+	}
+	tg, ok := g.generator().(*world.TerrainGenerator)
+	if !ok {
+		return "", fmt.Errorf("no structures in this world type")
+	}
+	px := int(math.Floor(g.player.CenterX() / BlockSize))
+	center := floorDiv(px, world.StructureSpacing)
+	best, found := world.PlacedStructure{}, false
+	for d := 0; d <= LocateRadius && !found; d++ {
+		// 同一距离的两个区域都检查，取较近的一个
+		for _, region := range []int{center - d, center + d} {
+			for _, ps := range tg.StructuresInRegion(region) {
+				if ps.Structure == s && (!found || abs(ps.Origin.X-px) < abs(best.Origin.X-px)) {
+					best, found = ps, true
+				}
+			}
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no %s within %d blocks", s.Name, LocateRadius*world.StructureSpacing)
+	}
+	return fmt.Sprintf("%s at (%d, %d), %d blocks away", s.Name, best.Origin.X, best.Origin.Y, abs(best.Origin.X-px)), nil
+}
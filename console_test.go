@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCommands(t *testing.T) {
+	g := newExploredGame(2)
+	g.inventory = make(map[ItemType]int)
+	r := newDefaultCommands(g)
+	run := func(line string) string {
+		t.Helper()
+		out, err := r.Execute(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		return out
+	}
+
+	run("tp 3 -7")
+	if g.player.X != 3*BlockSize || g.player.Y != -7*BlockSize {
+		t.Errorf("tp moved player to (%.0f, %.0f)", g.player.X, g.player.Y)
+	}
+
+	run("give stone 5")
+	run("give Stone")
+	if n := g.inventory[ItemTypeStone]; n != 6 {
+		t.Errorf("stone count = %d, want 6", n)
+	}
+	if _, err := r.Execute("give diamond"); err == nil {
+		t.Errorf("give unknown item succeeded")
+	}
+
+	run("time set noon")
+	if tod := g.clock.TimeOfDay(); tod != 0.5 {
+		t.Errorf("time of day = %v, want 0.5", tod)
+	}
+
+	run("gamemode survival")
+	if g.gameMode != GameModeSurvival {
+		t.Errorf("game mode = %d, want survival", g.gameMode)
+	}
+
+	if out := run("seed"); out != "seed: 12345" {
+		t.Errorf("seed = %q", out)
+	}
+
+	if out := run("help"); !strings.Contains(out, "regen") || !strings.Contains(out, "tp") {
+		t.Errorf("help = %q, want all commands listed", out)
+	}
+}
+
+func TestRegenChunk(t *testing.T) {
+	g := newExploredGame(2)
+	r := newDefaultCommands(g)
+
+	// 出生点地表所在的区块
+	chunk := chunkOf(0, g.terrain().SurfaceHeight(0))
+	original := make(map[GridPos]bool)
+	for p := range g.grid {
+		if chunkOf(p.X, p.Y) == chunk {
+			original[p] = true
+		}
+	}
+
+	// 挖掉区块中的所有方块，再在原来的空气格放一个新方块
+	var air GridPos
+	for p := range original {
+		g.removeBlock(float64(p.X*BlockSize), float64(p.Y*BlockSize))
+	}
+	for y := chunk.Y * ChunkSize; y < (chunk.Y+1)*ChunkSize; y++ {
+		for x := chunk.X * ChunkSize; x < (chunk.X+1)*ChunkSize; x++ {
+			if !original[GridPos{x, y}] {
+				air = GridPos{x, y}
+			}
+		}
+	}
+	g.addBlock(float64(air.X*BlockSize), float64(air.Y*BlockSize))
+
+	if _, err := r.Execute(fmt.Sprintf("regen chunk %d %d", chunk.X, chunk.Y)); err != nil {
+		t.Fatal(err)
+	}
+	for p := range original {
+		if !g.grid.Solid(p.X, p.Y) {
+			t.Fatalf("cell %v empty after regen", p)
+		}
+	}
+	if g.grid.Solid(air.X, air.Y) {
+		t.Errorf("placed block survived regen")
+	}
+
+	if _, err := r.Execute("regen chunk 0 99"); err == nil {
+		t.Errorf("regen of a chunk without terrain succeeded")
+	}
+}
+
+func TestRegenUnloadedChunk(t *testing.T) {
+	g := newStreamingGame()
+	r := newDefaultCommands(g)
+	surface := g.terrain().SurfaceHeight(0)
+	spawn := chunkOf(0, surface)
+	g.moveTo(0, surface-1)
+
+	// 区域没有加载的区块不能重新生成
+	if _, err := r.Execute(fmt.Sprintf("regen chunk %d %d", spawn.X+50, spawn.Y)); err == nil {
+		t.Error("regen of an unloaded chunk succeeded")
+	}
+	if _, err := r.Execute(fmt.Sprintf("regen chunk %d %d", spawn.X, spawn.Y)); err != nil {
+		t.Fatal(err)
+	}
+
+	// 玩家离开后，重新生成的区块与其他区块一起卸载
+	g.moveTo(1000, surface-1)
+	for _, block := range g.blocks {
+		if block.X < 500*BlockSize {
+			t.Fatalf("block %v left behind after the player moved away", block)
+		}
+	}
+	for p := range g.grid {
+		if p.X < 500 {
+			t.Fatalf("grid cell %v left behind after the player moved away", p)
+		}
+	}
+}
+
+func TestConsoleHistory(t *testing.T) {
+	g := newExploredGame(2)
+	c := NewConsole(g)
+	for _, line := range []string{"time set noon", "bogus", "gamemode c"} {
+		c.Input = line
+		c.Submit()
+	}
+	if lines := c.Lines(); !strings.HasPrefix(lines[3], "error: unknown command") {
+		t.Errorf("console output = %q", lines)
+	}
+
+	c.HistoryPrev()
+	c.HistoryPrev()
+	if c.Input != "bogus" {
+		t.Errorf("history prev = %q, want bogus", c.Input)
+	}
+	c.HistoryNext()
+	c.HistoryNext()
+	if c.Input != "" {
+		t.Errorf("history past the end = %q, want empty", c.Input)
+	}
+
+	for i := 0; i < ConsoleMaxLines; i++ {
+		c.Print("x")
+	}
+	if n := len(c.Lines()); n != ConsoleMaxLines {
+		t.Errorf("console kept %d lines, want %d", n, ConsoleMaxLines)
+	}
+}
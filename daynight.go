@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 昼夜循环常量
+const (
+	DefaultDayLength = 60 * 60 * 10 // 默认一天的长度（帧，按60TPS约10分钟）
+
+	// 一天中的时间点（0为午夜，0.5为正午）
+	SunriseTime = 0.25 // 日出 06:00
+	SunsetTime  = 0.75 // 日落 18:00
+	TwilightLen = 0.05 // 黎明/黄昏过渡时长（约1.2小时）
+	MoonLight   = 0.25 // 夜晚的日光强度
+)
+
+// DayPhase 定义一天中的阶段
+type DayPhase int
+
+// 昼夜阶段常量定义
+const (
+	DayPhaseNight DayPhase = iota // 夜晚
+	DayPhaseDawn                  // 黎明
+	DayPhaseDay                   // 白天
+	DayPhaseDusk                  // 黄昏
+)
+
+// String 返回阶段名称
+func (p DayPhase) String() string {
+	switch p {
+	case DayPhaseDawn:
+		return "Dawn"
+	case DayPhaseDay:
+		return "Day"
+	case DayPhaseDusk:
+		return "Dusk"
+	default:
+		return "Night"
+	}
+}
+
+// ClockEvent 定义可订阅的时钟事件
+type ClockEvent int
+
+// 时钟事件常量定义
+const (
+	ClockEventSunrise ClockEvent = iota // 日出
+	ClockEventSunset                    // 日落
+)
+
+// WorldClock 世界时钟，每帧前进一次
+type WorldClock struct {
+	Tick      int64 // 世界创建以来经过的总帧数
+	DayLength int64 // 一天的帧数
+
+	listeners map[ClockEvent][]func(day int64)
+}
+
+// NewWorldClock 创建新的世界时钟，从第一天的早晨开始
+func NewWorldClock(dayLength int64) *WorldClock {
+	if dayLength <= 0 {
+		dayLength = DefaultDayLength
+	}
+	return &WorldClock{
+		Tick:      int64(float64(dayLength) * (SunriseTime + TwilightLen)),
+		DayLength: dayLength,
+		listeners: make(map[ClockEvent][]func(day int64)),
+	}
+}
+
+// Subscribe 订阅日出或日落事件，回调参数为当前天数
+func (c *WorldClock) Subscribe(event ClockEvent, fn func(day int64)) {
+	c.listeners[event] = append(c.listeners[event], fn)
+}
+
+// Advance 时钟前进一帧，跨越日出或日落时触发事件
+func (c *WorldClock) Advance() {
+	c.Tick++
+	tickOfDay := c.Tick % c.DayLength
+	switch tickOfDay {
+	case int64(float64(c.DayLength) * SunriseTime):
+		c.emit(ClockEventSunrise)
+	case int64(float64(c.DayLength) * SunsetTime):
+		c.emit(ClockEventSunset)
+	}
+}
+
+// emit 通知所有订阅者
+func (c *WorldClock) emit(event ClockEvent) {
+	for _, fn := range c.listeners[event] {
+		fn(c.Day())
+	}
+}
+
+// Day 返回当前是第几天（从1开始）
+func (c *WorldClock) Day() int64 {
+	return c.Tick/c.DayLength + 1
+}
+
+// TimeOfDay 返回一天中的时间，范围[0,1)，0为午夜
+func (c *WorldClock) TimeOfDay() float64 {
+	return float64(c.Tick%c.DayLength) / float64(c.DayLength)
+}
+
+// SetTimeOfDay 将时间调整到当天的指定时刻（不触发事件）
+func (c *WorldClock) SetTimeOfDay(t float64) {
+	t -= math.Floor(t)
+	c.Tick = (c.Day()-1)*c.DayLength + int64(t*float64(c.DayLength))
+}
+
+// Phase 返回当前所处的昼夜阶段
+func (c *WorldClock) Phase() DayPhase {
+	t := c.TimeOfDay()
+	switch {
+	case t >= SunriseTime-TwilightLen && t < SunriseTime+TwilightLen:
+		return DayPhaseDawn
+	case t >= SunriseTime+TwilightLen && t < SunsetTime-TwilightLen:
+		return DayPhaseDay
+	case t >= SunsetTime-TwilightLen && t < SunsetTime+TwilightLen:
+		return DayPhaseDusk
+	default:
+		return DayPhaseNight
+	}
+}
+
+// IsNight 判断当前是否为夜晚（日落到日出之间）
+func (c *WorldClock) IsNight() bool {
+	t := c.TimeOfDay()
+	return t < SunriseTime || t >= SunsetTime
+}
+
+// Daylight 返回当前日光强度（0~1），夜晚保留少量月光，黎明和黄昏线性过渡
+func (c *WorldClock) Daylight() float64 {
+	t := c.TimeOfDay()
+	var f float64
+	switch {
+	case t >= SunriseTime-TwilightLen && t < SunriseTime+TwilightLen:
+		f = (t - (SunriseTime - TwilightLen)) / (2 * TwilightLen)
+	case t >= SunriseTime+TwilightLen && t < SunsetTime-TwilightLen:
+		f = 1
+	case t >= SunsetTime-TwilightLen && t < SunsetTime+TwilightLen:
+		f = 1 - (t-(SunsetTime-TwilightLen))/(2*TwilightLen)
+	}
+	return MoonLight + (1-MoonLight)*f
+}
+
+// String 以“Day N HH:MM”格式显示时间
+func (c *WorldClock) String() string {
+	minutes := int(c.TimeOfDay() * 24 * 60)
+	return fmt.Sprintf("Day %d %02d:%02d", c.Day(), minutes/60, minutes%60)
+}
+
+// skyKeyframe 天空颜色关键帧
+type skyKeyframe struct {
+	time         float64
+	top, horizon color.RGBA
+}
+
+// 天空颜色关键帧，按时间排序，首尾相同以便循环
+var skyKeyframes = []skyKeyframe{
+	{0, color.RGBA{10, 10, 30, 255}, color.RGBA{30, 30, 60, 255}},
+	{SunriseTime - TwilightLen, color.RGBA{10, 10, 30, 255}, color.RGBA{30, 30, 60, 255}},
+	{SunriseTime, color.RGBA{80, 90, 160, 255}, color.RGBA{240, 150, 90, 255}},
+	{SunriseTime + TwilightLen, color.RGBA{90, 150, 230, 255}, color.RGBA{170, 210, 250, 255}},
+	{SunsetTime - TwilightLen, color.RGBA{90, 150, 230, 255}, color.RGBA{170, 210, 250, 255}},
+	{SunsetTime, color.RGBA{70, 60, 130, 255}, color.RGBA{250, 120, 70, 255}},
+	{SunsetTime + TwilightLen, color.RGBA{10, 10, 30, 255}, color.RGBA{30, 30, 60, 255}},
+	{1, color.RGBA{10, 10, 30, 255}, color.RGBA{30, 30, 60, 255}},
+}
+
+// lerpColor 在两个颜色之间线性插值
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(a.R) + (float64(b.R)-float64(a.R))*t),
+		G: uint8(float64(a.G) + (float64(b.G)-float64(a.G))*t),
+		B: uint8(float64(a.B) + (float64(b.B)-float64(a.B))*t),
+		A: uint8(float64(a.A) + (float64(b.A)-float64(a.A))*t),
+	}
+}
+
+// skyColors 返回指定时间的天顶色和地平线色
+func skyColors(timeOfDay float64) (top, horizon color.RGBA) {
+	for i := 1; i < len(skyKeyframes); i++ {
+		prev, next := skyKeyframes[i-1], skyKeyframes[i]
+		if timeOfDay <= next.time {
+			t := (timeOfDay - prev.time) / (next.time - prev.time)
+			return lerpColor(prev.top, next.top, t), lerpColor(prev.horizon, next.horizon, t)
+		}
+	}
+	last := skyKeyframes[len(skyKeyframes)-1]
+	return last.top, last.horizon
+}
+
+// whiteImage 用于DrawTriangles的纯白纹理（首次使用时创建）
+var whiteImage *ebiten.Image
+
+// getWhiteImage 返回纯白纹理
+func getWhiteImage() *ebiten.Image {
+	if whiteImage == nil {
+		img := ebiten.NewImage(3, 3)
+		img.Fill(color.White)
+		whiteImage = img.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+	}
+	return whiteImage
+}
+
+// drawSky 以垂直渐变绘制天空背景
+func (g *Game) drawSky(screen *ebiten.Image) {
+	top, horizon := skyColors(g.clock.TimeOfDay())
+	w, h := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+
+	vertex := func(x, y float32, c color.RGBA) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX: x, DstY: y, SrcX: 1, SrcY: 1,
+			ColorR: float32(c.R) / 255, ColorG: float32(c.G) / 255, ColorB: float32(c.B) / 255, ColorA: 1,
+		}
+	}
+	vertices := []ebiten.Vertex{
+		vertex(0, 0, top),
+		vertex(w, 0, top),
+		vertex(0, h, horizon),
+		vertex(w, h, horizon),
+	}
+	indices := []uint16{0, 1, 2, 1, 2, 3}
+	screen.DrawTriangles(vertices, indices, getWhiteImage(), nil)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWorldClockRollover(t *testing.T) {
+	c := NewWorldClock(100)
+	if c.Day() != 1 || c.Phase() != DayPhaseDay {
+		t.Fatalf("new clock at %s (%s), want the morning of day 1", c, c.Phase())
+	}
+	for c.Tick < 99 {
+		c.Advance()
+	}
+	if c.Day() != 1 || c.String() != "Day 1 23:45" {
+		t.Errorf("last tick of day 1 = %s", c)
+	}
+	c.Advance()
+	if c.Day() != 2 || c.TimeOfDay() != 0 || c.String() != "Day 2 00:00" {
+		t.Errorf("first tick of day 2 = %s (time of day %v)", c, c.TimeOfDay())
+	}
+	if !c.IsNight() || c.Daylight() != MoonLight {
+		t.Errorf("midnight: night %v, daylight %v", c.IsNight(), c.Daylight())
+	}
+
+	// 调整时间只改变当天的时刻
+	c.SetTimeOfDay(1.5)
+	if c.Day() != 2 || c.TimeOfDay() != 0.5 || c.Daylight() != 1 {
+		t.Errorf("after SetTimeOfDay(1.5): %s, daylight %v", c, c.Daylight())
+	}
+}
+
+func TestWorldClockEvents(t *testing.T) {
+	c := NewWorldClock(100)
+	var sunrises, sunsets []int64
+	c.Subscribe(ClockEventSunrise, func(day int64) { sunrises = append(sunrises, day) })
+	c.Subscribe(ClockEventSunset, func(day int64) { sunsets = append(sunsets, day) })
+
+	// 从第一天早晨前进两天：经过两次日落和两次日出
+	for i := 0; i < 200; i++ {
+		c.Advance()
+	}
+	if !reflect.DeepEqual(sunsets, []int64{1, 2}) || !reflect.DeepEqual(sunrises, []int64{2, 3}) {
+		t.Errorf("sunsets on days %v, sunrises on days %v, want [1 2] and [2 3]", sunsets, sunrises)
+	}
+
+	// 调整时间不触发事件
+	c.SetTimeOfDay(SunsetTime)
+	c.SetTimeOfDay(SunriseTime)
+	if len(sunsets) != 2 || len(sunrises) != 2 {
+		t.Errorf("SetTimeOfDay fired events: sunsets %v, sunrises %v", sunsets, sunrises)
+	}
+}
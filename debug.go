@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 调试界面常量
+const (
+	DebugLineHeight     = 16 // 调试文字行高（逻辑像素）
+	DebugMemInterval    = 60 // 内存统计的采样间隔（帧）
+	ConsoleRepeatDelay  = 30 // 按住退格键开始重复删除的帧数
+	ConsoleRepeatPeriod = 3  // 重复删除的间隔（帧）
+)
+
+// 调试界面配色
+var (
+	debugBackground   = color.RGBA{0, 0, 0, 140}
+	consoleBackground = color.RGBA{0, 0, 0, 180}
+)
+
+// DebugOverlay 调试信息面板（F3切换）
+type DebugOverlay struct {
+	Visible  bool
+	mem      runtime.MemStats // 最近一次采样的内存统计
+	memTimer int
+}
+
+// sampleMemory 定期采样内存统计（ReadMemStats会暂停所有goroutine，不宜每帧调用）
+func (d *DebugOverlay) sampleMemory() {
+	if d.memTimer--; d.memTimer <= 0 {
+		runtime.ReadMemStats(&d.mem)
+		d.memTimer = DebugMemInterval
+	}
+}
+
+// debugLines 返回调试面板的各行文字
+func (g *Game) debugLines() []string {
+	modeText := "Creative"
+	if g.gameMode == GameModeSurvival {
+		modeText = "Survival"
+	}
+	tg := g.terrain()
+	mouseX, mouseY := g.getMouseWorldPosition()
+	cursor := toGridPos(mouseX, mouseY)
+	mem := g.debug.mem
+	undoCount, redoCount := g.history.Len()
+
+	lines := []string{
+		fmt.Sprintf("FPS: %.1f  TPS: %.1f", ebiten.ActualFPS(), ebiten.ActualTPS()),
+		fmt.Sprintf("Player: (%.1f, %.1f)  Velocity: (%.2f, %.2f)  On Ground: %t",
+			g.player.X, g.player.Y, g.player.VX, g.player.VY, g.player.OnGround),
+		fmt.Sprintf("Camera: (%.1f, %.1f) Zoom: %.2fx", g.camera.X, g.camera.Y, g.camera.Zoom),
+		fmt.Sprintf("World Bound: (%.0f,%.0f)-(%.0f,%.0f)", g.worldMinX, g.worldMinY, g.worldMaxX, g.worldMaxY),
+		fmt.Sprintf("Mode: %s  Item: %s", modeText, itemRegistry[g.currentItemType].Name),
+		fmt.Sprintf("Time: %s (%s)", g.clock, g.clock.Phase()),
+		fmt.Sprintf("Chunks: %d loaded, %d drawn, %d rebuilt, %d draw calls",
+			len(g.chunks), g.renderer.Stats.ChunksVisible, g.renderer.Stats.ChunksBuilt, g.renderer.Stats.DrawCalls),
+		fmt.Sprintf("Blocks: %d (%d cells)  Entities: %d", len(g.blocks), len(g.pathGrid()), g.entities.Count()),
+		fmt.Sprintf("Biome: %s", g.terrainUnderPlayer()),
+		fmt.Sprintf("Cursor: (%d, %d)  Light: %d  Height: %d", cursor.X, cursor.Y, g.lightLevelAt(cursor), tg.SurfaceHeight(cursor.X)),
+		fmt.Sprintf("Noise: continental %.3f  cave %.3f  (%s)",
+			tg.ContinentalNoise(cursor.X), tg.CaveNoise(cursor.X, cursor.Y), tg.Biome(cursor.X)),
+		fmt.Sprintf("History: %d undo, %d redo", undoCount, redoCount),
+		fmt.Sprintf("Memory: %.1f MB alloc, %.1f MB sys, %d GC", float64(mem.Alloc)/(1<<20), float64(mem.Sys)/(1<<20), mem.NumGC),
+	}
+	if g.selecting {
+		lines = append(lines, g.toolStatus())
+	} else {
+		lines = append(lines, fmt.Sprintf("Middle mouse button: %s tool (T/Shift+T to change)", g.buildTool))
+	}
+	return append(lines,
+		"M: Mode  1-8/Q/Wheel: Item  G: Grid  Tab: Map  `: Console",
+		"Ctrl+Z/Y: Undo/Redo  Ctrl+C/X/V: Copy/Cut/Paste (creative)",
+	)
+}
+
+// drawDebugOverlay 在左上角绘制调试面板
+func (g *Game) drawDebugOverlay(screen *ebiten.Image) {
+	lines := g.debugLines()
+	width := 0
+	for _, line := range lines {
+		width = max(width, len(line))
+	}
+	// 调试字体每个字符6像素宽
+	ebitenutil.DrawRect(screen, 6, 6, float64(width*6+8), float64(len(lines)*DebugLineHeight+6), debugBackground)
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, 10, 8+i*DebugLineHeight)
+	}
+}
+
+// keyRepeated 判断按键是否刚按下或处于按住后的重复触发帧
+func keyRepeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d >= ConsoleRepeatDelay && (d-ConsoleRepeatDelay)%ConsoleRepeatPeriod == 0)
+}
+
+// updateConsole 处理控制台输入：文字、退格、回车执行、上下键浏览历史，Esc或`关闭
+func (g *Game) updateConsole() {
+	c := g.console
+	c.blink++
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) {
+		c.Open = false
+		return
+	}
+	c.Input += string(ebiten.AppendInputChars(nil))
+	if keyRepeated(ebiten.KeyBackspace) && len(c.Input) > 0 {
+		runes := []rune(c.Input)
+		c.Input = string(runes[:len(runes)-1])
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowUp) {
+		c.HistoryPrev()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyArrowDown) {
+		c.HistoryNext()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter) {
+		c.Submit()
+	}
+}
+
+// drawConsole 在界面底部绘制控制台输出和输入行
+func (g *Game) drawConsole(screen *ebiten.Image) {
+	c := g.console
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	lines := c.Lines()
+	height := (len(lines)+1)*DebugLineHeight + 8
+	top := h - height
+	ebitenutil.DrawRect(screen, 0, float64(top), float64(w), float64(height), consoleBackground)
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, line, 8, top+4+i*DebugLineHeight)
+	}
+
+	// 输入行和闪烁的光标
+	prompt := "> " + c.Input
+	if c.blink/30%2 == 0 {
+		prompt += "_"
+	}
+	ebitenutil.DebugPrintAt(screen, prompt, 8, top+4+len(lines)*DebugLineHeight)
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// 实体相关常量
+const (
+	ItemDropSize         = 20       // 掉落物尺寸
+	ItemDropDespawnTicks = 60 * 300 // 掉落物存在时间（5分钟，按60TPS计算）
+	ItemDropPickupDelay  = 30       // 掉落物生成后可被拾取前的等待帧数
+	ItemDropFriction     = 0.8      // 掉落物在地面上的水平摩擦系数
+)
+
+// EntityID 实体唯一标识
+type EntityID int
+
+// EntityKind 定义实体种类
+type EntityKind int
+
+// 实体种类常量定义
+const (
+	EntityKindPlayer   EntityKind = iota // 玩家
+	EntityKindItemDrop                   // 掉落物
+	EntityKindMob                        // 生物
+)
+
+// PlayerComponent 玩家组件，标记由键盘输入控制的实体
+type PlayerComponent struct{}
+
+// ItemDropComponent 掉落物组件
+type ItemDropComponent struct {
+	Type ItemType // 掉落的物品类型
+	Age  int      // 已存在的帧数
+}
+
+// Entity 定义世界中的可移动对象（玩家、生物、掉落物、投射物等）
+type Entity struct {
+	ID       EntityID
+	Kind     EntityKind
+	X, Y     float64 // 左上角世界坐标
+	W, H     float64 // 碰撞盒尺寸
+	VX, VY   float64 // 速度
+	OnGround bool    // 是否站在方块上
+	Gravity  bool    // 是否受重力影响
+	Removed  bool    // 标记为待移除
+
+	// 组件（按需挂载）
+	Player   *PlayerComponent
+	ItemDrop *ItemDropComponent
+	Mob      *MobComponent
+
+	// 更新与绘制钩子
+	OnUpdate func(g *Game, e *Entity)
+	OnDraw   func(g *Game, e *Entity, screen *ebiten.Image, op *ebiten.DrawImageOptions)
+}
+
+// Rect 返回实体的碰撞盒
+func (e *Entity) Rect() Block {
+	return Block{e.X, e.Y, e.W, e.H, 0}
+}
+
+// CenterX 返回实体中心X坐标
+func (e *Entity) CenterX() float64 {
+	return e.X + e.W/2
+}
+
+// CenterY 返回实体中心Y坐标
+func (e *Entity) CenterY() float64 {
+	return e.Y + e.H/2
+}
+
+// EntityManager 管理世界中的所有实体
+type EntityManager struct {
+	nextID   EntityID
+	entities []*Entity
+	byID     map[EntityID]*Entity
+}
+
+// NewEntityManager 创建新的实体管理器
+func NewEntityManager() *EntityManager {
+	return &EntityManager{
+		nextID: 1,
+		byID:   make(map[EntityID]*Entity),
+	}
+}
+
+// Spawn 为实体分配ID并加入世界
+func (m *EntityManager) Spawn(e *Entity) *Entity {
+	e.ID = m.nextID
+	m.nextID++
+	m.entities = append(m.entities, e)
+	m.byID[e.ID] = e
+	return e
+}
+
+// Get 根据ID获取实体
+func (m *EntityManager) Get(id EntityID) *Entity {
+	return m.byID[id]
+}
+
+// All 返回当前所有实体
+func (m *EntityManager) All() []*Entity {
+	return m.entities
+}
+
+// Count 返回实体数量
+func (m *EntityManager) Count() int {
+	return len(m.entities)
+}
+
+// Update 调用每个实体的更新钩子，并清理被标记移除的实体
+func (m *EntityManager) Update(g *Game) {
+	// 更新过程中可能生成新实体，只遍历本帧开始时已有的实体
+	n := len(m.entities)
+	for i := 0; i < n; i++ {
+		e := m.entities[i]
+		if e.Removed {
+			continue
+		}
+		if e.OnUpdate != nil {
+			e.OnUpdate(g, e)
+		}
+	}
+
+	alive := m.entities[:0]
+	for _, e := range m.entities {
+		if e.Removed {
+			delete(m.byID, e.ID)
+			continue
+		}
+		alive = append(alive, e)
+	}
+	for i := len(alive); i < len(m.entities); i++ {
+		m.entities[i] = nil
+	}
+	m.entities = alive
+}
+
+// Draw 调用每个实体的绘制钩子
+func (m *EntityManager) Draw(g *Game, screen *ebiten.Image, op *ebiten.DrawImageOptions) {
+	for _, e := range m.entities {
+		if e.OnDraw != nil {
+			e.OnDraw(g, e, screen, op)
+		}
+	}
+}
+
+// moveEntity 按速度移动实体并处理与实心方块的碰撞（先水平后垂直），液体不阻挡移动
+func (g *Game) moveEntity(e *Entity) {
+	// 1. 水平移动
+	oldX := e.X
+	e.X += e.VX
+
+	// 2. 检测水平碰撞
+	rect := e.Rect()
+	for _, block := range g.solidCells(rect) {
+		if checkCollision(rect, block) {
+			// 从左侧碰撞
+			if oldX <= block.X-e.W {
+				e.X = block.X - e.W
+				// 从右侧碰撞
+			} else if oldX >= block.X+block.W {
+				e.X = block.X + block.W
+			}
+			rect = e.Rect()
+		}
+	}
+
+	// 边界检查（支持负数坐标）
+	if g.worldMinX != 0 && g.worldMaxX != 0 { // 确保世界边界已初始化
+		if e.X < g.worldMinX {
+			e.X = g.worldMinX
+		} else if e.X > g.worldMaxX-e.W {
+			e.X = g.worldMaxX - e.W
+		}
+	}
+
+	// 3. 应用重力
+	if e.Gravity {
+		e.VY += Gravity
+		if e.VY > PlayerMaxFall {
+			e.VY = PlayerMaxFall
+		}
+	}
+
+	// 4. 更新垂直位置
+	oldY := e.Y
+	e.Y += e.VY
+
+	// 5. 检测垂直碰撞
+	e.OnGround = false
+	rect = e.Rect()
+	for _, block := range g.solidCells(rect) {
+		if checkCollision(rect, block) {
+			// 从上方落下碰撞
+			if e.VY > 0 && oldY <= block.Y-e.H {
+				e.Y = block.Y - e.H
+				e.VY = 0
+				e.OnGround = true
+				// 从下方撞击方块
+			} else if e.VY < 0 && oldY >= block.Y+block.H {
+				e.Y = block.Y + block.H
+				e.VY = 0
+			}
+			rect = e.Rect()
+		}
+	}
+}
+
+// solidCells 返回与矩形重叠的实心格子（每个格子作为一个方块），液体不阻挡移动
+func (g *Game) solidCells(rect Block) []Block {
+	grid := g.pathGrid()
+	var cells []Block
+	x0, x1 := cellSpan(rect.X, rect.W)
+	y0, y1 := cellSpan(rect.Y, rect.H)
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			if t, ok := grid[GridPos{x, y}]; ok && t.Solid() {
+				cells = append(cells, Block{float64(x * BlockSize), float64(y * BlockSize), BlockSize, BlockSize, t})
+			}
+		}
+	}
+	return cells
+}
+
+// cellSpan 返回从pos开始、长度为size的区间覆盖的格子范围
+func cellSpan(pos, size float64) (int, int) {
+	return int(math.Floor(pos / BlockSize)), int(math.Ceil((pos+size)/BlockSize)) - 1
+}
+
+// drawEntityRect 以纯色矩形绘制实体
+func drawEntityRect(screen *ebiten.Image, op *ebiten.DrawImageOptions, e *Entity, clr color.Color) {
+	x0, y0 := op.GeoM.Apply(e.X, e.Y)
+	x1, y1 := op.GeoM.Apply(e.X+e.W, e.Y+e.H)
+	ebitenutil.DrawRect(screen, x0, y0, x1-x0, y1-y0, clr)
+}
+
+// newPlayerEntity 创建玩家实体
+func newPlayerEntity(x, y float64) *Entity {
+	return &Entity{
+		Kind:     EntityKindPlayer,
+		X:        x,
+		Y:        y,
+		W:        PlayerSize,
+		H:        PlayerSize,
+		Gravity:  true,
+		Player:   &PlayerComponent{},
+		OnUpdate: updatePlayer,
+		OnDraw: func(g *Game, e *Entity, screen *ebiten.Image, op *ebiten.DrawImageOptions) {
+			// 绘制玩家（红色方块）
+			drawEntityRect(screen, op, e, color.RGBA{255, 0, 0, 255})
+		},
+	}
+}
+
+// updatePlayer 处理玩家输入并移动玩家
+func updatePlayer(g *Game, e *Entity) {
+	left := ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA)
+	right := ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD)
+	jump := ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsKeyPressed(ebiten.KeyW)
+	g.steerPlayer(e, left, right, jump)
+}
+
+// steerPlayer 按方向键和跳跃键的状态移动玩家
+func (g *Game) steerPlayer(e *Entity, left, right, jump bool) {
+	// 1. 处理水平移动
+	e.VX = 0
+	if left {
+		e.VX -= PlayerSpeed
+	}
+	if right {
+		e.VX += PlayerSpeed
+	}
+
+	// 2. 处理跳跃
+	if jump && e.OnGround {
+		e.VY = -JumpPower
+		e.OnGround = false
+	}
+
+	// 3. 移动并处理碰撞
+	g.moveEntity(e)
+}
+
+// newItemDropEntity 创建掉落物实体，位于方块中心并以水平速度vx向上弹出
+func newItemDropEntity(itemType ItemType, blockX, blockY, vx float64) *Entity {
+	return &Entity{
+		Kind:     EntityKindItemDrop,
+		X:        blockX + BlockSize/2 - ItemDropSize/2,
+		Y:        blockY + BlockSize/2 - ItemDropSize/2,
+		W:        ItemDropSize,
+		H:        ItemDropSize,
+		VX:       vx,
+		VY:       -3,
+		Gravity:  true,
+		ItemDrop: &ItemDropComponent{Type: itemType},
+		OnUpdate: updateItemDrop,
+		OnDraw: func(g *Game, e *Entity, screen *ebiten.Image, op *ebiten.DrawImageOptions) {
+			clr := color.RGBA{100, 200, 100, 255}
+			if item, exists := itemRegistry[e.ItemDrop.Type]; exists {
+				clr = item.Color
+			}
+			drawEntityRect(screen, op, e, clr)
+		},
+	}
+}
+
+// updateItemDrop 更新掉落物：物理、拾取和消失计时
+func updateItemDrop(g *Game, e *Entity) {
+	drop := e.ItemDrop
+	drop.Age++
+	if drop.Age >= ItemDropDespawnTicks {
+		e.Removed = true
+		return
+	}
+
+	g.moveEntity(e)
+	if e.OnGround {
+		e.VX *= ItemDropFriction
+		if math.Abs(e.VX) < 0.05 {
+			e.VX = 0
+		}
+	}
+
+	// 玩家接触后拾取
+	if drop.Age >= ItemDropPickupDelay && g.player != nil && checkCollision(e.Rect(), g.player.Rect()) {
+		g.inventory[drop.Type]++
+		e.Removed = true
+	}
+}
+
+// spawnItemDrop 在指定方块位置生成掉落物，随机向左或向右轻微弹出
+func (g *Game) spawnItemDrop(itemType ItemType, blockX, blockY float64) *Entity {
+	return g.entities.Spawn(newItemDropEntity(itemType, blockX, blockY, (g.rng.Float64()-0.5)*2))
+}
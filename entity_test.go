@@ -0,0 +1,99 @@
+package main
+
+import "testing"
+
+func TestPlayerStandsAndWalksAtSpawn(t *testing.T) {
+	g := newExploredGame(4)
+	surface := g.terrain().SurfaceHeight(0)
+	if !g.grid.Solid(0, surface) {
+		t.Fatalf("no surface block at spawn (0, %d)", surface)
+	}
+	for y := surface - 2; y < surface; y++ {
+		if g.grid.Solid(0, y) {
+			t.Fatalf("cell (0, %d) above the spawn surface is solid", y)
+		}
+	}
+
+	// 玩家在出生点落到地表上站稳
+	p := g.entities.Spawn(newPlayerEntity(0, float64(surface*BlockSize-PlayerSize-10)))
+	for i := 0; i < 30; i++ {
+		g.steerPlayer(p, false, false, false)
+	}
+	if !p.OnGround || p.Y != float64(surface*BlockSize-PlayerSize) {
+		t.Fatalf("player at y=%v (on ground: %v), want standing on the surface at y=%v", p.Y, p.OnGround, surface*BlockSize-PlayerSize)
+	}
+
+	// 按住右键（需要时跳跃）向右行走
+	for i := 0; i < 120; i++ {
+		g.steerPlayer(p, false, true, true)
+	}
+	if p.X < 5*BlockSize {
+		t.Errorf("player walked to x=%v after 120 ticks, want at least %v", p.X, 5*BlockSize)
+	}
+}
+
+func TestPlayerSinksThroughWater(t *testing.T) {
+	// 水面以下是石头：玩家穿过水落到石头上
+	g := &Game{entities: NewEntityManager()}
+	g.blocks = []Block{
+		{X: 0, Y: 2 * BlockSize, W: BlockSize, H: BlockSize, Type: ItemTypeWater},
+		{X: 0, Y: 3 * BlockSize, W: BlockSize, H: BlockSize, Type: ItemTypeWater},
+		{X: 0, Y: 4 * BlockSize, W: BlockSize, H: BlockSize, Type: ItemTypeStone},
+	}
+	p := g.entities.Spawn(newPlayerEntity(0, 0))
+	for i := 0; i < 60; i++ {
+		g.steerPlayer(p, false, false, false)
+	}
+	if !p.OnGround || p.Y != 4*BlockSize-PlayerSize {
+		t.Errorf("player at y=%v (on ground: %v), want standing on the stone at y=%v", p.Y, p.OnGround, 4*BlockSize-PlayerSize)
+	}
+}
+
+// newDropGame 创建只有一排石头地面（第1行）和站在地面上的玩家的游戏
+func newDropGame() *Game {
+	var floor []Block
+	for x := -5; x <= 5; x++ {
+		floor = append(floor, Block{float64(x * BlockSize), BlockSize, BlockSize, BlockSize, ItemTypeStone})
+	}
+	g := &Game{entities: NewEntityManager(), inventory: make(map[ItemType]int), blocks: floor, grid: newBlockGrid(floor)}
+	g.player = g.entities.Spawn(newPlayerEntity(0, 0))
+	return g
+}
+
+func TestItemDropPickup(t *testing.T) {
+	g := newDropGame()
+	// 掉落物落在玩家脚下，等待拾取延迟后才被拾取
+	drop := newItemDropEntity(ItemTypeDirt, 0, 0, 0)
+	for i := 1; i < ItemDropPickupDelay; i++ {
+		updateItemDrop(g, drop)
+	}
+	if drop.Removed || g.inventory[ItemTypeDirt] != 0 {
+		t.Fatalf("drop picked up after %d ticks, before the pickup delay", drop.ItemDrop.Age)
+	}
+	if !drop.OnGround {
+		t.Errorf("drop at y=%v not resting on the floor", drop.Y)
+	}
+	updateItemDrop(g, drop)
+	if !drop.Removed || g.inventory[ItemTypeDirt] != 1 {
+		t.Errorf("drop not picked up after the pickup delay (inventory %d)", g.inventory[ItemTypeDirt])
+	}
+}
+
+func TestItemDropDespawn(t *testing.T) {
+	g := newDropGame()
+	// 玩家够不到的掉落物在地面上停止滑动，到时间后消失
+	drop := newItemDropEntity(ItemTypeDirt, 4*BlockSize, 0, 1)
+	for i := 1; i < ItemDropDespawnTicks; i++ {
+		updateItemDrop(g, drop)
+	}
+	if drop.Removed {
+		t.Fatal("drop despawned early")
+	}
+	if drop.VX != 0 || !drop.OnGround {
+		t.Errorf("drop still moving: vx %v, on ground %v", drop.VX, drop.OnGround)
+	}
+	updateItemDrop(g, drop)
+	if !drop.Removed || g.inventory[ItemTypeDirt] != 0 {
+		t.Errorf("drop not despawned after %d ticks (inventory %d)", ItemDropDespawnTicks, g.inventory[ItemTypeDirt])
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// DefaultHistoryDepth 默认保留的撤销步数
+const DefaultHistoryDepth = 100
+
+// blockEdit 一次方块增删
+type blockEdit struct {
+	Block Block
+	Added bool // true为放置，false为破坏
+}
+
+// editEntry 撤销历史中的一步，框选等批量操作的所有增删合为一步
+type editEntry []blockEdit
+
+// EditHistory 方块编辑的撤销/重做历史
+type EditHistory struct {
+	Depth int // 最多保留的步数
+
+	undo, redo []editEntry
+	group      editEntry // 正在记录的批量操作
+	grouping   int       // Begin的嵌套层数
+}
+
+// NewEditHistory 创建最多保留depth步的编辑历史（depth <= 0时使用默认值）
+func NewEditHistory(depth int) *EditHistory {
+	if depth <= 0 {
+		depth = DefaultHistoryDepth
+	}
+	return &EditHistory{Depth: depth}
+}
+
+// Begin 开始批量操作，直到对应的End之前记录的增删合为一步
+func (h *EditHistory) Begin() {
+	if h == nil {
+		return
+	}
+	h.grouping++
+}
+
+// End 结束批量操作，有增删时作为一步加入历史
+func (h *EditHistory) End() {
+	if h == nil || h.grouping == 0 {
+		return
+	}
+	if h.grouping--; h.grouping == 0 && len(h.group) > 0 {
+		h.push(h.group)
+		h.group = nil
+	}
+}
+
+// Record 记录一次方块增删，新的编辑会清空重做历史
+func (h *EditHistory) Record(block Block, added bool) {
+	if h == nil {
+		return
+	}
+	edit := blockEdit{Block: block, Added: added}
+	if h.grouping > 0 {
+		h.group = append(h.group, edit)
+		return
+	}
+	h.push(editEntry{edit})
+}
+
+// push 加入一步历史，超出Depth时丢弃最早的一步
+func (h *EditHistory) push(entry editEntry) {
+	h.undo = append(h.undo, entry)
+	if over := len(h.undo) - h.Depth; over > 0 {
+		h.undo = append(h.undo[:0:0], h.undo[over:]...)
+	}
+	h.redo = nil
+}
+
+// CanUndo 是否有可撤销的步骤
+func (h *EditHistory) CanUndo() bool {
+	return h != nil && len(h.undo) > 0
+}
+
+// CanRedo 是否有可重做的步骤
+func (h *EditHistory) CanRedo() bool {
+	return h != nil && len(h.redo) > 0
+}
+
+// Len 返回可撤销和可重做的步数
+func (h *EditHistory) Len() (int, int) {
+	if h == nil {
+		return 0, 0
+	}
+	return len(h.undo), len(h.redo)
+}
+
+// popUndo 取出最近一步并移入重做历史
+func (h *EditHistory) popUndo() (editEntry, bool) {
+	if !h.CanUndo() {
+		return nil, false
+	}
+	entry := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, entry)
+	return entry, true
+}
+
+// popRedo 取出最近撤销的一步并移回撤销历史
+func (h *EditHistory) popRedo() (editEntry, bool) {
+	if !h.CanRedo() {
+		return nil, false
+	}
+	entry := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, entry)
+	return entry, true
+}
+
+// undo 撤销最近一步编辑，返回是否有可撤销的步骤
+func (g *Game) undo() bool {
+	entry, ok := g.history.popUndo()
+	if ok {
+		g.replayEdits(entry, true)
+	}
+	return ok
+}
+
+// redo 重做最近撤销的一步编辑，返回是否有可重做的步骤
+func (g *Game) redo() bool {
+	entry, ok := g.history.popRedo()
+	if ok {
+		g.replayEdits(entry, false)
+	}
+	return ok
+}
+
+// replayEdits 按顺序（撤销时逆序取反）回放一步编辑，不检查放置规则，也不记入历史
+//
+// 已卸载区块中的编辑只记录在g.edits中，区块重新加载时生效。
+func (g *Game) replayEdits(entry editEntry, reverse bool) {
+	type cell struct{ x, y float64 }
+	touched := make(map[cell]bool, len(entry))
+	for _, edit := range entry {
+		touched[cell{edit.Block.X, edit.Block.Y}] = true
+	}
+	blocksAt := func() []Block {
+		var out []Block
+		for _, block := range g.blocks {
+			if touched[cell{block.X, block.Y}] {
+				out = append(out, block)
+			}
+		}
+		return out
+	}
+
+	before := blocksAt()
+	for i := range entry {
+		edit := entry[i]
+		if reverse {
+			edit = entry[len(entry)-1-i]
+			edit.Added = !edit.Added
+		}
+		if g.chunkUnloaded(toGridPos(edit.Block.X, edit.Block.Y)) {
+			if edit.Added {
+				g.recordEdits([]Block{edit.Block}, nil)
+			} else {
+				g.recordEdits(nil, []Block{edit.Block})
+			}
+			continue
+		}
+		if edit.Added {
+			g.blocks = append(g.blocks, edit.Block)
+			continue
+		}
+		for j, block := range g.blocks {
+			if block.X == edit.Block.X && block.Y == edit.Block.Y {
+				g.blocks = append(g.blocks[:j:j], g.blocks[j+1:]...)
+				break
+			}
+		}
+	}
+	// 以回放前后涉及位置上的方块作为增删，一次性同步网格、光照和渲染缓存
+	g.editBlocks(blocksAt(), before)
+}
+
+// updateHistory 处理撤销（Ctrl+Z）和重做（Ctrl+Y或Ctrl+Shift+Z）
+//
+// 生存模式下撤销破坏会在保留掉落物的同时恢复方块，因此只在创造模式下可用。
+func (g *Game) updateHistory() {
+	if g.gameMode != GameModeCreative || !ebiten.IsKeyPressed(ebiten.KeyControl) {
+		return
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyY),
+		inpututil.IsKeyJustPressed(ebiten.KeyZ) && ebiten.IsKeyPressed(ebiten.KeyShift):
+		g.redo()
+	case inpututil.IsKeyJustPressed(ebiten.KeyZ):
+		g.undo()
+	}
+}
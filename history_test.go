@@ -0,0 +1,173 @@
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+// sortedBlocks 返回按位置排序的方块副本，便于比较方块集合
+func sortedBlocks(blocks []Block) []Block {
+	out := append([]Block(nil), blocks...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].X != out[j].X {
+			return out[i].X < out[j].X
+		}
+		return out[i].Y < out[j].Y
+	})
+	return out
+}
+
+// equalBlocks 比较两个方块集合是否完全相同
+func equalBlocks(a, b []Block) bool {
+	a, b = sortedBlocks(a), sortedBlocks(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEditHistoryDepth(t *testing.T) {
+	h := NewEditHistory(3)
+	for i := 0; i < 5; i++ {
+		h.Record(Block{X: float64(i) * BlockSize}, true)
+	}
+	if undo, redo := h.Len(); undo != 3 || redo != 0 {
+		t.Fatalf("Len = %d, %d, want 3, 0", undo, redo)
+	}
+	entry, _ := h.popUndo()
+	if entry[0].Block.X != 4*BlockSize {
+		t.Fatalf("latest entry = %+v, want block at x=%v", entry, 4*BlockSize)
+	}
+	h.popUndo()
+	h.popUndo()
+	if h.CanUndo() {
+		t.Fatal("oldest entries should have been dropped")
+	}
+
+	// 新的编辑清空重做历史
+	h.Record(Block{}, true)
+	if h.CanRedo() {
+		t.Fatal("recording should clear redo history")
+	}
+}
+
+func TestEditHistoryGroup(t *testing.T) {
+	h := NewEditHistory(0)
+	h.Begin()
+	h.Record(Block{X: 0}, true)
+	h.Begin() // 嵌套的批量操作并入外层
+	h.Record(Block{X: BlockSize}, true)
+	h.End()
+	h.Record(Block{X: 2 * BlockSize}, false)
+	h.End()
+
+	// 空的批量操作不产生历史
+	h.Begin()
+	h.End()
+
+	if undo, _ := h.Len(); undo != 1 {
+		t.Fatalf("undo steps = %d, want 1", undo)
+	}
+	if entry, _ := h.popUndo(); len(entry) != 3 {
+		t.Fatalf("grouped entry has %d edits, want 3", len(entry))
+	}
+}
+
+func TestUndoBoxFill(t *testing.T) {
+	g := newExploredGame(2)
+	g.gameMode = GameModeCreative
+	g.currentItemType = ItemTypeStone
+	g.history = NewEditHistory(0)
+
+	// 先破坏一个方块，确认框选与之前的编辑分开撤销
+	target := g.blocks[0]
+	if _, ok := g.removeBlock(target.X, target.Y); !ok {
+		t.Fatal("removeBlock failed")
+	}
+
+	before := append([]Block(nil), g.blocks...)
+	gridBefore := len(g.grid)
+	g.selectionStartX, g.selectionStartY = -4*BlockSize, -30*BlockSize
+	g.selectionEndX, g.selectionEndY = 4*BlockSize+1, 2*BlockSize+1
+	g.applyTool()
+	after := append([]Block(nil), g.blocks...)
+	if len(after) == len(before) {
+		t.Fatal("box fill placed no blocks")
+	}
+	if undo, _ := g.history.Len(); undo != 2 {
+		t.Fatalf("undo steps = %d, want 2 (break + box fill)", undo)
+	}
+
+	if !g.undo() {
+		t.Fatal("undo returned false")
+	}
+	if !equalBlocks(g.blocks, before) {
+		t.Fatalf("undo left %d blocks, want exact prior set of %d", len(g.blocks), len(before))
+	}
+	if len(g.grid) != gridBefore {
+		t.Fatalf("grid has %d cells after undo, want %d", len(g.grid), gridBefore)
+	}
+
+	if !g.redo() {
+		t.Fatal("redo returned false")
+	}
+	if !equalBlocks(g.blocks, after) {
+		t.Fatal("redo did not restore the box fill")
+	}
+
+	// 撤销两步后破坏的方块也恢复
+	g.undo()
+	g.undo()
+	if !g.isBlockAt(target.X, target.Y) {
+		t.Fatal("undo did not restore the broken block")
+	}
+	if g.undo() {
+		t.Fatal("undo with empty history should return false")
+	}
+}
+
+func TestUndoAfterWalkingAway(t *testing.T) {
+	g := newStreamingGame()
+	g.history = NewEditHistory(0)
+	surface := g.terrain().SurfaceHeight(0)
+	g.moveTo(0, surface-1)
+	fresh := len(g.blocks)
+
+	// 挖掉地表方块、放置一个木头，然后离开到出生点区块卸载
+	if _, ok := g.removeBlock(0, float64(surface*BlockSize)); !ok {
+		t.Fatal("no surface block to remove")
+	}
+	g.currentItemType = ItemTypeWood
+	g.addBlock(0, float64((surface-3)*BlockSize))
+	g.moveTo(1000, surface-1)
+	away := len(g.blocks)
+
+	// 撤销不把方块加入已卸载的区块
+	g.undo()
+	g.undo()
+	if n := len(g.blocks); n != away {
+		t.Errorf("%d blocks after undoing away from spawn, want %d", n, away)
+	}
+	for p := range g.grid {
+		if p.X < 500 {
+			t.Fatalf("undo added grid cell %v in an unloaded chunk", p)
+		}
+	}
+
+	// 回到出生点后撤销生效，方块不重复
+	g.moveTo(0, surface-1)
+	if n := len(g.blocks); n != fresh {
+		t.Errorf("%d blocks after returning, want %d as generated", n, fresh)
+	}
+	if !g.grid.Solid(0, surface) {
+		t.Error("undone removal not restored after returning")
+	}
+	if _, ok := g.grid[GridPos{0, surface - 3}]; ok {
+		t.Error("undone placement still in the world after returning")
+	}
+}
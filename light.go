@@ -0,0 +1,307 @@
+package main
+
+import (
+	"image/color"
+	"math"
+)
+
+// 光照相关常量
+const (
+	MaxLightLevel    = 15  // 最高光照等级
+	SolidLightCost   = 3   // 光线穿过实心方块时每格衰减
+	AirLightCost     = 1   // 光线穿过空气时每格衰减
+	LightShaftLimit  = 64  // 列顶变化时最多向下重算的格数
+	MinBrightness    = 0.1 // 完全黑暗时方块保留的亮度
+	MobSpawnMaxLight = 7   // 敌对生物可生成的最高光照等级
+)
+
+// LightWorld 光照引擎查询世界的接口
+type LightWorld interface {
+	Solid(x, y int) bool
+	Emission(x, y int) int
+}
+
+// LightEngine 二维光照引擎，分别维护天空光与方块光
+//
+// 每列最高的实心方块之上为阳光直射区域，天空光恒为最大值且不存储；
+// 其余格子的光照由直射区域和发光方块向四周扩散得到，缺省为0。
+type LightEngine struct {
+	world LightWorld
+	tops  map[int]int       // 每列最高实心方块的Y坐标
+	sky   map[GridPos]uint8 // 非直射格子的天空光
+	block map[GridPos]uint8 // 方块光
+}
+
+// NewLightEngine 创建新的光照引擎
+func NewLightEngine(world LightWorld) *LightEngine {
+	return &LightEngine{
+		world: world,
+		tops:  make(map[int]int),
+		sky:   make(map[GridPos]uint8),
+		block: make(map[GridPos]uint8),
+	}
+}
+
+// isDirectSun 判断格子是否被阳光直射（所在列中没有更高的实心方块）
+func (le *LightEngine) isDirectSun(x, y int) bool {
+	top, ok := le.tops[x]
+	return !ok || y < top
+}
+
+// SkyLight 返回格子的天空光等级
+func (le *LightEngine) SkyLight(x, y int) int {
+	if le.isDirectSun(x, y) {
+		return MaxLightLevel
+	}
+	return int(le.sky[GridPos{x, y}])
+}
+
+// BlockLight 返回格子的方块光等级
+func (le *LightEngine) BlockLight(x, y int) int {
+	return int(le.block[GridPos{x, y}])
+}
+
+// Light 返回格子在给定日光强度（0~1）下的综合光照等级
+func (le *LightEngine) Light(x, y int, daylight float64) int {
+	sky := int(math.Round(float64(le.SkyLight(x, y)) * daylight))
+	if bl := le.BlockLight(x, y); bl > sky {
+		return bl
+	}
+	return sky
+}
+
+// Brightness 返回方块表面的亮度（0~1），取方块及其四邻中最亮的光照
+func (le *LightEngine) Brightness(x, y int, daylight float64) float64 {
+	level := le.Light(x, y, daylight)
+	for _, d := range neighborOffsets {
+		if l := le.Light(x+d.X, y+d.Y, daylight); l > level {
+			level = l
+		}
+	}
+	return MinBrightness + (1-MinBrightness)*float64(level)/MaxLightLevel
+}
+
+// neighborOffsets 四邻方向
+var neighborOffsets = []GridPos{{0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+
+// lightCost 光线进入指定格子的衰减
+func (le *LightEngine) lightCost(x, y int) int {
+	if le.world.Solid(x, y) {
+		return SolidLightCost
+	}
+	return AirLightCost
+}
+
+// gridRect 格子坐标矩形（包含边界）
+type gridRect struct {
+	minX, minY, maxX, maxY int
+}
+
+// expand 向四周各扩展n格
+func (r gridRect) expand(n int) gridRect {
+	return gridRect{r.minX - n, r.minY - n, r.maxX + n, r.maxY + n}
+}
+
+// contains 判断格子是否在矩形内
+func (r gridRect) contains(p GridPos) bool {
+	return p.X >= r.minX && p.X <= r.maxX && p.Y >= r.minY && p.Y <= r.maxY
+}
+
+// Update 在一批格子的实心状态或发光强度变化后增量更新光照
+//
+// 只有距离变化格子（包括列顶变化导致直射状态改变的格子）不超过
+// MaxLightLevel的格子会受影响，因此只需在这个范围内重新计算。
+// 返回光照可能改变的区域，没有变化时返回空矩形。
+func (le *LightEngine) Update(changed []GridPos) gridRect {
+	if len(changed) == 0 {
+		return gridRect{0, 0, -1, -1}
+	}
+
+	// 1. 按列收集变化，并更新列顶
+	columns := make(map[int][2]int) // 列 -> 变化的最小、最大Y
+	for _, p := range changed {
+		r, ok := columns[p.X]
+		if !ok {
+			r = [2]int{p.Y, p.Y}
+		}
+		r[0] = min(r[0], p.Y)
+		r[1] = max(r[1], p.Y)
+		columns[p.X] = r
+	}
+
+	area := gridRect{changed[0].X, changed[0].Y, changed[0].X, changed[0].Y}
+	extend := func(x, y int) {
+		area.minX, area.maxX = min(area.minX, x), max(area.maxX, x)
+		area.minY, area.maxY = min(area.minY, y), max(area.maxY, y)
+	}
+	for x, r := range columns {
+		extend(x, r[0])
+		extend(x, r[1])
+
+		oldTop, hadTop := le.tops[x]
+		from := r[0]
+		if hadTop {
+			from = min(from, oldTop)
+		}
+		to := r[1]
+		if hadTop {
+			to = max(to, oldTop)
+		}
+		newTop, hasTop := 0, false
+		for y := from; y <= to+LightShaftLimit; y++ {
+			if le.world.Solid(x, y) {
+				newTop, hasTop = y, true
+				break
+			}
+		}
+		if hasTop {
+			le.tops[x] = newTop
+		} else {
+			delete(le.tops, x)
+		}
+
+		// 直射状态改变的竖直区间也属于变化范围
+		switch {
+		case hadTop && hasTop:
+			extend(x, min(oldTop, newTop))
+			extend(x, max(oldTop, newTop))
+		case hadTop:
+			extend(x, oldTop+LightShaftLimit)
+		case hasTop:
+			extend(x, newTop+LightShaftLimit)
+		}
+	}
+
+	// 2. 在变化范围外扩MaxLightLevel的区域内重新计算
+	area = area.expand(MaxLightLevel)
+	le.recompute(area)
+	return area
+}
+
+// recompute 清空矩形内的光照，并以矩形内的光源和矩形外的既有光照为起点重新扩散
+func (le *LightEngine) recompute(area gridRect) {
+	for y := area.minY; y <= area.maxY; y++ {
+		for x := area.minX; x <= area.maxX; x++ {
+			p := GridPos{x, y}
+			delete(le.sky, p)
+			delete(le.block, p)
+		}
+	}
+
+	var skyQueue, blockQueue lightQueue
+	for y := area.minY; y <= area.maxY; y++ {
+		for x := area.minX; x <= area.maxX; x++ {
+			p := GridPos{x, y}
+
+			// 光源：阳光直射格子和发光方块
+			if le.isDirectSun(x, y) {
+				skyQueue.push(p, MaxLightLevel)
+			}
+			if e := le.world.Emission(x, y); e > 0 {
+				le.block[p] = uint8(min(e, MaxLightLevel))
+				blockQueue.push(p, int(le.block[p]))
+			}
+
+			// 边界：矩形外相邻格子的既有光照向内扩散
+			if x == area.minX || x == area.maxX || y == area.minY || y == area.maxY {
+				for _, d := range neighborOffsets {
+					q := GridPos{x + d.X, y + d.Y}
+					if area.contains(q) {
+						continue
+					}
+					cost := le.lightCost(x, y)
+					if l := le.SkyLight(q.X, q.Y) - cost; l > 0 {
+						le.raise(le.sky, p, l, &skyQueue, true)
+					}
+					if l := le.BlockLight(q.X, q.Y) - cost; l > 0 {
+						le.raise(le.block, p, l, &blockQueue, false)
+					}
+				}
+			}
+		}
+	}
+
+	le.flood(le.sky, &skyQueue, area, true)
+	le.flood(le.block, &blockQueue, area, false)
+}
+
+// raise 若新光照更亮则写入并加入队列
+func (le *LightEngine) raise(levels map[GridPos]uint8, p GridPos, level int, queue *lightQueue, sky bool) {
+	if sky && le.isDirectSun(p.X, p.Y) {
+		return
+	}
+	if level <= int(levels[p]) {
+		return
+	}
+	levels[p] = uint8(level)
+	queue.push(p, level)
+}
+
+// flood 按光照从高到低的顺序在矩形内扩散
+func (le *LightEngine) flood(levels map[GridPos]uint8, queue *lightQueue, area gridRect, sky bool) {
+	for {
+		p, level, ok := queue.pop()
+		if !ok {
+			return
+		}
+		current := int(levels[p])
+		if sky && le.isDirectSun(p.X, p.Y) {
+			current = MaxLightLevel
+		}
+		if level < current {
+			continue // 已被更亮的光覆盖
+		}
+		for _, d := range neighborOffsets {
+			q := GridPos{p.X + d.X, p.Y + d.Y}
+			if !area.contains(q) {
+				continue
+			}
+			if l := level - le.lightCost(q.X, q.Y); l > 0 {
+				le.raise(levels, q, l, queue, sky)
+			}
+		}
+	}
+}
+
+// lightQueue 按光照等级分桶的队列，总是先取出最亮的格子
+type lightQueue struct {
+	buckets [MaxLightLevel + 1][]GridPos
+	top     int
+}
+
+// push 加入队列
+func (q *lightQueue) push(p GridPos, level int) {
+	q.buckets[level] = append(q.buckets[level], p)
+	q.top = max(q.top, level)
+}
+
+// pop 取出最亮的格子
+func (q *lightQueue) pop() (GridPos, int, bool) {
+	for ; q.top > 0; q.top-- {
+		bucket := q.buckets[q.top]
+		if n := len(bucket); n > 0 {
+			p := bucket[n-1]
+			q.buckets[q.top] = bucket[:n-1]
+			return p, q.top, true
+		}
+	}
+	return GridPos{}, 0, false
+}
+
+// shadeColor 按亮度调暗颜色
+func shadeColor(c color.RGBA, brightness float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * brightness),
+		G: uint8(float64(c.G) * brightness),
+		B: uint8(float64(c.B) * brightness),
+		A: c.A,
+	}
+}
+
+// lightLevelAt 返回格子在当前时间的综合光照等级
+func (g *Game) lightLevelAt(p GridPos) int {
+	if g.light == nil {
+		return MaxLightLevel
+	}
+	return g.light.Light(p.X, p.Y, g.clock.Daylight())
+}
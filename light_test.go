@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// newTestLight 根据手工绘制的地图建立光照：'#'为石头，'L'为岩浆，其余为空气
+func newTestLight(rows []string) (blockGrid, *LightEngine) {
+	grid := make(blockGrid)
+	var cells []GridPos
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case '#':
+				grid[GridPos{x, y}] = ItemTypeStone
+			case 'L':
+				grid[GridPos{x, y}] = ItemTypeLava
+			default:
+				continue
+			}
+			cells = append(cells, GridPos{x, y})
+		}
+	}
+	le := NewLightEngine(grid)
+	le.Update(cells)
+	return grid, le
+}
+
+func checkLight(t *testing.T, name string, got func(x, y int) int, want map[GridPos]int) {
+	t.Helper()
+	for p, w := range want {
+		if g := got(p.X, p.Y); g != w {
+			t.Errorf("%s at %v = %d, want %d", name, p, g, w)
+		}
+	}
+}
+
+func TestSkyLightPropagation(t *testing.T) {
+	_, le := newTestLight([]string{
+		"......",
+		"..###.",
+		"......",
+		"######",
+	})
+	checkLight(t, "sky", le.SkyLight, map[GridPos]int{
+		{0, 0}: 15, // 直射
+		{0, 2}: 15, // 直射到地面
+		{2, 2}: 14, // 屋檐下向内一格
+		{3, 2}: 13, // 屋檐正中
+		{4, 2}: 14,
+		{3, 1}: 12, // 屋顶方块
+		{0, 3}: 12, // 地面方块
+		{3, 3}: 10, // 屋檐下的地面方块
+	})
+}
+
+func TestBlockLightPropagation(t *testing.T) {
+	_, le := newTestLight([]string{
+		"......",
+		"..L...",
+		"......",
+		"######",
+	})
+	checkLight(t, "block", le.BlockLight, map[GridPos]int{
+		{2, 1}: 14, // 光源
+		{3, 1}: 13,
+		{5, 1}: 11,
+		{2, 2}: 13,
+		{2, 3}: 10, // 进入石头衰减更多
+		{5, 3}: 7,
+	})
+}
+
+func TestCaveIsDark(t *testing.T) {
+	_, le := newTestLight([]string{
+		"..................................",
+		"##################################",
+		"##################################",
+		"##################################",
+		"##################################",
+		"##################################",
+		"##########..........##############",
+		"##################################",
+	})
+	if got := le.SkyLight(15, 6); got != 0 {
+		t.Fatalf("sky light in sealed cave = %d, want 0", got)
+	}
+}
+
+func TestLightRemoval(t *testing.T) {
+	grid, le := newTestLight([]string{
+		"...#...",
+		"..#L#..",
+		"..###..",
+		"#######",
+	})
+	if got := le.BlockLight(3, 1); got != 14 {
+		t.Fatalf("lava light = %d, want 14", got)
+	}
+
+	// 移除岩浆后方块光全部消失
+	delete(grid, GridPos{3, 1})
+	le.Update([]GridPos{{3, 1}})
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 7; x++ {
+			if got := le.BlockLight(x, y); got != 0 {
+				t.Errorf("block light at (%d,%d) = %d after removing lava, want 0", x, y, got)
+			}
+		}
+	}
+
+	// 岩浆原位置被屋顶遮挡，移除屋顶后阳光直射
+	if got := le.SkyLight(3, 1); got == MaxLightLevel {
+		t.Fatalf("covered cell should not be sunlit")
+	}
+	delete(grid, GridPos{3, 0})
+	le.Update([]GridPos{{3, 0}})
+	if got := le.SkyLight(3, 1); got != MaxLightLevel {
+		t.Fatalf("sky light after removing roof = %d, want %d", got, MaxLightLevel)
+	}
+
+	// 重新封顶后只剩透过方块的天空光
+	grid[GridPos{3, 0}] = ItemTypeStone
+	le.Update([]GridPos{{3, 0}})
+	if got := le.SkyLight(3, 1); got != 11 {
+		t.Fatalf("sky light after closing roof = %d, want 11", got)
+	}
+}
+
+func TestIncrementalMatchesFullRecompute(t *testing.T) {
+	rows := []string{
+		"..............................",
+		"..............................",
+		"......###.........####........",
+		"..............................",
+		"##############################",
+		"##############################",
+		"#####.......######.......#####",
+		"#####.......######.......#####",
+		"##############################",
+		"##############################",
+	}
+	grid, le := newTestLight(rows)
+	w, h := len(rows[0]), len(rows)
+
+	rng := rand.New(rand.NewSource(1))
+	types := []ItemType{ItemTypeStone, ItemTypeLava, ItemTypeDirt}
+	for i := 0; i < 200; i++ {
+		p := GridPos{rng.Intn(w), rng.Intn(h)}
+		if grid.Solid(p.X, p.Y) {
+			delete(grid, p)
+		} else {
+			grid[p] = types[rng.Intn(len(types))]
+		}
+		le.Update([]GridPos{p})
+	}
+
+	// 用最终网格从头计算一次作为对照
+	var cells []GridPos
+	for p := range grid {
+		cells = append(cells, p)
+	}
+	full := NewLightEngine(grid)
+	full.Update(cells)
+
+	for y := -MaxLightLevel; y < h+MaxLightLevel; y++ {
+		for x := -MaxLightLevel; x < w+MaxLightLevel; x++ {
+			if a, b := le.SkyLight(x, y), full.SkyLight(x, y); a != b {
+				t.Errorf("sky light at (%d,%d): incremental %d, full %d", x, y, a, b)
+			}
+			if a, b := le.BlockLight(x, y), full.BlockLight(x, y); a != b {
+				t.Errorf("block light at (%d,%d): incremental %d, full %d", x, y, a, b)
+			}
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package main
 import (
+	"flag"
 	"fmt"
 	"image/color"
 	"log"
 	"math"
 	"math/rand"
+	"time"
+
+	"2d.go/region"
+	"2d.go/world"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
@@ -27,11 +32,12 @@ const (
 	PlayerMaxFall = 10.0
 	
 	// 地形生成常量
-	BlockSize     = 50
-	ChunkSize     = 10              // 每个区块的方块数
+	BlockSize     = world.BlockSize
+	ChunkSize     = world.ChunkSize // 每个区块的方块数
 	ChunkWorldSize = BlockSize * ChunkSize // 每个区块的世界尺寸
 	GenerationDistance = 3          // 生成距离（以区块为单位）
 	UndergroundDepth   = 10         // 地下深度
+	TerrainSeed        = world.TerrainSeed // 地形生成种子
 	
 	// 游戏模式枚举
 	GameModeCreative = iota // 创造模式
@@ -41,99 +47,48 @@ const (
 	MaxPlaceDistance = 5 * BlockSize
 )
 
-// ItemType 定义游戏中可用的方块类型
-type ItemType int
+// 方块类型和地形类型定义在world包中，与区块生成共用
+type (
+	ItemType    = world.ItemType
+	TerrainType = world.TerrainType
+)
 
-// 方块类型常量定义
+// 方块类型常量
 const (
-	ItemTypeGrass ItemType = iota // 草地
-	ItemTypeDirt                  // 泥土
-	ItemTypeStone                 // 石头
-	ItemTypeSand                  // 沙子
-	ItemTypeWood                  // 木头
-	ItemTypeWater                 // 水
-	ItemTypeLava                  // 岩浆
-	ItemTypeSnow                  // 雪
+	ItemTypeGrass      = world.ItemTypeGrass
+	ItemTypeDirt       = world.ItemTypeDirt
+	ItemTypeStone      = world.ItemTypeStone
+	ItemTypeSand       = world.ItemTypeSand
+	ItemTypeWood       = world.ItemTypeWood
+	ItemTypeWater      = world.ItemTypeWater
+	ItemTypeLava       = world.ItemTypeLava
+	ItemTypeSnow       = world.ItemTypeSnow
+	ItemTypeCoalOre    = world.ItemTypeCoalOre
+	ItemTypeIronOre    = world.ItemTypeIronOre
+	ItemTypeGoldOre    = world.ItemTypeGoldOre
+	ItemTypeDiamondOre = world.ItemTypeDiamondOre
 )
 
-// Item 定义游戏中可用的物品结构
-type Item struct {
-	Type        ItemType
-	Name        string
-	Color       color.RGBA
-	Description string
-}
-
-// 全局物品注册表，包含所有可用方块类型及其属性
-var itemRegistry = map[ItemType]Item{
-	ItemTypeGrass: {
-		Type:        ItemTypeGrass,
-		Name:        "Grass",
-		Color:       color.RGBA{50, 180, 50, 255},
-		Description: "Green grass block",
-	},
-	ItemTypeDirt: {
-		Type:        ItemTypeDirt,
-		Name:        "Dirt",
-		Color:       color.RGBA{150, 100, 50, 255},
-		Description: "Brown dirt block",
-	},
-	ItemTypeStone: {
-		Type:        ItemTypeStone,
-		Name:        "Stone",
-		Color:       color.RGBA{100, 100, 100, 255},
-		Description: "Gray stone block",
-	},
-	ItemTypeSand: {
-		Type:        ItemTypeSand,
-		Name:        "Sand",
-		Color:       color.RGBA{255, 220, 100, 255},
-		Description: "Golden sand block",
-	},
-	ItemTypeWood: {
-		Type:        ItemTypeWood,
-		Name:        "Wood",
-		Color:       color.RGBA{150, 100, 50, 255},
-		Description: "Brown wood block",
-	},
-	ItemTypeWater: {
-		Type:        ItemTypeWater,
-		Name:        "Water",
-		Color:       color.RGBA{50, 100, 255, 200},
-		Description: "Blue water block",
-	},
-	ItemTypeLava: {
-		Type:        ItemTypeLava,
-		Name:        "Lava",
-		Color:       color.RGBA{255, 100, 0, 200},
-		Description: "Hot lava block",
-	},
-	ItemTypeSnow: {
-		Type:        ItemTypeSnow,
-		Name:        "Snow",
-		Color:       color.RGBA{230, 230, 255, 255},
-		Description: "White snow block",
-	},
-}
-
-// TerrainType 定义地形类型枚举
-type TerrainType int
-
-// 地形类型常量定义
+// 地形类型常量
 const (
-	TerrainTypePlains TerrainType = iota // 平原
-	TerrainTypeHills                     // 丘陵
-	TerrainTypeMountains                 // 山脉
-	TerrainTypeDesert                    // 沙漠
-	TerrainTypeForest                    // 森林
-	TerrainTypeSnowyPlains               // 雪原
-	TerrainTypeSwamp                     // 沼泽
-	TerrainTypeJungle                    // 丛林
-	TerrainTypeTaiga                     // 针叶林
-	TerrainTypeSavanna                   // 热带草原
-	TerrainTypeCanyon                    // 峡谷
+	TerrainTypePlains      = world.TerrainTypePlains
+	TerrainTypeHills       = world.TerrainTypeHills
+	TerrainTypeMountains   = world.TerrainTypeMountains
+	TerrainTypeDesert      = world.TerrainTypeDesert
+	TerrainTypeForest      = world.TerrainTypeForest
+	TerrainTypeSnowyPlains = world.TerrainTypeSnowyPlains
+	TerrainTypeSwamp       = world.TerrainTypeSwamp
+	TerrainTypeJungle      = world.TerrainTypeJungle
+	TerrainTypeTaiga       = world.TerrainTypeTaiga
+	TerrainTypeSavanna     = world.TerrainTypeSavanna
+	TerrainTypeCanyon      = world.TerrainTypeCanyon
+	TerrainTypeOcean       = world.TerrainTypeOcean
+	TerrainTypeBeach       = world.TerrainTypeBeach
 )
 
+// 全局物品注册表，包含所有可用方块类型及其属性
+var itemRegistry = world.ItemRegistry
+
 // Block 定义游戏中的方块结构
 type Block struct {
 	X, Y, W, H float64
@@ -146,20 +101,88 @@ type Chunk struct {
 	Blocks []Block
 }
 
+// chunkFromWorld 将生成的区块转换为游戏的区块
+func chunkFromWorld(c *world.Chunk) *Chunk {
+	chunk := &Chunk{X: c.X, Y: c.Y, Blocks: make([]Block, len(c.Blocks))}
+	for i, b := range c.Blocks {
+		chunk.Blocks[i] = Block(b)
+	}
+	return chunk
+}
+
 // Game 定义游戏主结构，包含所有游戏状态
 type Game struct {
-	playerX, playerY   float64 // 玩家在世界中的位置
-	playerVelocityY    float64 // 玩家垂直速度
-	playerOnGround     bool    // 玩家是否在地面上
+	// 实体管理（玩家也是实体）
+	entities *EntityManager
+	player   *Entity
+	
+	// 玩家拾取的物品数量
+	inventory map[ItemType]int
+	
+	// 生物生成与寻路
+	rng              *rand.Rand // 游戏逻辑使用的随机数
+	mobSpawnTimer    int        // 距下一次生物生成尝试的帧数
+	
+	// 方块网格（按格子索引方块，供寻路和光照使用）与光照引擎
+	grid  blockGrid
+	light *LightEngine
+	
+	// 地形生成器（用于运行时查询地形类型）
+	terrainGen *world.TerrainGenerator
+	
+	// 区块生成器及其世界类型（见world.NewChunkGenerator）
+	worldGen     world.ChunkGenerator
+	worldType    string
+	worldTypeSet bool // 世界类型由命令行显式指定
+	
+	// 昼夜循环
+	clock        *WorldClock
+	dayLength    int64 // 一天的帧数（0表示使用默认值）
+	dayLengthSet bool  // 一天的帧数由命令行显式指定
+	
+	// 存档路径
+	savePath string
+	
+	// 方块贴图集（加载失败时为nil，使用注册表颜色绘制）
+	atlas *BlockAtlas
+	
+	// 方块层渲染器（按区块缓存离屏图像）
+	renderer *BlockRenderer
 
-	// 实际摄像头偏移（用于绘制）
-	cameraX, cameraY float64
+	// 跟随玩家的摄像机
+	camera *Camera
+	
+	// 世界地图（小地图与全屏地图）和出生点（世界坐标）
+	worldMap       *WorldMap
+	spawnX, spawnY float64
+	
+	// 视差背景与调试网格开关
+	parallax Parallax
+	showGrid bool
+	
+	// 调试面板与控制台
+	debug   DebugOverlay
+	console *Console
+	
+	// 方块编辑的撤销/重做历史
+	history      *EditHistory
+	historyDepth int // 保留的撤销步数（0表示使用默认值）
+	
+	// 屏幕尺寸（设备像素，随窗口大小变化）与高DPI缩放倍数
+	screenWidth, screenHeight int
+	uiScale                   float64
+	
+	// 界面层（逻辑像素），按uiScale放大后绘制到屏幕
+	hud *ebiten.Image
 	
 	// 地面方块列表
 	blocks []Block
 	
-	// 区块管理
+	// 区块管理：已加载的区块、按玩家位置生成和卸载区块的区域，
+	// 以及玩家对格子的修改（区块卸载后重新生成时仍然有效）
 	chunks map[string]*Chunk
+	region *region.Region
+	edits  map[GridPos]cellEdit
 	
 	// 世界边界（用于地下世界）
 	worldMinX, worldMaxX float64
@@ -175,6 +198,16 @@ type Game struct {
 	selectionEndX      float64 // 框选结束点X坐标
 	selectionEndY      float64 // 框选结束点Y坐标
 	
+	// 框选使用的建造工具及其预览
+	buildTool   BuildTool
+	toolPreview toolPreview
+	
+	// 选择工具记录的选区与剪贴板
+	selection    gridRect
+	hasSelection bool
+	clipboard    *Clipboard
+	pasting      bool // 是否处于粘贴模式
+	
 	// 选中块相关字段
 	selectedBlockX     float64 // 选中方块的X坐标
 	selectedBlockY     float64 // 选中方块的Y坐标
@@ -190,9 +223,7 @@ type Game struct {
 // getMouseWorldPosition 获取鼠标在世界坐标系中的位置
 func (g *Game) getMouseWorldPosition() (float64, float64) {
 	x, y := ebiten.CursorPosition()
-	worldX := float64(x) - g.cameraX
-	worldY := float64(y) - g.cameraY
-	return worldX, worldY
+	return g.screenToWorld(float64(x), float64(y))
 }
 
 // getBlockCoordinate 将世界坐标转换为方块坐标
@@ -200,15 +231,10 @@ func getBlockCoordinate(worldCoord float64) float64 {
 	return math.Floor(worldCoord/BlockSize) * BlockSize
 }
 
-// isBlockAt 检查指定位置是否有方块
+// isBlockAt 检查指定位置所在的格子是否有方块
 func (g *Game) isBlockAt(x, y float64) bool {
-	for _, block := range g.blocks {
-		// 精确比较方块的X和Y坐标，确保匹配指定位置
-		if block.X == x && block.Y == y {
-			return true
-		}
-	}
-	return false
+	_, ok := g.pathGrid()[toGridPos(x, y)]
+	return ok
 }
 
 // hasLineOfSight 检查指定位置和玩家之间是否有视线（用于创造模式）
@@ -306,11 +332,14 @@ func (g *Game) addBlock(x, y float64) {
 		switch g.gameMode {
 		case GameModeCreative:
 			// 创造模式：可以隔着方块放置，无距离限制
-			g.blocks = append(g.blocks, Block{x, y, BlockSize, BlockSize, blockType})
+			block := Block{x, y, BlockSize, BlockSize, blockType}
+			g.blocks = append(g.blocks, block)
+			g.editBlocks([]Block{block}, nil)
+			g.history.Record(block, true)
 		case GameModeSurvival:
 			// 生存模式：必须在距离范围内且与现有方块相邻
-			playerCenterX := g.playerX + PlayerSize/2
-			playerCenterY := g.playerY + PlayerSize/2
+			playerCenterX := g.player.CenterX()
+			playerCenterY := g.player.CenterY()
 			blockCenterX := x + BlockSize/2
 			blockCenterY := y + BlockSize/2
 			
@@ -321,14 +350,17 @@ func (g *Game) addBlock(x, y float64) {
 			// 1. 放置距离不能超过最大距离
 			// 2. 必须与现有方块相邻
 			if dist <= MaxPlaceDistance && g.isBlockAdjacent(x, y) {
-				g.blocks = append(g.blocks, Block{x, y, BlockSize, BlockSize, blockType})
+				block := Block{x, y, BlockSize, BlockSize, blockType}
+				g.blocks = append(g.blocks, block)
+				g.editBlocks([]Block{block}, nil)
+				g.history.Record(block, true)
 			}
 		}
 	}
 }
 
-// removeBlock 移除指定位置的方块
-func (g *Game) removeBlock(x, y float64) {
+// removeBlock 移除指定位置的方块，返回被移除的方块及是否移除成功
+func (g *Game) removeBlock(x, y float64) (Block, bool) {
 	for i, block := range g.blocks {
 		if block.X == x && block.Y == y {
 			// 从切片中移除该方块并正确初始化新切片
@@ -336,464 +368,67 @@ func (g *Game) removeBlock(x, y float64) {
 			newBlocks = append(newBlocks, g.blocks[:i]...)
 			newBlocks = append(newBlocks, g.blocks[i+1:]...)
 			g.blocks = newBlocks
-			break
+			g.editBlocks(nil, []Block{block})
+			g.history.Record(block, false)
+			return block, true
 		}
 	}
+	return Block{}, false
 }
 
-// chunkKey 获取区块键值
-func chunkKey(x, y int) string {
-	return fmt.Sprintf("%d,%d", x, y)
-}
-
-// PerlinNoise 生成Perlin噪声值
-type PerlinNoise struct {
-	perm [512]int
-}
-
-// NewPerlinNoise 创建新的Perlin噪声生成器
-func NewPerlinNoise(seed int64) *PerlinNoise {
-	p := &PerlinNoise{}
-	rand.Seed(seed)
-	for i := range p.perm {
-		p.perm[i] = i
+// onBlocksChanged 方块增删后同步方块网格，增量更新光照并使渲染缓存失效
+func (g *Game) onBlocksChanged(added, removed []Block) {
+	grid := g.pathGrid()
+	var changed []GridPos
+	for _, block := range removed {
+		grid.remove(block)
+		changed = append(changed, blockCells(block)...)
 	}
-	rand.Shuffle(len(p.perm), func(i, j int) {
-		p.perm[i], p.perm[j] = p.perm[j], p.perm[i]
-	})
-	return p
-}
-
-// Noise2D 生成2D Perlin噪声
-func (p *PerlinNoise) Noise2D(x, y float64) float64 {
-	X := int(math.Floor(x)) & 255
-	Y := int(math.Floor(y)) & 255
-	
-	x -= math.Floor(x)
-	y -= math.Floor(y)
-	
-	u := p.fade(x)
-	v := p.fade(y)
-	
-	A := p.perm[X] + Y
-	AA := p.perm[A & 511]
-	AB := p.perm[(A+1) & 511]
-	B := p.perm[(X+1) & 255] + Y
-	BA := p.perm[B & 511]
-	BB := p.perm[(B+1) & 511]
-	
-	return p.lerp(v, 
-		p.lerp(u, p.grad(p.perm[AA & 511], x, y), 
-			p.grad(p.perm[BA & 511], x-1, y)), 
-		p.lerp(u, p.grad(p.perm[AB & 511], x, y-1), 
-			p.grad(p.perm[BB & 511], x-1, y-1)))
-}
-
-// fade 淡化函数
-func (p *PerlinNoise) fade(t float64) float64 {
-	return t * t * t * (t*(t*6-15) + 10)
-}
-
-// lerp 线性插值
-func (p *PerlinNoise) lerp(t, a, b float64) float64 {
-	return a + t*(b-a)
-}
-
-// grad 梯度函数
-func (p *PerlinNoise) grad(hash int, x, y float64) float64 {
-	h := hash & 15
-	u := x
-	v := y
-	if h < 8 {
-		u = y
-		v = x
-	}
-	if h&4 != 0 {
-		u = -u
-	}
-	if h&2 != 0 {
-		v = -v
+	for _, block := range added {
+		grid.add(block)
+		changed = append(changed, blockCells(block)...)
 	}
-	return u + v
-}
-
-// OctaveNoise 生成多层噪声（分形噪声）
-func (p *PerlinNoise) OctaveNoise(octaves int, persistence, scale, x, y float64) float64 {
-	var total float64
-	var frequency, amplitude float64
-	maxAmplitude := 0.0
-	
-	for i := 0; i < octaves; i++ {
-		frequency = math.Pow(2, float64(i))
-		amplitude = math.Pow(persistence, float64(i))
-		
-		total += p.Noise2D(x*scale*frequency, y*scale*frequency) * amplitude
-		maxAmplitude += amplitude
+	if g.worldMap != nil {
+		// 卸载的区块保留原来的地图贴图
+		g.worldMap.MarkDirty(g.loadedCells(changed))
 	}
-	
-	return total / maxAmplitude
-}
-
-// TerrainGenerator 地形生成器
-type TerrainGenerator struct {
-	noise      *PerlinNoise
-	seed       int64
-}
-
-// NewTerrainGenerator 创建新的地形生成器
-func NewTerrainGenerator(seed int64) *TerrainGenerator {
-	return &TerrainGenerator{
-		noise: NewPerlinNoise(seed),
-		seed:  seed,
-	}
-}
-
-// getHeight 获取指定位置的高度
-func (tg *TerrainGenerator) getHeight(x int) int {
-	// 基础地形高度，调整垂直偏移使地面更接近玩家出生点
-	baseHeight := tg.noise.OctaveNoise(4, 0.5, 0.01, float64(x), 0) * 20
-	
-	// 添加细节变化
-	detail := tg.noise.OctaveNoise(3, 0.6, 0.05, float64(x), 100) * 5
-	
-	// 添加山脉
-	mountains := 0.0
-	if val := tg.noise.OctaveNoise(2, 0.7, 0.005, float64(x), 200); val > 0.6 {
-		mountains = val * 20
-	}
-	
-	// 调整整体高度偏移，使地面更适合玩家出生
-	return int(baseHeight + detail + mountains) - 5
-}
-
-// getTerrainType 获取指定位置的地形类型
-func (tg *TerrainGenerator) getTerrainType(x int) TerrainType {
-	// 使用不同的噪声尺度获取地形类型
-	continental := tg.noise.OctaveNoise(3, 0.5, 0.005, float64(x), 300)
-	
-	switch {
-	case continental < -0.4:
-		return TerrainTypeDesert
-	case continental < -0.2:
-		return TerrainTypeSavanna
-	case continental < 0:
-		return TerrainTypePlains
-	case continental < 0.2:
-		return TerrainTypeForest
-	case continental < 0.4:
-		return TerrainTypeHills
-	case continental < 0.6:
-		return TerrainTypeMountains
-	default:
-		return TerrainTypeSnowyPlains
-	}
-}
-
-// getBlockType 获取指定位置和高度的方块类型
-func (tg *TerrainGenerator) getBlockType(x, y, height int, terrainType TerrainType) ItemType {
-	depth := height - y
-	
-	switch terrainType {
-	case TerrainTypeDesert:
-		if depth < 3 {
-			return ItemTypeSand
-		}
-		return ItemTypeStone
-		
-	case TerrainTypeSavanna:
-		if depth == 0 {
-			return ItemTypeGrass
-		} else if depth < 4 {
-			return ItemTypeDirt
-		}
-		return ItemTypeStone
-		
-	case TerrainTypePlains:
-		if depth == 0 {
-			return ItemTypeGrass
-		} else if depth < 3 {
-			return ItemTypeDirt
-		}
-		return ItemTypeStone
-		
-	case TerrainTypeForest:
-		if depth == 0 {
-			return ItemTypeGrass
-		} else if depth < 3 {
-			return ItemTypeDirt
-		}
-		return ItemTypeStone
-		
-	case TerrainTypeHills:
-		if depth == 0 {
-			return ItemTypeGrass
-		} else if depth < 5 {
-			return ItemTypeDirt
-		}
-		return ItemTypeStone
-		
-	case TerrainTypeMountains:
-		if depth == 0 {
-			if y > 5 {
-				return ItemTypeSnow
-			}
-			return ItemTypeStone
-		} else if depth < 3 {
-			return ItemTypeStone
-		}
-		return ItemTypeStone
-		
-	case TerrainTypeSnowyPlains:
-		if depth == 0 {
-			return ItemTypeSnow
-		} else if depth < 3 {
-			return ItemTypeDirt
-		}
-		return ItemTypeStone
-		
-	default:
-		if depth == 0 {
-			return ItemTypeGrass
-		} else if depth < 3 {
-			return ItemTypeDirt
+	if g.light != nil {
+		// 光照变化区域已包含相邻格子（草地边缘贴图随邻居变化）
+		area := g.light.Update(changed)
+		if g.renderer != nil {
+			g.renderer.Invalidate(area)
 		}
-		return ItemTypeStone
-	}
-}
-
-
-// hasCave 判断指定位置是否有洞穴
-func (tg *TerrainGenerator) hasCave(x, y int) bool {
-	// 使用噪声生成洞穴
-	caveNoise := tg.noise.OctaveNoise(4, 0.6, 0.1, float64(x), float64(y)+1000)
-	return caveNoise > 0.7 && y > -5
-}
-
-// hasTree 判断指定位置是否有树
-func (tg *TerrainGenerator) hasTree(x, height int, terrainType TerrainType) bool {
-	treeNoise := tg.noise.OctaveNoise(2, 0.5, 0.05, float64(x), 2000)
-	
-	switch terrainType {
-	case TerrainTypeForest:
-		return treeNoise > 0.6 && height >= 0
-	case TerrainTypeJungle:
-		return treeNoise > 0.5 && height >= 0
-	case TerrainTypeTaiga:
-		return treeNoise > 0.55 && height >= -2
-	default:
-		return false
 	}
 }
 
-// getTreeHeight 获取树的高度
-func (tg *TerrainGenerator) getTreeHeight(x int, terrainType TerrainType) int {
-	treeNoise := tg.noise.OctaveNoise(2, 0.5, 0.1, float64(x), 3000)
-	
-	switch terrainType {
-	case TerrainTypeForest:
-		return 4 + int(treeNoise*4)
-	case TerrainTypeJungle:
-		return 6 + int(treeNoise*6)
-	case TerrainTypeTaiga:
-		return 5 + int(treeNoise*3)
-	default:
-		return 3 + int(treeNoise*3)
-	}
+// chunkKey 获取区块键值
+func chunkKey(x, y int) string {
+	return fmt.Sprintf("%d,%d", x, y)
 }
 
-// generateChunk 生成地形区块
-func (g *Game) generateChunk(chunkX, chunkY int) *Chunk {
-	chunk := &Chunk{
-		X: chunkX,
-		Y: chunkY,
-	}
-	
-	// 初始化地形生成器
-	terrainGen := NewTerrainGenerator(12345)
-	
-	// 确保在玩家出生点附近不会生成阻挡方块
-	playerChunkX := int(math.Floor(g.playerX / ChunkWorldSize))
-	isNearPlayerSpawn := (chunkX >= playerChunkX-1) && (chunkX <= playerChunkX+1) && chunkY == 0
-	
-	// 只在需要的区域内生成地形
-	playerChunkY := int(math.Floor(g.playerY / ChunkWorldSize))
-	if chunkY > playerChunkY+3 || chunkY < playerChunkY-3 {
-		return chunk
-	}
-	
-	// 为每个X坐标生成地形
-	for x := 0; x < ChunkSize; x++ {
-		worldX := chunkX*ChunkSize + x
-		
-		// 获取地形高度和类型
-		height := terrainGen.getHeight(worldX)
-		terrainType := terrainGen.getTerrainType(worldX)
-		
-		// 计算方块X坐标
-		blockX := float64(worldX * BlockSize)
-		
-		// 在玩家出生点附近确保不会生成阻挡方块
-		if isNearPlayerSpawn {
-			playerSpawnY := -40.0
-			spawnRadius := 3.0 * BlockSize
-			if math.Abs(blockX) <= spawnRadius {
-				blockY := float64(height * BlockSize)
-				playerTop := playerSpawnY
-				playerBottom := playerSpawnY + PlayerSize
-				
-				if blockY < playerBottom && (blockY + BlockSize) > playerTop {
-					continue
-				}
-			}
-		}
-		
-		// 生成地形柱
-		maxDepth := 5
-		switch terrainType {
-		case TerrainTypeMountains:
-			maxDepth = 8
-		case TerrainTypeHills:
-			maxDepth = 6
-		case TerrainTypeDesert:
-			maxDepth = 4
-		}
-		
-		for y := height; y >= height-maxDepth; y-- {
-			blockY := float64(y * BlockSize)
-			
-			// 检查是否在洞穴位置
-			if terrainGen.hasCave(worldX, y) {
-				continue
-			}
-			
-			// 获取方块类型
-			blockType := terrainGen.getBlockType(worldX, y, height, terrainType)
-			
-			// 添加方块到区块
-			chunk.Blocks = append(chunk.Blocks, Block{
-				X:    blockX,
-				Y:    blockY,
-				W:    BlockSize,
-				H:    BlockSize,
-				Type: blockType,
-			})
-		}
-		
-		// 生成树木
-		if terrainGen.hasTree(worldX, height, terrainType) && !(isNearPlayerSpawn && math.Abs(blockX) <= 3*BlockSize) {
-			treeHeight := terrainGen.getTreeHeight(worldX, terrainType)
-			
-			// 生成树干
-			for i := 1; i <= treeHeight; i++ {
-				chunk.Blocks = append(chunk.Blocks, Block{
-					X:    blockX,
-					Y:    float64(height+i) * BlockSize,
-					W:    BlockSize,
-					H:    BlockSize,
-					Type: ItemTypeWood,
-				})
-			}
-			
-			// 生成树叶
-			switch terrainType {
-			case TerrainTypeForest, TerrainTypeJungle:
-				// 简单的树冠
-				chunk.Blocks = append(chunk.Blocks, Block{
-					X:    blockX - BlockSize,
-					Y:    float64(height+treeHeight+1) * BlockSize,
-					W:    BlockSize * 3,
-					H:    BlockSize,
-					Type: ItemTypeGrass,
-				})
-				
-				if terrainType == TerrainTypeJungle && treeHeight > 6 {
-					chunk.Blocks = append(chunk.Blocks, Block{
-						X:    blockX - BlockSize,
-						Y:    float64(height+treeHeight-2) * BlockSize,
-						W:    BlockSize * 3,
-						H:    BlockSize,
-						Type: ItemTypeGrass,
-					})
-				}
-				
-			case TerrainTypeTaiga:
-				// 针叶树冠
-				for i := 0; i < 3; i++ {
-					chunk.Blocks = append(chunk.Blocks, Block{
-						X:    blockX - float64(2-i)*BlockSize/2,
-						Y:    float64(height+treeHeight-1+i) * BlockSize,
-						W:    BlockSize * float64(3-i),
-						H:    BlockSize,
-						Type: ItemTypeGrass,
-					})
-				}
-			}
-		}
-		
-		// 在特定地形生成特殊元素
-		switch terrainType {
-		case TerrainTypeDesert:
-			// 生成仙人掌
-			cactusNoise := terrainGen.noise.OctaveNoise(2, 0.5, 0.1, float64(worldX), 4000)
-			if cactusNoise > 0.7 && height >= 0 && !(isNearPlayerSpawn && math.Abs(blockX) <= 3*BlockSize) {
-				cactusHeight := 1 + int(cactusNoise*3)
-				for i := 1; i <= cactusHeight; i++ {
-					chunk.Blocks = append(chunk.Blocks, Block{
-						X:    blockX,
-						Y:    float64(height+i) * BlockSize,
-						W:    BlockSize,
-						H:    BlockSize,
-						Type: ItemTypeSand,
-					})
-				}
-			}
-			
-		case TerrainTypeSwamp:
-			// 生成水池
-			waterNoise := terrainGen.noise.OctaveNoise(2, 0.5, 0.1, float64(worldX), 5000)
-			if waterNoise > 0.6 && height >= -1 {
-				chunk.Blocks = append(chunk.Blocks, Block{
-					X:    blockX,
-					Y:    float64(height) * BlockSize,
-					W:    BlockSize,
-					H:    BlockSize,
-					Type: ItemTypeWater,
-				})
-			}
+// terrain 返回游戏使用的地形生成器（其他世界类型的查询也使用默认地形）
+func (g *Game) terrain() *world.TerrainGenerator {
+	if g.terrainGen == nil {
+		if tg, ok := g.worldGen.(*world.TerrainGenerator); ok {
+			g.terrainGen = tg
+		} else {
+			g.terrainGen = world.NewTerrainGenerator(TerrainSeed)
 		}
 	}
-	
-	return chunk
+	return g.terrainGen
 }
 
-// loadChunk 加载区块（如果不存在则生成）
-func (g *Game) loadChunk(chunkX, chunkY int) {
-	key := chunkKey(chunkX, chunkY)
-	if _, exists := g.chunks[key]; !exists {
-		g.chunks[key] = g.generateChunk(chunkX, chunkY)
-		g.blocks = append(g.blocks, g.chunks[key].Blocks...)
+// generator 返回世界使用的区块生成器，没有选择世界类型时使用默认地形
+func (g *Game) generator() world.ChunkGenerator {
+	if g.worldGen == nil {
+		g.worldGen = g.terrain()
 	}
+	return g.worldGen
 }
 
-// updateChunks 更新可见区块
-func (g *Game) updateChunks() {
-	// 计算玩家所在区块
-	playerChunkX := int(math.Floor(g.playerX / ChunkWorldSize))
-	playerChunkY := int(math.Floor(g.playerY / ChunkWorldSize))
-	
-	// 增加加载范围以提高性能和视觉效果
-	visibleDistance := 3
-	for x := playerChunkX - visibleDistance; x <= playerChunkX + visibleDistance; x++ {
-		for y := playerChunkY - visibleDistance; y <= playerChunkY + visibleDistance; y++ {
-			g.loadChunk(x, y)
-		}
-	}
-	
-	// 更新世界边界（无限世界不需要限制）
-	// g.worldMinX = float64((playerChunkX - visibleDistance*2) * ChunkWorldSize)
-	// g.worldMaxX = float64((playerChunkX + visibleDistance*2) * ChunkWorldSize)
-	// g.worldMinY = float64((playerChunkY - visibleDistance*2) * ChunkWorldSize)
-	// g.worldMaxY = float64((playerChunkY + visibleDistance*2) * ChunkWorldSize)
+// generateChunk 使用世界的区块生成器生成区块
+func (g *Game) generateChunk(chunkX, chunkY int) *Chunk {
+	return chunkFromWorld(g.generator().GenerateChunk(chunkX, chunkY))
 }
 
 // getItemTypeAtHotbarPosition 获取物品栏中指定位置的物品类型
@@ -834,17 +469,45 @@ func (g *Game) Update() error {
 	// 初始化游戏
 	if g.chunks == nil {
 		g.chunks = make(map[string]*Chunk)
-		// 初始化地形生成器
-		terrainGen := NewTerrainGenerator(12345)
-		// 获取出生点附近的地面高度
-		spawnHeight := terrainGen.getHeight(0)
+		// 选择世界类型（存档中记录的类型优先），获取出生点附近的地面高度
+		save, err := g.openWorldSave()
+		if err != nil {
+			return err
+		}
+		if g.worldType != "" {
+			gen, err := world.NewChunkGenerator(g.worldType, TerrainSeed)
+			if err != nil {
+				return err
+			}
+			g.worldGen = gen
+		}
+		g.setWorldBounds()
+		spawnHeight := g.generator().SurfaceHeight(0)
 		// 初始化玩家位置 - 在地面略高的位置开始
-		g.playerX = 0
-		g.playerY = float64(spawnHeight * BlockSize - PlayerSize - 10) // 确保玩家出生时位于地面之上
+		g.entities = NewEntityManager()
+		g.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+		g.inventory = make(map[ItemType]int)
+		g.player = g.entities.Spawn(newPlayerEntity(0, float64(spawnHeight * BlockSize - PlayerSize - 10))) // 确保玩家出生时位于地面之上
+		g.spawnX, g.spawnY = g.player.CenterX(), g.player.CenterY()
 		g.gameMode = GameModeCreative // 默认为创造模式
 		g.hotbarSelected = 0          // 默认选择第一个物品
 		g.updateCurrentItemType()
 		
+		// 初始化昼夜循环并注册生物相关的事件
+		g.clock = NewWorldClock(g.dayLength)
+		g.clock.Subscribe(ClockEventSunrise, func(day int64) {
+			g.despawnSunlitHostiles()
+		})
+		
+		// 应用存档（如果存在，包括探索过的地图）
+		g.worldMap = NewWorldMap()
+		if save != nil {
+			g.applyWorldSave(save)
+		}
+		
+		// 摄像机从玩家位置开始
+		g.camera = NewCamera(g.player.CenterX(), g.player.CenterY())
+		
 		// 确保玩家出生点周围没有方块
 		// 清理玩家出生点附近的方块，确保玩家不会被卡住
 		safeArea := 3.0 * BlockSize // 3个方块的半径
@@ -857,13 +520,73 @@ func (g *Game) Update() error {
 			
 			// 检查方块是否在玩家安全区域内（水平方向）
 			if !(math.Abs(blockCenterX) <= safeArea &&
-				blockCenterY >= g.playerY-BlockSize && blockCenterY <= g.playerY+PlayerSize+BlockSize) {
+				blockCenterY >= g.player.Y-BlockSize && blockCenterY <= g.player.Y+PlayerSize+BlockSize) {
 				newBlocks = append(newBlocks, block)
 			}
 		}
 		g.blocks = newBlocks
+		
+		// 建立方块网格和光照引擎
+		g.grid = newBlockGrid(g.blocks)
+		g.light = NewLightEngine(g.grid)
+		g.renderer = NewBlockRenderer()
+		g.console = NewConsole(g)
+		g.history = NewEditHistory(g.historyDepth)
+		
+		// 加载方块贴图
+		atlas, err := loadBlockAtlas()
+		if err != nil {
+			log.Printf("failed to load block atlas: %v", err)
+		}
+		g.atlas = atlas
+	}
+	
+	// 保存世界（F5或关闭窗口时）
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) || ebiten.IsWindowBeingClosed() {
+		if g.savePath != "" {
+			if err := g.saveWorld(g.savePath); err != nil {
+				log.Printf("failed to save world: %v", err)
+			}
+		}
+		if ebiten.IsWindowBeingClosed() {
+			return ebiten.Termination
+		}
 	}
 	
+	// 控制台打开时暂停游戏，键盘输入写入命令行
+	if g.console.Open {
+		g.updateConsole()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) {
+		g.console.Open = true
+		return nil
+	}
+	
+	// 全屏地图打开时暂停游戏，只处理地图的平移和缩放
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.toggleMap()
+	}
+	if g.worldMap.Open {
+		g.updateMapView()
+		return nil
+	}
+	
+	// 推进世界时钟
+	g.clock.Advance()
+	
+	// 切换调试网格和调试面板
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		g.showGrid = !g.showGrid
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.debug.Visible = !g.debug.Visible
+	}
+	g.debug.sampleMemory()
+	
+	// 撤销/重做方块编辑
+	g.updateHistory()
+	
 	// 切换游戏模式
 	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
 		if g.gameMode == GameModeCreative {
@@ -913,8 +636,17 @@ func (g *Game) Update() error {
 		g.updateCurrentItemType()
 	}
 	
+	// 切换建造工具
+	g.updateBuildTool()
+	
+	// 缩放摄像机（Ctrl+滚轮时滚轮不再切换物品）
+	wheelUsed := g.updateZoom()
+	
 	// 鼠标滚轮切换物品
 	_, wheelY := ebiten.Wheel()
+	if wheelUsed {
+		wheelY = 0
+	}
 	if wheelY > 0 {
 		// 向上滚动，选择下一个物品
 		g.hotbarSelected = (g.hotbarSelected + 1) % g.getHotbarSize()
@@ -953,38 +685,28 @@ func (g *Game) Update() error {
 		// 更新框选区域
 		g.selectionEndX, g.selectionEndY = g.getMouseWorldPosition()
 	} else if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonMiddle) {
-		// 结束框选并放置方块
+		// 结束框选并执行建造工具
 		if g.selecting {
 			g.selecting = false
-			// 确保起点坐标小于终点坐标
-			minX := math.Min(g.selectionStartX, g.selectionEndX)
-			maxX := math.Max(g.selectionStartX, g.selectionEndX)
-			minY := math.Min(g.selectionStartY, g.selectionEndY)
-			maxY := math.Max(g.selectionStartY, g.selectionEndY)
-			
-			// 在框选区域内放置方块
-			for x := getBlockCoordinate(minX); x <= maxX; x += BlockSize {
-				for y := getBlockCoordinate(minY); y <= maxY; y += BlockSize {
-					// 检查视线（用于创造模式的远程放置）
-					playerCenterX := g.playerX + PlayerSize/2
-					playerCenterY := g.playerY + PlayerSize/2
-					blockX := getBlockCoordinate(x)
-					blockY := getBlockCoordinate(y)
-					if g.hasLineOfSight(blockX, blockY, playerCenterX, playerCenterY) {
-						g.addBlock(blockX, blockY)
-					}
-				}
-			}
+			g.applyTool()
 		}
 	}
 	
+	// 处理复制、剪切和粘贴（粘贴模式下鼠标用于放置剪贴板）
+	pasting := g.updateClipboard()
+	
 	// 处理方块破坏和放置
-	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+	if pasting {
+		// 粘贴模式下不破坏或放置单个方块
+	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
 		// 左键破坏方块
 		mouseWorldX, mouseWorldY := g.getMouseWorldPosition()
 		blockX := getBlockCoordinate(mouseWorldX)
 		blockY := getBlockCoordinate(mouseWorldY)
-		g.removeBlock(blockX, blockY)
+		if block, removed := g.removeBlock(blockX, blockY); removed && g.gameMode == GameModeSurvival {
+			// 生存模式下破坏方块后生成掉落物
+			g.spawnItemDrop(block.Type, block.X, block.Y)
+		}
 	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
 		// 右键放置方块
 		mouseWorldX, mouseWorldY := g.getMouseWorldPosition()
@@ -992,87 +714,20 @@ func (g *Game) Update() error {
 		blockY := getBlockCoordinate(mouseWorldY)
 		
 		// 检查视线（用于创造模式的远程放置）
-		playerCenterX := g.playerX + PlayerSize/2
-		playerCenterY := g.playerY + PlayerSize/2
+		playerCenterX := g.player.CenterX()
+		playerCenterY := g.player.CenterY()
 		if g.hasLineOfSight(blockX, blockY, playerCenterX, playerCenterY) {
 			g.addBlock(blockX, blockY)
 		}
 	}
 	
-	// 1. 处理玩家输入（水平移动）
-	oldX := g.playerX
-	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
-		g.playerX -= PlayerSpeed
-	}
-	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
-		g.playerX += PlayerSpeed
-	}
-	
-	// 1.5 检测水平碰撞
-	playerRect := Block{g.playerX, g.playerY, PlayerSize, PlayerSize, 0} // 玩家视为类型0（无意义）
-	for _, block := range g.blocks {
-		if checkCollision(playerRect, block) {
-			// 从左侧碰撞
-			if oldX <= block.X - PlayerSize {
-				g.playerX = block.X - PlayerSize
-			// 从右侧碰撞
-			} else if oldX >= block.X + block.W {
-				g.playerX = block.X + block.W
-			}
-		}
-	}
-	
-	// 边界检查（支持负数坐标）
-	if g.worldMinX != 0 && g.worldMaxX != 0 { // 确保世界边界已初始化
-		if g.playerX < g.worldMinX {
-			g.playerX = g.worldMinX
-		} else if g.playerX > g.worldMaxX - PlayerSize {
-			g.playerX = g.worldMaxX - PlayerSize
-		}
-	}
+	// 生成生物并更新所有实体（玩家、掉落物、生物等）
+	g.updateMobSpawning()
+	g.entities.Update(g)
 
-	// 2. 处理跳跃
-	if (ebiten.IsKeyPressed(ebiten.KeySpace) || ebiten.IsKeyPressed(ebiten.KeyW)) && g.playerOnGround {
-		g.playerVelocityY = -JumpPower
-		g.playerOnGround = false
-	}
-
-	// 3. 应用重力
-	g.playerVelocityY += Gravity
-	if g.playerVelocityY > PlayerMaxFall {
-		g.playerVelocityY = PlayerMaxFall
-	}
-	
-	// 4. 更新玩家垂直位置
-	oldY := g.playerY
-	g.playerY += g.playerVelocityY
-	
-	// 5. 检测垂直碰撞
-	g.playerOnGround = false
-	playerRect = Block{g.playerX, g.playerY, PlayerSize, PlayerSize, 0} // 玩家视为类型0（无意义）
-	
-	for _, block := range g.blocks {
-		if checkCollision(playerRect, block) {
-			// 从上方落下碰撞
-			if g.playerVelocityY > 0 && oldY <= block.Y - PlayerSize {
-				g.playerY = block.Y - PlayerSize
-				g.playerVelocityY = 0
-				g.playerOnGround = true
-			// 从下方撞击方块
-			} else if g.playerVelocityY < 0 && oldY >= block.Y + block.H {
-				g.playerY = block.Y + block.H
-				g.playerVelocityY = 0
-			}
-		}
-	}
-
-	// 6. 计算摄像机目标位置（玩家中心位置）
-	targetCameraX := -g.playerX + ScreenWidth/2 - PlayerSize/2
-	targetCameraY := -g.playerY + ScreenHeight/2 - PlayerSize/2
-
-	// 7. 平滑移动摄像机到目标位置
-	g.cameraX += (targetCameraX - g.cameraX) * CameraLerp
-	g.cameraY += (targetCameraY - g.cameraY) * CameraLerp
+	// 摄像机跟随玩家，背景随玩家所在的地形类型变化
+	g.updateCamera()
+	g.parallax.Update(g.terrainUnderPlayer())
 
 	return nil
 }
@@ -1088,8 +743,6 @@ func checkCollision(a, b Block) bool {
 // drawHotbar 绘制物品栏
 func (g *Game) drawHotbar(screen *ebiten.Image) {
 	const (
-		hotbarX      = 10
-		hotbarY      = ScreenHeight - 60
 		slotSize     = 40
 		slotSpacing  = 5
 	)
@@ -1098,6 +751,10 @@ func (g *Game) drawHotbar(screen *ebiten.Image) {
 	hotbarSize := g.getHotbarSize()
 	hotbarWidth := hotbarSize*slotSize + (hotbarSize-1)*slotSpacing
 	
+	// 物品栏固定在屏幕底部居中
+	hotbarX := (screen.Bounds().Dx() - hotbarWidth) / 2
+	hotbarY := screen.Bounds().Dy() - 60
+	
 	// 绘制物品栏背景
 	ebitenutil.DrawRect(screen, float64(hotbarX-2), float64(hotbarY-2), 
 		float64(hotbarWidth+4), float64(slotSize+4), 
@@ -1123,6 +780,11 @@ func (g *Game) drawHotbar(screen *ebiten.Image) {
 			ebitenutil.DrawRect(screen, float64(x+5), float64(y+5), float64(slotSize-10), float64(slotSize-10), itemColor)
 		}
 		
+		// 绘制拾取数量
+		if count := g.inventory[itemType]; count > 0 {
+			ebitenutil.DebugPrintAt(screen, fmt.Sprintf("%d", count), x+2, y)
+		}
+		
 		// 绘制数字键提示
 		keyText := fmt.Sprintf("%d", i+1)
 		ebitenutil.DebugPrintAt(screen, keyText, x+slotSize/2-4, y+slotSize+2)
@@ -1131,61 +793,55 @@ func (g *Game) drawHotbar(screen *ebiten.Image) {
 	// 绘制Q键提示
 	ebitenutil.DebugPrintAt(screen, "Q: Cycle", hotbarX, hotbarY+slotSize+15)
 	ebitenutil.DebugPrintAt(screen, "Wheel: Switch", hotbarX+80, hotbarY+slotSize+15)
+	ebitenutil.DebugPrintAt(screen, "Ctrl+Wheel: Zoom", hotbarX+200, hotbarY+slotSize+15)
+	ebitenutil.DebugPrintAt(screen, "Tab: Map", hotbarX+310, hotbarY+slotSize+15)
+	ebitenutil.DebugPrintAt(screen, "G: Grid", hotbarX+370, hotbarY+slotSize+15)
+	ebitenutil.DebugPrintAt(screen, "F3: Debug", hotbarX+430, hotbarY+slotSize+15)
 }
 
 // Draw 渲染游戏画面
 func (g *Game) Draw(screen *ebiten.Image) {
-	// 绘制背景
-	screen.Fill(color.RGBA{30, 30, 60, 255})
+	// 游戏尚未在Update中初始化
+	if g.player == nil {
+		return
+	}
+	
+	// 绘制随时间变化的天空背景和视差背景层
+	g.drawSky(screen)
+	g.drawParallax(screen)
 
 	// 应用摄像头变换
 	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(g.cameraX, g.cameraY)
+	op.GeoM = g.cameraGeoM()
 
-	// 绘制网格（帮助观察移动）
-	gridSize := 50.0
-	for x := math.Floor((g.cameraX - ScreenWidth/2) / gridSize) * gridSize; x <= math.Ceil((g.cameraX + ScreenWidth + ScreenWidth/2) / gridSize) * gridSize; x += gridSize {
-		x0, y0 := op.GeoM.Apply(x, g.cameraY - ScreenHeight)
-		x1, y1 := op.GeoM.Apply(x, g.cameraY + ScreenHeight*2)
-		ebitenutil.DrawLine(screen, x0, y0, x1, y1, color.Gray{100})
-	}
-	
-	for y := math.Floor((g.cameraY - ScreenHeight/2) / gridSize) * gridSize; y <= math.Ceil((g.cameraY + ScreenHeight + ScreenHeight/2) / gridSize) * gridSize; y += gridSize {
-		x0, y0 := op.GeoM.Apply(g.cameraX - ScreenWidth, y)
-		x1, y1 := op.GeoM.Apply(g.cameraX + ScreenWidth*2, y)
-		ebitenutil.DrawLine(screen, x0, y0, x1, y1, color.Gray{100})
+	// 绘制网格（帮助观察移动，G键切换）
+	if g.showGrid {
+		drawGridLines(screen, op.GeoM)
 	}
 
-	// 绘制地面方块
-	for _, block := range g.blocks {
-		x, y := op.GeoM.Apply(block.X, block.Y)
-		// 根据方块类型改变颜色
-		var blockColor color.RGBA
-		item, exists := itemRegistry[block.Type]
-		if exists {
-			blockColor = item.Color
-		} else {
-			blockColor = color.RGBA{100, 200, 100, 255} // 默认绿色
-		}
-		ebitenutil.DrawRect(screen, x, y, block.W, block.H, blockColor)
-	}
+	// 绘制地面方块（按区块缓存，只绘制视野内的区块）
+	g.renderer.Draw(screen, g, op.GeoM, g.clock.Daylight())
 
-	// 绘制玩家（红色方块）
-	x, y := op.GeoM.Apply(g.playerX, g.playerY)
-	ebitenutil.DrawRect(screen, x, y, PlayerSize, PlayerSize, color.RGBA{255, 0, 0, 255})
+	// 绘制实体（玩家、掉落物等）
+	g.entities.Draw(g, screen, op)
 	
 	// 绘制选中方块的黑框
 	if g.hasSelectedBlock {
 		x, y := op.GeoM.Apply(g.selectedBlockX, g.selectedBlockY)
+		x1, _ := op.GeoM.Apply(g.selectedBlockX+BlockSize, g.selectedBlockY)
+		size := x1 - x // 方块在屏幕上的大小
 		// 绘制黑框（比方块稍大一点，确保可见）
-		ebitenutil.DrawRect(screen, x-2, y-2, BlockSize+4, 2, color.RGBA{0, 0, 0, 255}) // 上边
-		ebitenutil.DrawRect(screen, x-2, y+BlockSize, BlockSize+4, 2, color.RGBA{0, 0, 0, 255}) // 下边
-		ebitenutil.DrawRect(screen, x-2, y, 2, BlockSize, color.RGBA{0, 0, 0, 255}) // 左边
-		ebitenutil.DrawRect(screen, x+BlockSize, y, 2, BlockSize, color.RGBA{0, 0, 0, 255}) // 右边
+		ebitenutil.DrawRect(screen, x-2, y-2, size+4, 2, color.RGBA{0, 0, 0, 255}) // 上边
+		ebitenutil.DrawRect(screen, x-2, y+size, size+4, 2, color.RGBA{0, 0, 0, 255}) // 下边
+		ebitenutil.DrawRect(screen, x-2, y, 2, size, color.RGBA{0, 0, 0, 255}) // 左边
+		ebitenutil.DrawRect(screen, x+size, y, 2, size, color.RGBA{0, 0, 0, 255}) // 右边
 	}
 	
-	// 绘制选择框
+	// 绘制建造工具的预览和选择框
+	g.drawClipboard(screen, op.GeoM)
 	if g.selecting {
+		g.drawToolPreview(screen, op.GeoM)
+		
 		// 计算选择框的屏幕坐标
 		startX, startY := op.GeoM.Apply(g.selectionStartX, g.selectionStartY)
 		endX, endY := op.GeoM.Apply(g.selectionEndX, g.selectionEndY)
@@ -1207,40 +863,75 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		ebitenutil.DrawLine(screen, maxX, minY, maxX, maxY, color.RGBA{255, 255, 255, 255})
 	}
 
-	// 调试信息
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Player: (%.1f, %.1f)", g.playerX, g.playerY), 10, 10)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Camera: (%.1f, %.1f)", g.cameraX, g.cameraY), 10, 30)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Velocity Y: %.2f", g.playerVelocityY), 10, 50)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("On Ground: %t", g.playerOnGround), 10, 70)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("World Bound: (%.0f,%.0f)-(%.0f,%.0f)", g.worldMinX, g.worldMinY, g.worldMaxX, g.worldMaxY), 10, 90)
-	
-	// 显示游戏模式
-	modeText := "Mode: Creative"
-	if g.gameMode == GameModeSurvival {
-		modeText = "Mode: Survival"
+	// 界面层以逻辑像素绘制，再按高DPI倍数放大到屏幕
+	hud := g.hudImage(screen)
+	if g.worldMap.Open {
+		g.drawFullMap(hud)
+	} else {
+		g.drawHUD(hud)
+		g.drawMinimap(hud)
+		if g.console.Open {
+			g.drawConsole(hud)
+		}
+	}
+	hudOp := &ebiten.DrawImageOptions{}
+	hudOp.GeoM.Scale(g.pixelScale(), g.pixelScale())
+	screen.DrawImage(hud, hudOp)
+}
+
+// hudImage 返回与屏幕逻辑尺寸一致的界面层图像（窗口大小变化时重建）
+func (g *Game) hudImage(screen *ebiten.Image) *ebiten.Image {
+	scale := g.pixelScale()
+	w := int(math.Ceil(float64(screen.Bounds().Dx()) / scale))
+	h := int(math.Ceil(float64(screen.Bounds().Dy()) / scale))
+	if g.hud == nil || g.hud.Bounds().Dx() != w || g.hud.Bounds().Dy() != h {
+		if g.hud != nil {
+			g.hud.Deallocate()
+		}
+		g.hud = ebiten.NewImage(w, h)
+	} else {
+		g.hud.Clear()
+	}
+	return g.hud
+}
+
+// drawHUD 绘制调试信息和物品栏（逻辑像素坐标，调试信息靠左上角，物品栏靠底部）
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	// 调试信息（F3切换）
+	if g.debug.Visible {
+		g.drawDebugOverlay(screen)
+	} else if g.pasting {
+		ebitenutil.DebugPrintAt(screen, g.clipboardStatus(), 10, 10)
+	} else {
+		ebitenutil.DebugPrintAt(screen, g.toolStatus(), 10, 10)
 	}
-	ebitenutil.DebugPrintAt(screen, modeText, 10, 110)
-	ebitenutil.DebugPrintAt(screen, "Press 'M' to switch mode", 10, 130)
-	
-	// 显示当前物品类型
-	currentItem := itemRegistry[g.currentItemType]
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Item: %s", currentItem.Name), 10, 150)
-	ebitenutil.DebugPrintAt(screen, "Press '1/2/3' or 'Q' to switch items", 10, 170)
 	
 	// 绘制物品栏
 	g.drawHotbar(screen)
-	
-	// 显示框选提示
-	if g.selecting {
-		ebitenutil.DebugPrintAt(screen, "Selecting area...", 10, 190)
-	} else {
-		ebitenutil.DebugPrintAt(screen, "Middle mouse button to select area", 10, 190)
-	}
 }
 
-// Layout 设置游戏窗口布局
+// Layout 设置游戏窗口布局，以设备像素渲染使高DPI屏幕上画面保持清晰
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return ScreenWidth, ScreenHeight
+	g.uiScale = ebiten.Monitor().DeviceScaleFactor()
+	g.screenWidth = int(math.Ceil(float64(outsideWidth) * g.uiScale))
+	g.screenHeight = int(math.Ceil(float64(outsideHeight) * g.uiScale))
+	return g.screenWidth, g.screenHeight
+}
+
+// viewSize 返回屏幕尺寸（设备像素），首次Layout之前使用默认窗口尺寸
+func (g *Game) viewSize() (float64, float64) {
+	if g.screenWidth == 0 || g.screenHeight == 0 {
+		return ScreenWidth, ScreenHeight
+	}
+	return float64(g.screenWidth), float64(g.screenHeight)
+}
+
+// pixelScale 返回高DPI缩放倍数（逻辑像素到设备像素）
+func (g *Game) pixelScale() float64 {
+	if g.uiScale <= 0 {
+		return 1
+	}
+	return g.uiScale
 }
 
 
@@ -1250,9 +941,18 @@ func main() {
 	
 	ebiten.SetWindowSize(ScreenWidth, ScreenHeight)
 	ebiten.SetWindowTitle("Smooth Camera Follow - Ebitengine")
-	ebiten.SetWindowResizable(false)
-
-	if err := ebiten.RunGame(&Game{}); err != nil {
+	ebiten.SetWindowResizable(true)
+	ebiten.SetWindowClosingHandled(true) // 关闭窗口前先保存世界
+
+	dayLength := flag.Int64("daylength", DefaultDayLength, "length of a full day in ticks")
+	savePath := flag.String("save", WorldSavePath, "world save file")
+	historyDepth := flag.Int("history", DefaultHistoryDepth, "number of block edits that can be undone")
+	worldType := flag.String("world", world.WorldDefault, "world type for new worlds: default, amplified, flat[:layers], skyblock, void or single:<biome>")
+	flag.Parse()
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	
+	if err := ebiten.RunGame(&Game{dayLength: *dayLength, savePath: *savePath, historyDepth: *historyDepth, dayLengthSet: explicit["daylength"], worldType: *worldType, worldTypeSet: explicit["world"]}); err != nil {
 		log.Fatal(err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 生物相关常量
+const (
+	MobSize           = 40  // 生物尺寸（小于一个方块，便于通过单格通道）
+	MobCap            = 12  // 世界中同时存在的生物上限
+	MobSpawnInterval  = 120 // 生成尝试间隔（帧）
+	MobSpawnMinDist   = 8   // 生成位置与玩家的最小水平距离（方块）
+	MobSpawnMaxDist   = 20  // 生成位置与玩家的最大水平距离（方块）
+	MobDespawnDist    = 40  // 距离玩家超过该值（方块）的生物会消失
+	MobWanderRadius   = 6   // 闲逛目标的水平半径（方块）
+	MobWanderInterval = 180 // 闲逛决策间隔（帧）
+	MobChaseRange     = 12  // 敌对生物追击玩家的距离（方块）
+	MobRepathInterval = 30  // 追击时重新寻路的间隔（帧）
+	MobStuckTicks     = 90  // 卡在同一路点超过该帧数则放弃路径
+	MobKnockback      = 6.0 // 敌对生物接触玩家时的击退速度
+	MobHitTrauma      = 0.4 // 敌对生物击中玩家时的屏幕震动强度
+)
+
+// MobType 定义生物种类
+type MobType int
+
+// 生物种类常量定义
+const (
+	MobTypePig    MobType = iota // 猪（被动）
+	MobTypeSheep                 // 羊（被动）
+	MobTypeZombie                // 僵尸（敌对）
+)
+
+// MobInfo 定义生物的属性
+type MobInfo struct {
+	Type    MobType
+	Name    string
+	Color   color.RGBA
+	Hostile bool          // 是否敌对
+	Speed   float64       // 移动速度
+	Biomes  []TerrainType // 被动生物可生成的地形，敌对生物忽略
+}
+
+// 全局生物注册表
+var mobRegistry = map[MobType]MobInfo{
+	MobTypePig: {
+		Type:   MobTypePig,
+		Name:   "Pig",
+		Color:  color.RGBA{240, 160, 170, 255},
+		Speed:  1.5,
+		Biomes: []TerrainType{TerrainTypePlains, TerrainTypeForest, TerrainTypeSavanna, TerrainTypeJungle},
+	},
+	MobTypeSheep: {
+		Type:   MobTypeSheep,
+		Name:   "Sheep",
+		Color:  color.RGBA{235, 235, 235, 255},
+		Speed:  1.5,
+		Biomes: []TerrainType{TerrainTypeHills, TerrainTypeMountains, TerrainTypeSnowyPlains, TerrainTypeTaiga},
+	},
+	MobTypeZombie: {
+		Type:    MobTypeZombie,
+		Name:    "Zombie",
+		Color:   color.RGBA{60, 140, 80, 255},
+		Hostile: true,
+		Speed:   2.0,
+	},
+}
+
+// MobComponent 生物AI组件
+type MobComponent struct {
+	Type       MobType
+	Path       []GridPos // 当前要走的路径
+	Target     *Entity   // 追击目标
+	ThinkTimer int       // 距下一次决策的帧数
+	StuckTimer int       // 在当前路点停留的帧数
+}
+
+// Info 返回生物的注册表信息
+func (m *MobComponent) Info() MobInfo {
+	return mobRegistry[m.Type]
+}
+
+// newMobEntity 创建站在指定格子上的生物实体
+func newMobEntity(mobType MobType, cell GridPos) *Entity {
+	return &Entity{
+		Kind:     EntityKindMob,
+		X:        float64(cell.X*BlockSize) + (BlockSize-MobSize)/2,
+		Y:        float64((cell.Y+1)*BlockSize) - MobSize,
+		W:        MobSize,
+		H:        MobSize,
+		Gravity:  true,
+		Mob:      &MobComponent{Type: mobType},
+		OnUpdate: updateMob,
+		OnDraw: func(g *Game, e *Entity, screen *ebiten.Image, op *ebiten.DrawImageOptions) {
+			drawEntityRect(screen, op, e, e.Mob.Info().Color)
+		},
+	}
+}
+
+// mobCell 返回生物脚下所在的格子
+func mobCell(e *Entity) GridPos {
+	return toGridPos(e.CenterX(), e.Y+e.H-1)
+}
+
+// updateMob 生物AI：决策、沿路径移动、与玩家交互
+func updateMob(g *Game, e *Entity) {
+	mob := e.Mob
+	info := mob.Info()
+
+	// 离玩家太远则消失
+	if g.player != nil && math.Abs(e.CenterX()-g.player.CenterX()) > MobDespawnDist*BlockSize {
+		e.Removed = true
+		return
+	}
+
+	mob.ThinkTimer--
+	if mob.ThinkTimer <= 0 {
+		g.mobThink(e)
+	}
+
+	g.followPath(e, info.Speed)
+	g.moveEntity(e)
+
+	// 敌对生物接触玩家时将其击退
+	if info.Hostile && g.player != nil && checkCollision(e.Rect(), g.player.Rect()) {
+		dir := 1.0
+		if g.player.CenterX() < e.CenterX() {
+			dir = -1
+		}
+		g.player.X += dir * MobKnockback
+		if g.player.OnGround {
+			g.player.VY = -MobKnockback
+		}
+		if g.camera != nil {
+			g.camera.AddTrauma(MobHitTrauma)
+		}
+	}
+}
+
+// mobThink 为生物选择新的目标并寻路
+func (g *Game) mobThink(e *Entity) {
+	mob := e.Mob
+	info := mob.Info()
+	grid := g.pathGrid()
+	start := mobCell(e)
+	maxJump := jumpHeightBlocks()
+
+	// 敌对生物在范围内追击玩家
+	if info.Hostile && g.player != nil {
+		playerCell := toGridPos(g.player.CenterX(), g.player.Y+g.player.H-1)
+		if pathHeuristic(start, playerCell) <= MobChaseRange {
+			mob.Target = g.player
+			mob.ThinkTimer = MobRepathInterval
+			if goal, ok := groundBelow(grid, playerCell, PathMaxFall); ok {
+				mob.Path = FindPath(grid, start, goal, maxJump, PathMaxFall)
+				mob.StuckTimer = 0
+			}
+			return
+		}
+	}
+	mob.Target = nil
+
+	// 否则随机闲逛
+	mob.ThinkTimer = MobWanderInterval/2 + g.rng.Intn(MobWanderInterval)
+	dx := g.rng.Intn(2*MobWanderRadius+1) - MobWanderRadius
+	for dy := -2; dy <= 2; dy++ {
+		if goal, ok := groundBelow(grid, GridPos{start.X + dx, start.Y + dy}, PathMaxFall); ok {
+			mob.Path = FindPath(grid, start, goal, maxJump, PathMaxFall)
+			mob.StuckTimer = 0
+			return
+		}
+	}
+}
+
+// followPath 根据当前路径设置生物速度，需要时起跳
+func (g *Game) followPath(e *Entity, speed float64) {
+	mob := e.Mob
+	e.VX = 0
+	if len(mob.Path) == 0 {
+		return
+	}
+
+	cell := mobCell(e)
+	next := mob.Path[0]
+	targetX := float64(next.X*BlockSize) + BlockSize/2
+
+	// 到达路点（同一格且水平基本居中）
+	if cell == next && math.Abs(e.CenterX()-targetX) < speed*2 {
+		mob.Path = mob.Path[1:]
+		mob.StuckTimer = 0
+		return
+	}
+
+	mob.StuckTimer++
+	if mob.StuckTimer > MobStuckTicks {
+		mob.Path = nil
+		mob.ThinkTimer = 0
+		return
+	}
+
+	if dx := targetX - e.CenterX(); math.Abs(dx) > 1 {
+		e.VX = math.Copysign(math.Min(speed, math.Abs(dx)), dx)
+	}
+
+	// 路点比当前格子高时起跳
+	if next.Y < cell.Y && e.OnGround {
+		e.VY = -JumpPower
+		e.OnGround = false
+	}
+}
+
+// pathGrid 返回当前方块网格
+func (g *Game) pathGrid() blockGrid {
+	if g.grid == nil {
+		g.grid = newBlockGrid(g.blocks)
+	}
+	return g.grid
+}
+
+// isDarkAt 判断格子是否足够黑暗，可以生成敌对生物
+func (g *Game) isDarkAt(p GridPos) bool {
+	return g.lightLevelAt(p) <= MobSpawnMaxLight
+}
+
+// updateMobSpawning 定期在玩家附近生成生物
+func (g *Game) updateMobSpawning() {
+	g.mobSpawnTimer--
+	if g.mobSpawnTimer > 0 {
+		return
+	}
+	g.mobSpawnTimer = MobSpawnInterval
+
+	mobCount := 0
+	for _, e := range g.entities.All() {
+		if e.Mob != nil {
+			mobCount++
+		}
+	}
+	if mobCount >= MobCap {
+		return
+	}
+
+	// 在玩家左侧或右侧随机选择一列
+	grid := g.pathGrid()
+	playerCell := toGridPos(g.player.CenterX(), g.player.CenterY())
+	offset := MobSpawnMinDist + g.rng.Intn(MobSpawnMaxDist-MobSpawnMinDist+1)
+	if g.rng.Intn(2) == 0 {
+		offset = -offset
+	}
+	x := playerCell.X + offset
+
+	// 纵向扫描该列所有可站立的格子，按光照分为明亮与黑暗（夜晚的地表也是黑暗的）
+	var lit, dark []GridPos
+	fromY := playerCell.Y - ChunkSize*GenerationDistance
+	toY := playerCell.Y + ChunkSize*GenerationDistance
+	for y := fromY; y < toY; y++ {
+		p := GridPos{x, y}
+		if !isStandable(grid, p) {
+			continue
+		}
+		if g.isDarkAt(p) {
+			dark = append(dark, p)
+		} else {
+			lit = append(lit, p)
+		}
+	}
+
+	// 敌对生物只在黑暗处生成，被动生物在符合地形的明亮地表生成
+	if len(dark) > 0 && g.rng.Intn(2) == 0 {
+		g.entities.Spawn(newMobEntity(MobTypeZombie, dark[g.rng.Intn(len(dark))]))
+		return
+	}
+	if len(lit) > 0 {
+		terrainType := g.generator().Biome(x)
+		for _, mobType := range []MobType{MobTypePig, MobTypeSheep} {
+			for _, biome := range mobRegistry[mobType].Biomes {
+				if biome == terrainType {
+					g.entities.Spawn(newMobEntity(mobType, lit[0]))
+					return
+				}
+			}
+		}
+	}
+}
+
+// despawnSunlitHostiles 日出时移除暴露在阳光下的敌对生物
+func (g *Game) despawnSunlitHostiles() {
+	for _, e := range g.entities.All() {
+		if e.Mob != nil && e.Mob.Info().Hostile && !g.isDarkAt(mobCell(e)) {
+			e.Removed = true
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package noise
+
+import "hash/fnv"
+
+// Channels 由一个世界种子派生互相独立的噪声通道
+//
+// 每种用途（高度、洞穴、矿石等）按名称取得自己的种子，不需要在同一噪声的
+// 不同坐标偏移处采样来“假装”独立，新增用途也不会改变已有通道的结果。
+type Channels struct {
+	Seed int64
+}
+
+// SeedFor 返回名称对应通道的种子
+func (c Channels) SeedFor(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	// splitmix64终结函数混合世界种子和名称哈希
+	z := uint64(c.Seed) ^ h.Sum64()
+	z ^= z >> 30
+	z *= 0xBF58476D1CE4E5B9
+	z ^= z >> 27
+	z *= 0x94D049BB133111EB
+	z ^= z >> 31
+	return int64(z)
+}
+
+// Perlin 返回名称对应的Perlin噪声通道
+func (c Channels) Perlin(name string) *Perlin {
+	return NewPerlin(c.SeedFor(name))
+}
+
+// Simplex 返回名称对应的单纯形噪声通道
+func (c Channels) Simplex(name string) *Simplex {
+	return NewSimplex(c.SeedFor(name))
+}
+
+// Value 返回名称对应的值噪声通道
+func (c Channels) Value(name string) *Value {
+	return NewValue(c.SeedFor(name))
+}
@@ -0,0 +1,69 @@
+package noise
+
+import "math"
+
+// Fractal 分形叠加参数：每层频率乘以Lacunarity、振幅乘以Gain
+type Fractal struct {
+	Octaves    int
+	Lacunarity float64 // 频率倍数，通常为2
+	Gain       float64 // 振幅倍数（持续度），越小高频细节越弱
+}
+
+// Octaves 返回频率逐层加倍、振幅按persistence衰减的分形参数
+func Octaves(octaves int, persistence float64) Fractal {
+	return Fractal{Octaves: octaves, Lacunarity: 2, Gain: persistence}
+}
+
+// FBM2D 分形布朗运动：叠加多层噪声并按总振幅归一化，取值范围与源噪声相同
+func (f Fractal) FBM2D(src Noise2D, x, y float64) float64 {
+	sum, total, amp, freq := 0.0, 0.0, 1.0, 1.0
+	for i := 0; i < f.Octaves; i++ {
+		sum += src.Eval2D(x*freq, y*freq) * amp
+		total += amp
+		amp *= f.Gain
+		freq *= f.Lacunarity
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// FBM3D 三维分形布朗运动
+func (f Fractal) FBM3D(src Noise3D, x, y, z float64) float64 {
+	sum, total, amp, freq := 0.0, 0.0, 1.0, 1.0
+	for i := 0; i < f.Octaves; i++ {
+		sum += src.Eval3D(x*freq, y*freq, z*freq) * amp
+		total += amp
+		amp *= f.Gain
+		freq *= f.Lacunarity
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// Ridged2D 山脊多重分形：每层取1-|n|的平方形成尖锐的山脊，并以上一层的值加权，
+// 使细节集中在山脊附近。取值范围为[0, 1]，1为山脊。
+func (f Fractal) Ridged2D(src Noise2D, x, y float64) float64 {
+	sum, total, amp, freq, weight := 0.0, 0.0, 1.0, 1.0, 1.0
+	for i := 0; i < f.Octaves; i++ {
+		signal := 1 - math.Abs(src.Eval2D(x*freq, y*freq))
+		signal *= signal * weight
+		weight = math.Min(1, math.Max(0, signal*2))
+		sum += signal * amp
+		total += amp
+		amp *= f.Gain
+		freq *= f.Lacunarity
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// Of 返回以f叠加src的二维噪声
+func (f Fractal) Of(src Noise2D) Noise2D {
+	return Func2D(func(x, y float64) float64 { return f.FBM2D(src, x, y) })
+}
@@ -0,0 +1,54 @@
+// Package noise 提供带种子的梯度噪声（Perlin、Simplex）、值噪声、分形叠加（fBm、山脊多重分形）
+// 与域扭曲，以及由一个世界种子派生互相独立的噪声通道。
+//
+// 所有基础噪声的取值范围为[-1, 1]，同一种子在任何平台上生成相同的结果。
+package noise
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Noise2D 二维噪声
+type Noise2D interface {
+	Eval2D(x, y float64) float64
+}
+
+// Noise3D 三维噪声
+type Noise3D interface {
+	Eval3D(x, y, z float64) float64
+}
+
+// Func2D 将函数适配为Noise2D
+type Func2D func(x, y float64) float64
+
+// Eval2D 实现Noise2D接口
+func (f Func2D) Eval2D(x, y float64) float64 {
+	return f(x, y)
+}
+
+// permutation 返回由种子决定的0~255排列，重复一次以免索引回绕
+func permutation(seed int64) [512]uint8 {
+	var perm [512]uint8
+	for i, v := range rand.New(rand.NewSource(seed)).Perm(256) {
+		perm[i] = uint8(v)
+		perm[i+256] = uint8(v)
+	}
+	return perm
+}
+
+// lattice 返回坐标所在格子的整数坐标（对256取模）和格子内的小数部分
+func lattice(x float64) (int, float64) {
+	f := math.Floor(x)
+	return int(f) & 255, x - f
+}
+
+// fade 五次平滑曲线6t^5-15t^4+10t^3，使插值在格点处一阶和二阶导数连续
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp 线性插值
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
@@ -0,0 +1,170 @@
+package noise
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// source 同时支持二维和三维采样的基础噪声
+type source interface {
+	Noise2D
+	Noise3D
+}
+
+// sources 测试和基准使用的基础噪声
+var sources = []struct {
+	name string
+	new  func(seed int64) source
+}{
+	{"Perlin", func(seed int64) source { return NewPerlin(seed) }},
+	{"Simplex", func(seed int64) source { return NewSimplex(seed) }},
+	{"Value", func(seed int64) source { return NewValue(seed) }},
+}
+
+// sampleRange 在随机坐标处采样n次，返回最小值和最大值
+func sampleRange(n int, f func(x, y, z float64) float64) (lo, hi float64) {
+	rng := rand.New(rand.NewSource(1))
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for i := 0; i < n; i++ {
+		v := f((rng.Float64()-0.5)*1000, (rng.Float64()-0.5)*1000, (rng.Float64()-0.5)*1000)
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// checkRange 检查取值在[min, max]内，并且至少覆盖了spread的宽度
+func checkRange(t *testing.T, name string, f func(x, y, z float64) float64, min, max, spread float64) {
+	t.Helper()
+	lo, hi := sampleRange(200000, f)
+	if lo < min || hi > max {
+		t.Errorf("%s: range [%.4f, %.4f] exceeds [%g, %g]", name, lo, hi, min, max)
+	}
+	if hi-lo < spread {
+		t.Errorf("%s: range [%.4f, %.4f] narrower than %g", name, lo, hi, spread)
+	}
+	t.Logf("%s: [%.4f, %.4f]", name, lo, hi)
+}
+
+func TestRange(t *testing.T) {
+	for _, s := range sources {
+		n := s.new(42)
+		checkRange(t, s.name+" 2D", func(x, y, _ float64) float64 { return n.Eval2D(x, y) }, -1, 1, 1.2)
+		checkRange(t, s.name+" 3D", n.Eval3D, -1, 1, 1.2)
+
+		f := Octaves(5, 0.5)
+		checkRange(t, s.name+" fBm 2D", func(x, y, _ float64) float64 { return f.FBM2D(n, x, y) }, -1, 1, 0.8)
+		checkRange(t, s.name+" fBm 3D", func(x, y, z float64) float64 { return f.FBM3D(n, x, y, z) }, -1, 1, 0.8)
+		checkRange(t, s.name+" ridged", func(x, y, _ float64) float64 { return f.Ridged2D(n, x, y) }, 0, 1, 0.5)
+
+		w := Warp{Source: n, OffsetX: s.new(43), OffsetY: s.new(44), Strength: 4}
+		checkRange(t, s.name+" warp", func(x, y, _ float64) float64 { return w.Eval2D(x, y) }, -1, 1, 1.2)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	for _, s := range sources {
+		a, b, c := s.new(7), s.new(7), s.new(8)
+		same, differs := true, false
+		for i := 0; i < 1000; i++ {
+			x, y, z := float64(i)*0.37, float64(i)*-0.73, float64(i)*0.11
+			if a.Eval2D(x, y) != b.Eval2D(x, y) || a.Eval3D(x, y, z) != b.Eval3D(x, y, z) {
+				same = false
+			}
+			if a.Eval2D(x, y) != c.Eval2D(x, y) {
+				differs = true
+			}
+		}
+		if !same {
+			t.Errorf("%s: same seed gives different values", s.name)
+		}
+		if !differs {
+			t.Errorf("%s: seeds 7 and 8 give identical noise", s.name)
+		}
+	}
+}
+
+func TestChannels(t *testing.T) {
+	c := Channels{Seed: 12345}
+	if c.SeedFor("height") != c.SeedFor("height") {
+		t.Fatal("SeedFor is not deterministic")
+	}
+	names := []string{"height", "detail", "caves", "ore Coal Ore", "ore Iron Ore", ""}
+	seen := make(map[int64]string)
+	for _, name := range names {
+		seed := c.SeedFor(name)
+		if other, ok := seen[seed]; ok {
+			t.Errorf("channels %q and %q share seed %d", name, other, seed)
+		}
+		seen[seed] = name
+		if (Channels{Seed: 54321}).SeedFor(name) == seed {
+			t.Errorf("channel %q has the same seed in different worlds", name)
+		}
+	}
+
+	// 不同通道在相同坐标处几乎不相关（原来的实现在同一噪声的不同偏移处采样）
+	a, b := c.Simplex("height"), c.Simplex("detail")
+	var sab, saa, sbb float64
+	for i := 0; i < 10000; i++ {
+		x := float64(i) * 0.13
+		va, vb := a.Eval2D(x, 0), b.Eval2D(x, 0)
+		sab, saa, sbb = sab+va*vb, saa+va*va, sbb+vb*vb
+	}
+	if r := sab / math.Sqrt(saa*sbb); math.Abs(r) > 0.1 {
+		t.Errorf("height and detail channels are correlated: r = %.3f", r)
+	}
+}
+
+func TestWarpZeroStrength(t *testing.T) {
+	p := NewPerlin(1)
+	w := Warp{Source: p, OffsetX: NewValue(2), OffsetY: NewValue(3)}
+	for i := 0; i < 100; i++ {
+		x, y := float64(i)*0.41, float64(i)*0.29
+		if w.Eval2D(x, y) != p.Eval2D(x, y) {
+			t.Fatalf("warp with zero strength changed the value at (%g, %g)", x, y)
+		}
+	}
+}
+
+func BenchmarkEval2D(b *testing.B) {
+	for _, s := range sources {
+		n := s.new(1)
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				n.Eval2D(float64(i)*0.01, 0.5)
+			}
+		})
+	}
+}
+
+func BenchmarkEval3D(b *testing.B) {
+	for _, s := range sources {
+		n := s.new(1)
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				n.Eval3D(float64(i)*0.01, 0.5, 0.25)
+			}
+		})
+	}
+}
+
+func BenchmarkFBM2D(b *testing.B) {
+	n, f := NewSimplex(1), Octaves(4, 0.5)
+	for i := 0; i < b.N; i++ {
+		f.FBM2D(n, float64(i)*0.01, 0.5)
+	}
+}
+
+func BenchmarkRidged2D(b *testing.B) {
+	n, f := NewSimplex(1), Octaves(4, 0.5)
+	for i := 0; i < b.N; i++ {
+		f.Ridged2D(n, float64(i)*0.01, 0.5)
+	}
+}
+
+func BenchmarkWarp(b *testing.B) {
+	w := Warp{Source: NewSimplex(1), OffsetX: NewSimplex(2), OffsetY: NewSimplex(3), Strength: 4}
+	for i := 0; i < b.N; i++ {
+		w.Eval2D(float64(i)*0.01, 0.5)
+	}
+}
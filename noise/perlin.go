@@ -0,0 +1,98 @@
+package noise
+
+// Perlin 改进的Perlin梯度噪声
+type Perlin struct {
+	perm [512]uint8
+}
+
+// NewPerlin 创建Perlin噪声
+func NewPerlin(seed int64) *Perlin {
+	return &Perlin{perm: permutation(seed)}
+}
+
+// perlin2Scale 和 perlin3Scale 将输出归一化到[-1, 1]
+const (
+	perlin2Scale = 1.0
+	perlin3Scale = 1 / 1.0363
+)
+
+// grad2 返回格点梯度（8个方向之一）与偏移量的点积
+func grad2(hash uint8, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// grad3 返回格点梯度（立方体12条棱的方向之一）与偏移量的点积
+func grad3(hash uint8, x, y, z float64) float64 {
+	h := hash & 15
+	u, v := x, y
+	if h >= 8 {
+		u = y
+	}
+	switch {
+	case h < 4:
+		v = y
+	case h == 12 || h == 14:
+		v = x
+	default:
+		v = z
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+// Eval2D 返回(x, y)处的噪声值
+func (p *Perlin) Eval2D(x, y float64) float64 {
+	X, x := lattice(x)
+	Y, y := lattice(y)
+	u, v := fade(x), fade(y)
+
+	a, b := int(p.perm[X]), int(p.perm[X+1])
+	aa, ab := p.perm[a+Y], p.perm[a+Y+1]
+	ba, bb := p.perm[b+Y], p.perm[b+Y+1]
+
+	return perlin2Scale * lerp(v,
+		lerp(u, grad2(aa, x, y), grad2(ba, x-1, y)),
+		lerp(u, grad2(ab, x, y-1), grad2(bb, x-1, y-1)))
+}
+
+// Eval3D 返回(x, y, z)处的噪声值
+func (p *Perlin) Eval3D(x, y, z float64) float64 {
+	X, x := lattice(x)
+	Y, y := lattice(y)
+	Z, z := lattice(z)
+	u, v, w := fade(x), fade(y), fade(z)
+
+	a, b := int(p.perm[X])+Y, int(p.perm[X+1])+Y
+	aa, ab := int(p.perm[a])+Z, int(p.perm[a+1])+Z
+	ba, bb := int(p.perm[b])+Z, int(p.perm[b+1])+Z
+
+	return perlin3Scale * lerp(w,
+		lerp(v,
+			lerp(u, grad3(p.perm[aa], x, y, z), grad3(p.perm[ba], x-1, y, z)),
+			lerp(u, grad3(p.perm[ab], x, y-1, z), grad3(p.perm[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad3(p.perm[aa+1], x, y, z-1), grad3(p.perm[ba+1], x-1, y, z-1)),
+			lerp(u, grad3(p.perm[ab+1], x, y-1, z-1), grad3(p.perm[bb+1], x-1, y-1, z-1))))
+}
@@ -0,0 +1,103 @@
+package noise
+
+import "math"
+
+// Simplex 单纯形噪声：在三角形（二维）或四面体（三维）网格上插值，
+// 没有Perlin噪声沿坐标轴的方向性，高维时计算量也更小
+type Simplex struct {
+	perm [512]uint8
+}
+
+// NewSimplex 创建单纯形噪声
+func NewSimplex(seed int64) *Simplex {
+	return &Simplex{perm: permutation(seed)}
+}
+
+// 斜切与反斜切系数
+var (
+	skew2   = 0.5 * (math.Sqrt(3) - 1)
+	unskew2 = (3 - math.Sqrt(3)) / 6
+)
+
+const (
+	skew3   = 1.0 / 3
+	unskew3 = 1.0 / 6
+)
+
+// simplex2Scale 和 simplex3Scale 将输出归一化到[-1, 1]
+const (
+	simplex2Scale = 70
+	simplex3Scale = 32
+)
+
+// corner 返回单纯形一个顶点的贡献
+func corner(t, g float64) float64 {
+	if t < 0 {
+		return 0
+	}
+	t *= t
+	return t * t * g
+}
+
+// Eval2D 返回(x, y)处的噪声值
+func (s *Simplex) Eval2D(x, y float64) float64 {
+	// 斜切到正方形网格，确定所在的三角形
+	k := (x + y) * skew2
+	i, j := math.Floor(x+k), math.Floor(y+k)
+	t := (i + j) * unskew2
+	x0, y0 := x-(i-t), y-(j-t)
+	i1, j1 := 0, 1
+	if x0 > y0 {
+		i1, j1 = 1, 0
+	}
+	x1, y1 := x0-float64(i1)+unskew2, y0-float64(j1)+unskew2
+	x2, y2 := x0-1+2*unskew2, y0-1+2*unskew2
+
+	ii, jj := int(i)&255, int(j)&255
+	g0 := s.perm[ii+int(s.perm[jj])]
+	g1 := s.perm[ii+i1+int(s.perm[jj+j1])]
+	g2 := s.perm[ii+1+int(s.perm[jj+1])]
+
+	n := corner(0.5-x0*x0-y0*y0, grad2(g0, x0, y0)) +
+		corner(0.5-x1*x1-y1*y1, grad2(g1, x1, y1)) +
+		corner(0.5-x2*x2-y2*y2, grad2(g2, x2, y2))
+	return simplex2Scale * n
+}
+
+// Eval3D 返回(x, y, z)处的噪声值
+func (s *Simplex) Eval3D(x, y, z float64) float64 {
+	k := (x + y + z) * skew3
+	i, j, l := math.Floor(x+k), math.Floor(y+k), math.Floor(z+k)
+	t := (i + j + l) * unskew3
+	x0, y0, z0 := x-(i-t), y-(j-t), z-(l-t)
+
+	// 按坐标大小顺序确定所在的四面体
+	var i1, j1, k1, i2, j2, k2 int
+	switch {
+	case x0 >= y0 && y0 >= z0:
+		i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 1, 0
+	case x0 >= y0 && x0 >= z0:
+		i1, j1, k1, i2, j2, k2 = 1, 0, 0, 1, 0, 1
+	case x0 >= y0:
+		i1, j1, k1, i2, j2, k2 = 0, 0, 1, 1, 0, 1
+	case y0 < z0:
+		i1, j1, k1, i2, j2, k2 = 0, 0, 1, 0, 1, 1
+	case x0 < z0:
+		i1, j1, k1, i2, j2, k2 = 0, 1, 0, 0, 1, 1
+	default:
+		i1, j1, k1, i2, j2, k2 = 0, 1, 0, 1, 1, 0
+	}
+	x1, y1, z1 := x0-float64(i1)+unskew3, y0-float64(j1)+unskew3, z0-float64(k1)+unskew3
+	x2, y2, z2 := x0-float64(i2)+2*unskew3, y0-float64(j2)+2*unskew3, z0-float64(k2)+2*unskew3
+	x3, y3, z3 := x0-1+3*unskew3, y0-1+3*unskew3, z0-1+3*unskew3
+
+	ii, jj, ll := int(i)&255, int(j)&255, int(l)&255
+	hash := func(di, dj, dl int) uint8 {
+		return s.perm[ii+di+int(s.perm[jj+dj+int(s.perm[ll+dl])])]
+	}
+	n := corner(0.6-x0*x0-y0*y0-z0*z0, grad3(hash(0, 0, 0), x0, y0, z0)) +
+		corner(0.6-x1*x1-y1*y1-z1*z1, grad3(hash(i1, j1, k1), x1, y1, z1)) +
+		corner(0.6-x2*x2-y2*y2-z2*z2, grad3(hash(i2, j2, k2), x2, y2, z2)) +
+		corner(0.6-x3*x3-y3*y3-z3*z3, grad3(hash(1, 1, 1), x3, y3, z3))
+	return simplex3Scale * n
+}
@@ -0,0 +1,45 @@
+package noise
+
+// Value 值噪声：在格点上取随机值并平滑插值，比梯度噪声更“块状”，适合低频的大尺度变化
+type Value struct {
+	perm [512]uint8
+}
+
+// NewValue 创建值噪声
+func NewValue(seed int64) *Value {
+	return &Value{perm: permutation(seed)}
+}
+
+// value 将格点哈希映射到[-1, 1]
+func value(hash uint8) float64 {
+	return float64(hash)/127.5 - 1
+}
+
+// Eval2D 返回(x, y)处的噪声值
+func (v *Value) Eval2D(x, y float64) float64 {
+	X, x := lattice(x)
+	Y, y := lattice(y)
+	u, w := fade(x), fade(y)
+	a, b := int(v.perm[X]), int(v.perm[X+1])
+	return lerp(w,
+		lerp(u, value(v.perm[a+Y]), value(v.perm[b+Y])),
+		lerp(u, value(v.perm[a+Y+1]), value(v.perm[b+Y+1])))
+}
+
+// Eval3D 返回(x, y, z)处的噪声值
+func (v *Value) Eval3D(x, y, z float64) float64 {
+	X, x := lattice(x)
+	Y, y := lattice(y)
+	Z, z := lattice(z)
+	u, w, s := fade(x), fade(y), fade(z)
+	a, b := int(v.perm[X])+Y, int(v.perm[X+1])+Y
+	aa, ab := int(v.perm[a])+Z, int(v.perm[a+1])+Z
+	ba, bb := int(v.perm[b])+Z, int(v.perm[b+1])+Z
+	return lerp(s,
+		lerp(w,
+			lerp(u, value(v.perm[aa]), value(v.perm[ba])),
+			lerp(u, value(v.perm[ab]), value(v.perm[bb]))),
+		lerp(w,
+			lerp(u, value(v.perm[aa+1]), value(v.perm[ba+1])),
+			lerp(u, value(v.perm[ab+1]), value(v.perm[bb+1]))))
+}
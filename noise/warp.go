@@ -0,0 +1,17 @@
+package noise
+
+// Warp 域扭曲：先用两个噪声偏移采样坐标再采样源噪声，使规则的噪声形状弯曲成
+// 更自然的河流、海岸线和地层。取值范围与源噪声相同。
+type Warp struct {
+	Source   Noise2D
+	OffsetX  Noise2D // x方向偏移噪声
+	OffsetY  Noise2D // y方向偏移噪声
+	Strength float64 // 最大偏移量（输入坐标单位）
+}
+
+// Eval2D 实现Noise2D接口
+func (w Warp) Eval2D(x, y float64) float64 {
+	dx := w.OffsetX.Eval2D(x, y) * w.Strength
+	dy := w.OffsetY.Eval2D(x, y) * w.Strength
+	return w.Source.Eval2D(x+dx, y+dy)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 视差背景常量
+const (
+	ParallaxFadeFrames   = 120   // 切换地形类型时的淡入淡出帧数
+	ParallaxColumn       = 8.0   // 山体轮廓的采样间隔（逻辑像素）
+	ParallaxCloudSpacing = 220.0 // 云层中每朵云占据的宽度（逻辑像素）
+	ParallaxCloudFactor  = 0.05  // 云层相对摄像机移动的比例
+	ParallaxCloudDrift   = 0.15  // 云每帧自行飘动的距离（逻辑像素）
+	ParallaxNightShade   = 0.3   // 夜晚背景保留的亮度
+)
+
+// parallaxLayer 一层山体轮廓
+type parallaxLayer struct {
+	factor    float64 // 相对摄像机移动的比例（越远越小）
+	frequency float64 // 轮廓起伏的频率（每逻辑像素）
+	seed      float64 // 轮廓相位，使各层形状不同
+	peaked    bool    // 尖峰（山脉）或圆顶（丘陵）
+}
+
+// 由远及近的山体层
+var parallaxLayers = []parallaxLayer{
+	{factor: 0.1, frequency: 0.004, seed: 11.3, peaked: true}, // 远山
+	{factor: 0.3, frequency: 0.009, seed: 47.9},               // 丘陵
+}
+
+// parallaxTheme 某种地形类型的背景外观
+type parallaxTheme struct {
+	colors     [2]color.RGBA // 各山体层颜色
+	baselines  [2]float64    // 各层基线（屏幕高度的比例）
+	amplitudes [2]float64    // 各层起伏高度（逻辑像素）
+	clouds     float64       // 云量（0~1）
+	cloud      color.RGBA
+}
+
+// 各地形类型的背景外观，未列出的类型使用平原
+var parallaxThemes = map[TerrainType]parallaxTheme{
+	TerrainTypePlains: {
+		colors:     [2]color.RGBA{{120, 150, 190, 255}, {90, 150, 90, 255}},
+		baselines:  [2]float64{0.55, 0.7},
+		amplitudes: [2]float64{60, 30},
+		clouds:     0.5,
+		cloud:      color.RGBA{255, 255, 255, 220},
+	},
+	TerrainTypeForest: {
+		colors:     [2]color.RGBA{{100, 135, 160, 255}, {40, 100, 50, 255}},
+		baselines:  [2]float64{0.5, 0.65},
+		amplitudes: [2]float64{80, 45},
+		clouds:     0.6,
+		cloud:      color.RGBA{245, 245, 250, 220},
+	},
+	TerrainTypeHills: {
+		colors:     [2]color.RGBA{{110, 130, 170, 255}, {80, 130, 70, 255}},
+		baselines:  [2]float64{0.5, 0.62},
+		amplitudes: [2]float64{110, 70},
+		clouds:     0.5,
+		cloud:      color.RGBA{255, 255, 255, 220},
+	},
+	TerrainTypeMountains: {
+		colors:     [2]color.RGBA{{130, 135, 160, 255}, {95, 100, 110, 255}},
+		baselines:  [2]float64{0.5, 0.62},
+		amplitudes: [2]float64{200, 110},
+		clouds:     0.7,
+		cloud:      color.RGBA{235, 235, 245, 230},
+	},
+	TerrainTypeSnowyPlains: {
+		colors:     [2]color.RGBA{{200, 210, 230, 255}, {225, 235, 245, 255}},
+		baselines:  [2]float64{0.5, 0.68},
+		amplitudes: [2]float64{140, 35},
+		clouds:     0.8,
+		cloud:      color.RGBA{230, 230, 240, 230},
+	},
+	TerrainTypeDesert: {
+		colors:     [2]color.RGBA{{215, 170, 120, 255}, {230, 200, 130, 255}},
+		baselines:  [2]float64{0.6, 0.72},
+		amplitudes: [2]float64{50, 25},
+		clouds:     0.1,
+		cloud:      color.RGBA{255, 250, 240, 200},
+	},
+	TerrainTypeSavanna: {
+		colors:     [2]color.RGBA{{190, 160, 120, 255}, {170, 160, 80, 255}},
+		baselines:  [2]float64{0.58, 0.7},
+		amplitudes: [2]float64{55, 30},
+		clouds:     0.3,
+		cloud:      color.RGBA{255, 250, 240, 210},
+	},
+	TerrainTypeBeach: {
+		colors:     [2]color.RGBA{{140, 170, 200, 255}, {220, 205, 150, 255}},
+		baselines:  [2]float64{0.58, 0.72},
+		amplitudes: [2]float64{40, 15},
+		clouds:     0.3,
+		cloud:      color.RGBA{255, 255, 255, 210},
+	},
+	TerrainTypeOcean: {
+		colors:     [2]color.RGBA{{120, 160, 200, 255}, {60, 110, 170, 255}},
+		baselines:  [2]float64{0.62, 0.75},
+		amplitudes: [2]float64{20, 8},
+		clouds:     0.4,
+		cloud:      color.RGBA{250, 250, 255, 220},
+	},
+}
+
+// themeFor 返回地形类型的背景外观
+func themeFor(t TerrainType) parallaxTheme {
+	if theme, ok := parallaxThemes[t]; ok {
+		return theme
+	}
+	return parallaxThemes[TerrainTypePlains]
+}
+
+// lerpTheme 在两种背景外观之间插值
+func lerpTheme(a, b parallaxTheme, t float64) parallaxTheme {
+	out := a
+	for i := range out.colors {
+		out.colors[i] = lerpColor(a.colors[i], b.colors[i], t)
+		out.baselines[i] = a.baselines[i] + (b.baselines[i]-a.baselines[i])*t
+		out.amplitudes[i] = a.amplitudes[i] + (b.amplitudes[i]-a.amplitudes[i])*t
+	}
+	out.clouds = a.clouds + (b.clouds-a.clouds)*t
+	out.cloud = lerpColor(a.cloud, b.cloud, t)
+	return out
+}
+
+// Parallax 视差背景，跟随玩家所在的地形类型淡入淡出
+type Parallax struct {
+	from, to TerrainType
+	fade     float64 // 从from过渡到to的进度（0~1）
+	started  bool
+}
+
+// Update 每帧推进淡入淡出，地形类型变化时开始新的过渡
+func (p *Parallax) Update(terrain TerrainType) {
+	if !p.started {
+		p.from, p.to, p.fade, p.started = terrain, terrain, 1, true
+	}
+	if terrain != p.to {
+		// 过渡到一半时反向切换，保留已混合的进度
+		if terrain == p.from {
+			p.from, p.to, p.fade = p.to, p.from, 1-p.fade
+		} else {
+			p.from, p.to, p.fade = p.to, terrain, 0
+		}
+	}
+	p.fade = math.Min(1, p.fade+1.0/ParallaxFadeFrames)
+}
+
+// Theme 返回当前混合后的背景外观
+func (p *Parallax) Theme() parallaxTheme {
+	return lerpTheme(themeFor(p.from), themeFor(p.to), p.fade)
+}
+
+// ridge 返回层轮廓在u处的高度（0~1）
+func ridge(u float64, peaked bool) float64 {
+	h := 0.5*math.Sin(u) + 0.3*math.Sin(2.3*u+1.7) + 0.2*math.Sin(5.1*u+0.3)
+	if peaked {
+		return 1 - math.Abs(h) // 取绝对值形成尖峰
+	}
+	return (h + 1) / 2
+}
+
+// hash01 将整数映射为[0,1)内的伪随机数（不依赖全局随机数）
+func hash01(n int, salt uint32) float64 {
+	h := uint32(n)*0x9E3779B1 ^ salt*0x85EBCA6B
+	h ^= h >> 15
+	h *= 0x2C1B3C6D
+	h ^= h >> 12
+	return float64(h%10000) / 10000
+}
+
+// drawParallax 在天空之上绘制云层和山体层
+//
+// 各层水平方向按摄像机位置的一定比例滚动，竖直方向随摄像机离开出生点高度移动，
+// 因此进入地下后背景会移出画面。
+func (g *Game) drawParallax(screen *ebiten.Image) {
+	theme := g.parallax.Theme()
+	scale := g.pixelScale()
+	w, h := float64(screen.Bounds().Dx())/scale, float64(screen.Bounds().Dy())/scale
+	shade := ParallaxNightShade + (1-ParallaxNightShade)*g.clock.Daylight()
+	camX, camY := g.camera.X, g.camera.Y-g.spawnY
+
+	// 云层：按固定间隔划分，每格是否有云由哈希决定
+	drift := float64(g.clock.Tick) * ParallaxCloudDrift
+	offset := camX*ParallaxCloudFactor + drift
+	cloudColor := shadeColor(theme.cloud, shade)
+	first := int(math.Floor(offset/ParallaxCloudSpacing)) - 1 // 左边缘外的云可能部分可见
+	for i := first; float64(i)*ParallaxCloudSpacing-offset < w; i++ {
+		if hash01(i, 1) >= theme.clouds {
+			continue
+		}
+		x := float64(i)*ParallaxCloudSpacing - offset + hash01(i, 2)*ParallaxCloudSpacing/2
+		y := h*0.1 + hash01(i, 3)*h*0.2 - camY*ParallaxCloudFactor
+		r := 14 + hash01(i, 4)*12
+		for _, puff := range [][3]float64{{0, 0, 1}, {r, -r * 0.4, 1.2}, {2 * r, 0, 0.9}} {
+			vector.DrawFilledCircle(screen, float32((x+puff[0])*scale), float32((y+puff[1])*scale),
+				float32(r*puff[2]*scale), cloudColor, true)
+		}
+	}
+
+	// 山体层：轮廓以下填充到屏幕底部，一层一次DrawTriangles
+	for i, layer := range parallaxLayers {
+		c := shadeColor(theme.colors[i], shade)
+		cr, cg, cb := float32(c.R)/255, float32(c.G)/255, float32(c.B)/255
+		baseline := h*theme.baselines[i] - camY*layer.factor
+		var vertices []ebiten.Vertex
+		var indices []uint16
+		for x := 0.0; ; x += ParallaxColumn {
+			u := (x+camX*layer.factor)*layer.frequency + layer.seed
+			top := baseline - theme.amplitudes[i]*ridge(u, layer.peaked)
+			bottom := math.Max(top, h)
+			n := uint16(len(vertices))
+			for _, y := range []float64{top, bottom} {
+				vertices = append(vertices, ebiten.Vertex{
+					DstX: float32(x * scale), DstY: float32(y * scale), SrcX: 1, SrcY: 1,
+					ColorR: cr, ColorG: cg, ColorB: cb, ColorA: 1,
+				})
+			}
+			if n > 0 {
+				indices = append(indices, n-2, n-1, n, n-1, n, n+1)
+			}
+			if x >= w {
+				break
+			}
+		}
+		screen.DrawTriangles(vertices, indices, getWhiteImage(), nil)
+	}
+}
+
+// terrainUnderPlayer 返回玩家所在列的地形类型
+func (g *Game) terrainUnderPlayer() TerrainType {
+	return g.generator().Biome(int(math.Floor(g.player.CenterX() / BlockSize)))
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+)
+
+// 寻路相关常量
+const (
+	PathMaxFall  = 4    // 寻路时允许的最大下落高度（方块）
+	PathMaxNodes = 2000 // 单次寻路最多展开的节点数
+)
+
+// GridPos 方块网格坐标
+type GridPos struct {
+	X, Y int
+}
+
+// toGridPos 将世界坐标转换为网格坐标
+func toGridPos(worldX, worldY float64) GridPos {
+	return GridPos{int(math.Floor(worldX / BlockSize)), int(math.Floor(worldY / BlockSize))}
+}
+
+// PathGrid 寻路使用的网格接口，只关心某个格子是否为实心方块
+type PathGrid interface {
+	Solid(x, y int) bool
+}
+
+// blockGrid 以方块网格坐标为键记录每个格子的方块类型
+type blockGrid map[GridPos]ItemType
+
+// Solid 实现PathGrid和LightWorld接口，液体不算实心
+func (bg blockGrid) Solid(x, y int) bool {
+	t, ok := bg[GridPos{x, y}]
+	return ok && t.Solid()
+}
+
+// Emission 实现LightWorld接口，返回格子中方块的发光强度
+func (bg blockGrid) Emission(x, y int) int {
+	if t, ok := bg[GridPos{x, y}]; ok {
+		return itemRegistry[t].Light
+	}
+	return 0
+}
+
+// add 记录方块占据的所有格子
+func (bg blockGrid) add(block Block) {
+	for _, p := range blockCells(block) {
+		bg[p] = block.Type
+	}
+}
+
+// remove 清除方块占据的所有格子
+func (bg blockGrid) remove(block Block) {
+	for _, p := range blockCells(block) {
+		delete(bg, p)
+	}
+}
+
+// blockCells 返回方块占据的格子，宽方块（如树冠）会占据多个格子
+func blockCells(block Block) []GridPos {
+	x0 := int(math.Floor(block.X / BlockSize))
+	y0 := int(math.Floor(block.Y / BlockSize))
+	x1 := int(math.Ceil((block.X+block.W)/BlockSize)) - 1
+	y1 := int(math.Ceil((block.Y+block.H)/BlockSize)) - 1
+	cells := make([]GridPos, 0, (x1-x0+1)*(y1-y0+1))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			cells = append(cells, GridPos{x, y})
+		}
+	}
+	return cells
+}
+
+// newBlockGrid 根据方块列表构建网格
+func newBlockGrid(blocks []Block) blockGrid {
+	bg := make(blockGrid, len(blocks))
+	for _, block := range blocks {
+		bg.add(block)
+	}
+	return bg
+}
+
+// jumpHeightBlocks 根据JumpPower和Gravity计算一次跳跃能登上的方块层数
+// 与moveEntity中的积分方式保持一致：先加速度再位移
+func jumpHeightBlocks() int {
+	v := -JumpPower
+	height := 0.0
+	for {
+		v += Gravity
+		if v >= 0 {
+			break
+		}
+		height -= v
+	}
+	return int(height / BlockSize)
+}
+
+// isStandable 判断生物能否站在指定格子（格子为空且脚下为实心）
+func isStandable(grid PathGrid, p GridPos) bool {
+	return !grid.Solid(p.X, p.Y) && grid.Solid(p.X, p.Y+1)
+}
+
+// groundBelow 从指定格子向下寻找第一个可站立的格子
+func groundBelow(grid PathGrid, p GridPos, maxFall int) (GridPos, bool) {
+	for d := 0; d <= maxFall; d++ {
+		q := GridPos{p.X, p.Y + d}
+		if grid.Solid(q.X, q.Y) {
+			return GridPos{}, false
+		}
+		if grid.Solid(q.X, q.Y+1) {
+			return q, true
+		}
+	}
+	return GridPos{}, false
+}
+
+// pathStep 相邻可达格子及移动代价
+type pathStep struct {
+	pos  GridPos
+	cost int
+}
+
+// pathNeighbors 列出从某个可站立格子出发可到达的格子：平走、跳上和下落
+func pathNeighbors(grid PathGrid, p GridPos, maxJump, maxFall int) []pathStep {
+	var steps []pathStep
+	for _, dx := range []int{-1, 1} {
+		side := GridPos{p.X + dx, p.Y}
+		if !grid.Solid(side.X, side.Y) {
+			// 平走或从边缘下落
+			if q, ok := groundBelow(grid, side, maxFall); ok {
+				steps = append(steps, pathStep{q, 1 + q.Y - p.Y})
+			}
+			continue
+		}
+
+		// 侧面被挡住，尝试跳上去（头顶需要留出空间）
+		for h := 1; h <= maxJump; h++ {
+			if grid.Solid(p.X, p.Y-h) {
+				break
+			}
+			q := GridPos{p.X + dx, p.Y - h}
+			if isStandable(grid, q) {
+				steps = append(steps, pathStep{q, 1 + h})
+				break
+			}
+		}
+	}
+	return steps
+}
+
+// FindPath 使用A*在方块网格上寻找从start到goal的路径
+// 返回的路径不含起点、包含终点；无法到达时返回nil
+func FindPath(grid PathGrid, start, goal GridPos, maxJump, maxFall int) []GridPos {
+	if !isStandable(grid, goal) {
+		return nil
+	}
+	if start == goal {
+		return []GridPos{}
+	}
+
+	open := &pathQueue{}
+	cameFrom := make(map[GridPos]GridPos)
+	gScore := map[GridPos]int{start: 0}
+	closed := make(map[GridPos]bool)
+	seq := 0
+	heap.Push(open, &pathNode{pos: start, f: pathHeuristic(start, goal), seq: seq})
+
+	for expanded := 0; open.Len() > 0 && expanded < PathMaxNodes; expanded++ {
+		current := heap.Pop(open).(*pathNode)
+		if current.pos == goal {
+			return reconstructPath(cameFrom, start, goal)
+		}
+		if closed[current.pos] {
+			continue
+		}
+		closed[current.pos] = true
+
+		for _, step := range pathNeighbors(grid, current.pos, maxJump, maxFall) {
+			if closed[step.pos] {
+				continue
+			}
+			tentative := gScore[current.pos] + step.cost
+			if old, ok := gScore[step.pos]; ok && tentative >= old {
+				continue
+			}
+			gScore[step.pos] = tentative
+			cameFrom[step.pos] = current.pos
+			seq++
+			heap.Push(open, &pathNode{pos: step.pos, f: tentative + pathHeuristic(step.pos, goal), seq: seq})
+		}
+	}
+	return nil
+}
+
+// pathHeuristic 曼哈顿距离启发函数
+func pathHeuristic(a, b GridPos) int {
+	dx := a.X - b.X
+	if dx < 0 {
+		dx = -dx
+	}
+	dy := a.Y - b.Y
+	if dy < 0 {
+		dy = -dy
+	}
+	return dx + dy
+}
+
+// reconstructPath 根据前驱表还原路径
+func reconstructPath(cameFrom map[GridPos]GridPos, start, goal GridPos) []GridPos {
+	var path []GridPos
+	for p := goal; p != start; p = cameFrom[p] {
+		path = append(path, p)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// pathNode A*开放列表中的节点
+type pathNode struct {
+	pos GridPos
+	f   int // 已走代价加启发值
+	seq int // 插入序号，保证相同f值时结果确定
+}
+
+// pathQueue 按f值排序的优先队列
+type pathQueue []*pathNode
+
+func (q pathQueue) Len() int { return len(q) }
+
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].f != q[j].f {
+		return q[i].f < q[j].f
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x any) { *q = append(*q, x.(*pathNode)) }
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	n := len(old)
+	node := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return node
+}
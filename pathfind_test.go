@@ -0,0 +1,183 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// parseTestMap 解析手工绘制的地图：'#'为实心方块，'S'为起点，'G'为终点，其余为空气
+func parseTestMap(t *testing.T, rows []string) (blockGrid, GridPos, GridPos) {
+	t.Helper()
+	grid := make(blockGrid)
+	var start, goal GridPos
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case '#':
+				grid[GridPos{x, y}] = ItemTypeStone
+			case 'S':
+				start = GridPos{x, y}
+			case 'G':
+				goal = GridPos{x, y}
+			}
+		}
+	}
+	return grid, start, goal
+}
+
+func TestJumpHeightBlocks(t *testing.T) {
+	// JumpPower=12、Gravity=0.5时最高约138像素，可登上2格
+	if got := jumpHeightBlocks(); got != 2 {
+		t.Fatalf("jumpHeightBlocks() = %d, want 2", got)
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want []GridPos // nil表示不可达
+	}{
+		{
+			name: "flat",
+			rows: []string{
+				"S...G",
+				"#####",
+			},
+			want: []GridPos{{1, 0}, {2, 0}, {3, 0}, {4, 0}},
+		},
+		{
+			name: "step up two",
+			rows: []string{
+				"....G",
+				"...##",
+				"S..##",
+				"#####",
+			},
+			want: []GridPos{{1, 2}, {2, 2}, {3, 0}, {4, 0}},
+		},
+		{
+			name: "wall too high",
+			rows: []string{
+				"...G",
+				"..##",
+				"..##",
+				"S.##",
+				"####",
+			},
+			want: nil,
+		},
+		{
+			name: "no headroom",
+			rows: []string{
+				"##.G",
+				"S.##",
+				"####",
+			},
+			want: nil,
+		},
+		{
+			name: "fall into pit",
+			rows: []string{
+				"S....",
+				"##...",
+				"##...",
+				"##..G",
+				"#####",
+			},
+			want: []GridPos{{1, 0}, {2, 3}, {3, 3}, {4, 3}},
+		},
+		{
+			name: "fall too deep",
+			rows: []string{
+				"S.",
+				"#.",
+				"#.",
+				"#.",
+				"#.",
+				"#.",
+				"#G",
+				"##",
+			},
+			want: nil,
+		},
+		{
+			name: "detour around wall",
+			rows: []string{
+				".....",
+				"S.#.G",
+				"#####",
+			},
+			want: []GridPos{{1, 1}, {2, 0}, {3, 1}, {4, 1}},
+		},
+		{
+			name: "goal in air",
+			rows: []string{
+				"S..G",
+				"##..",
+				"####",
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid, start, goal := parseTestMap(t, tt.rows)
+			got := FindPath(grid, start, goal, jumpHeightBlocks(), PathMaxFall)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("FindPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPathDeterministic(t *testing.T) {
+	grid, start, goal := parseTestMap(t, []string{
+		"..........",
+		"S.........",
+		"###...#..G",
+		"###.####.#",
+		"##########",
+	})
+	first := FindPath(grid, start, goal, jumpHeightBlocks(), PathMaxFall)
+	if first == nil {
+		t.Fatal("expected a path")
+	}
+	for i := 0; i < 20; i++ {
+		if got := FindPath(grid, start, goal, jumpHeightBlocks(), PathMaxFall); !reflect.DeepEqual(got, first) {
+			t.Fatalf("run %d: FindPath() = %v, want %v", i, got, first)
+		}
+	}
+}
+
+func TestNewBlockGridWideBlocks(t *testing.T) {
+	// 树冠等宽方块应占据多个格子
+	grid := newBlockGrid([]Block{{X: -BlockSize, Y: 0, W: BlockSize * 3, H: BlockSize}})
+	for x := -1; x <= 1; x++ {
+		if !grid.Solid(x, 0) {
+			t.Errorf("cell (%d,0) should be solid", x)
+		}
+	}
+	if grid.Solid(2, 0) || grid.Solid(-2, 0) {
+		t.Error("cells outside the block should be empty")
+	}
+}
+
+func TestBlockGridLiquidIsNotSolid(t *testing.T) {
+	// 水和岩浆不阻挡寻路和光线，但岩浆仍然发光
+	grid := newBlockGrid([]Block{
+		{X: 0, Y: 0, W: BlockSize, H: BlockSize, Type: ItemTypeWater},
+		{X: BlockSize, Y: 0, W: BlockSize, H: BlockSize, Type: ItemTypeLava},
+		{X: 2 * BlockSize, Y: 0, W: BlockSize, H: BlockSize, Type: ItemTypeStone},
+	})
+	if grid.Solid(0, 0) || grid.Solid(1, 0) {
+		t.Error("liquid cells should not be solid")
+	}
+	if !grid.Solid(2, 0) {
+		t.Error("stone cell should be solid")
+	}
+	if grid.Emission(1, 0) == 0 {
+		t.Error("lava should still emit light")
+	}
+}
@@ -0,0 +1,160 @@
+// Package region 按玩家位置生成区块：只生成高度范围内、玩家附近的区块，
+// 并在玩家离开后卸载远处的区块，使生成量只与玩家数量有关，与世界大小无关。
+package region
+
+import (
+	"sort"
+
+	"2d.go/world"
+)
+
+// Limits 区块生成的范围限制（以区块为单位）
+type Limits struct {
+	MinChunkY, MaxChunkY int // 生成的区块行范围（包含），避免生成过高或过低的区块
+	RadiusX, RadiusY     int // 只生成与玩家所在区块距离不超过这些区块数的区块
+}
+
+// 默认地形的地表行范围（从山顶到海底），以及树木伸出地表的最大格数
+const (
+	MinSurface = -30
+	MaxSurface = 60
+	TreeMargin = 16
+)
+
+// DefaultLimits 返回默认的范围限制：区块行覆盖默认地形的整个地形柱和树木，
+// 水平和垂直方向分别生成玩家附近10个和5个区块
+func DefaultLimits() Limits {
+	return Limits{
+		MinChunkY: world.FloorDiv(MinSurface-TreeMargin, world.ChunkSize),
+		MaxChunkY: world.FloorDiv(MaxSurface+world.TerrainDepth-1, world.ChunkSize),
+		RadiusX:   10,
+		RadiusY:   5,
+	}
+}
+
+// LimitsFor 返回适合gen的范围限制：起伏放大的地形按倍数扩大地表行范围，
+// 其余生成器使用DefaultLimits
+func LimitsFor(gen world.ChunkGenerator) Limits {
+	l := DefaultLimits()
+	if tg, ok := gen.(*world.TerrainGenerator); ok && tg.HeightScale > 1 {
+		l.MinChunkY = world.FloorDiv(int(MinSurface*tg.HeightScale)-TreeMargin, world.ChunkSize)
+		l.MaxChunkY = world.FloorDiv(int(MaxSurface*tg.HeightScale)+world.TerrainDepth-1, world.ChunkSize)
+	}
+	return l
+}
+
+// InHeight 判断区块行是否在高度范围内
+func (l Limits) InHeight(chunkY int) bool {
+	return chunkY >= l.MinChunkY && chunkY <= l.MaxChunkY
+}
+
+// Near 判断区块是否在玩家所在区块附近
+func (l Limits) Near(chunk, player world.GridPos) bool {
+	return abs(chunk.X-player.X) <= l.RadiusX && abs(chunk.Y-player.Y) <= l.RadiusY
+}
+
+// Region 已生成的区块集合
+//
+// 区块的内容只由生成器决定，因此卸载后再次生成的区块与原来相同。
+// Region不是并发安全的。
+type Region struct {
+	gen    world.ChunkGenerator
+	limits Limits
+	chunks map[world.GridPos]*world.Chunk
+}
+
+// New 创建使用gen生成区块的区域
+func New(gen world.ChunkGenerator, limits Limits) *Region {
+	return &Region{gen: gen, limits: limits, chunks: make(map[world.GridPos]*world.Chunk)}
+}
+
+// Limits 返回区域的范围限制
+func (r *Region) Limits() Limits {
+	return r.limits
+}
+
+// Chunk 返回已生成的区块
+func (r *Region) Chunk(x, y int) (*world.Chunk, bool) {
+	c, ok := r.chunks[world.GridPos{X: x, Y: y}]
+	return c, ok
+}
+
+// Len 返回已生成的区块数
+func (r *Region) Len() int {
+	return len(r.chunks)
+}
+
+// Load 生成高度范围内尚未生成的单个区块，已生成或超出高度范围时返回false
+func (r *Region) Load(x, y int) (*world.Chunk, bool) {
+	p := world.GridPos{X: x, Y: y}
+	if _, ok := r.chunks[p]; ok || !r.limits.InHeight(y) {
+		return nil, false
+	}
+	c := r.gen.GenerateChunk(x, y)
+	r.chunks[p] = c
+	return c, true
+}
+
+// Update 生成任一玩家附近、高度范围内尚未生成的区块，players为玩家所在的方块格子，
+// 返回按坐标排序的新区块
+func (r *Region) Update(players ...world.GridPos) []*world.Chunk {
+	var added []*world.Chunk
+	for _, player := range players {
+		center := world.ChunkOf(player.X, player.Y)
+		minY := max(center.Y-r.limits.RadiusY, r.limits.MinChunkY)
+		maxY := min(center.Y+r.limits.RadiusY, r.limits.MaxChunkY)
+		for x := center.X - r.limits.RadiusX; x <= center.X+r.limits.RadiusX; x++ {
+			for y := minY; y <= maxY; y++ {
+				p := world.GridPos{X: x, Y: y}
+				if _, ok := r.chunks[p]; ok {
+					continue
+				}
+				c := r.gen.GenerateChunk(x, y)
+				r.chunks[p] = c
+				added = append(added, c)
+			}
+		}
+	}
+	sort.Slice(added, func(i, j int) bool {
+		return less(world.GridPos{X: added[i].X, Y: added[i].Y}, world.GridPos{X: added[j].X, Y: added[j].Y})
+	})
+	return added
+}
+
+// Unload 卸载不在任何玩家附近的区块，返回按坐标排序的被卸载区块坐标
+func (r *Region) Unload(players ...world.GridPos) []world.GridPos {
+	var removed []world.GridPos
+	for p := range r.chunks {
+		near := false
+		for _, player := range players {
+			if r.limits.Near(p, world.ChunkOf(player.X, player.Y)) {
+				near = true
+				break
+			}
+		}
+		if !near {
+			removed = append(removed, p)
+		}
+	}
+	for _, p := range removed {
+		delete(r.chunks, p)
+	}
+	sort.Slice(removed, func(i, j int) bool { return less(removed[i], removed[j]) })
+	return removed
+}
+
+// less 按先x后y的顺序比较区块坐标
+func less(a, b world.GridPos) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	return a.Y < b.Y
+}
+
+// abs 返回整数的绝对值
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
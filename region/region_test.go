@@ -0,0 +1,107 @@
+package region
+
+import (
+	"fmt"
+	"testing"
+
+	"2d.go/world"
+)
+
+func TestUpdateLimits(t *testing.T) {
+	gen := world.NewTerrainGenerator(world.TerrainSeed)
+	limits := Limits{MinChunkY: -2, MaxChunkY: 2, RadiusX: 3, RadiusY: 5}
+	r := New(gen, limits)
+
+	added := r.Update(world.GridPos{X: 5, Y: 0})
+	// 水平7个区块，垂直受高度范围限制为5行
+	if len(added) != 7*5 || r.Len() != 7*5 {
+		t.Fatalf("generated %d chunks (region holds %d), want %d", len(added), r.Len(), 7*5)
+	}
+	for _, c := range added {
+		if c.X < -3 || c.X > 3 || !limits.InHeight(c.Y) {
+			t.Errorf("chunk (%d, %d) outside the limits", c.X, c.Y)
+		}
+		if fmt.Sprint(c.Blocks) != fmt.Sprint(gen.GenerateChunk(c.X, c.Y).Blocks) {
+			t.Errorf("chunk (%d, %d) differs from the generator", c.X, c.Y)
+		}
+	}
+	if _, ok := r.Chunk(0, 0); !ok {
+		t.Error("player chunk not generated")
+	}
+
+	// 已生成的区块不会重复生成
+	if again := r.Update(world.GridPos{X: 9, Y: 9}); len(again) != 0 {
+		t.Errorf("second update generated %d chunks", len(again))
+	}
+
+	// 玩家远离高度范围时不生成区块
+	if far := r.Update(world.GridPos{X: 0, Y: 200}); len(far) != 0 {
+		t.Errorf("generated %d chunks for a player far above the height limit", len(far))
+	}
+}
+
+func TestUnload(t *testing.T) {
+	r := New(world.NewTerrainGenerator(world.TerrainSeed), Limits{MinChunkY: -1, MaxChunkY: 1, RadiusX: 2, RadiusY: 1})
+	a, b := world.GridPos{X: 0, Y: 0}, world.GridPos{X: 100, Y: 0}
+	r.Update(a, b)
+	if r.Len() != 2*5*3 {
+		t.Fatalf("two players generated %d chunks, want %d", r.Len(), 2*5*3)
+	}
+
+	// 玩家b离开后，只保留玩家a附近的区块
+	removed := r.Unload(a)
+	if len(removed) != 5*3 || r.Len() != 5*3 {
+		t.Fatalf("unloaded %d chunks, %d left", len(removed), r.Len())
+	}
+	for _, p := range removed {
+		if p.X < 8 || p.X > 12 {
+			t.Errorf("unloaded chunk %v near player a", p)
+		}
+	}
+	if _, ok := r.Chunk(10, 0); ok {
+		t.Error("chunk near player b still loaded")
+	}
+
+	// 卸载后再次生成的区块与原来相同
+	if added := r.Update(b); len(added) != 5*3 {
+		t.Errorf("reloading generated %d chunks", len(added))
+	}
+}
+
+func TestDefaultLimits(t *testing.T) {
+	for _, spec := range []string{world.WorldDefault, world.WorldAmplified} {
+		gen, err := world.NewChunkGenerator(spec, world.TerrainSeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		limits := LimitsFor(gen)
+		// 所有列的地形柱和树木都在高度范围内
+		for x := -20000; x < 20000; x++ {
+			h := gen.SurfaceHeight(x)
+			for _, y := range []int{h - TreeMargin, h + world.TerrainDepth - 1} {
+				if cy := world.ChunkOf(x, y).Y; !limits.InHeight(cy) {
+					t.Fatalf("%s: terrain cell (%d, %d) in chunk row %d outside %d..%d", spec, x, y, cy, limits.MinChunkY, limits.MaxChunkY)
+				}
+			}
+		}
+	}
+	if LimitsFor(world.NewTerrainGenerator(world.TerrainSeed)) != DefaultLimits() {
+		t.Error("default terrain does not use DefaultLimits")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	r := New(world.NewTerrainGenerator(world.TerrainSeed), Limits{MinChunkY: -1, MaxChunkY: 1, RadiusX: 2, RadiusY: 1})
+	if _, ok := r.Load(50, 0); !ok {
+		t.Fatal("chunk inside the height limits not loaded")
+	}
+	if _, ok := r.Load(50, 0); ok {
+		t.Error("loaded chunk loaded again")
+	}
+	if _, ok := r.Load(50, 5); ok {
+		t.Error("loaded a chunk outside the height limits")
+	}
+	if _, ok := r.Chunk(50, 0); !ok || r.Len() != 1 {
+		t.Errorf("region holds %d chunks after loading one", r.Len())
+	}
+}
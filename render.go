@@ -0,0 +1,303 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 方块渲染相关常量
+const (
+	RenderChunkPixels = ChunkSize * BlockSize // 渲染区块缓存图像的边长（像素）
+	RenderCacheMargin = 2                     // 视野外仍保留缓存的区块数
+)
+
+// RenderStats 最近一帧方块层的绘制统计
+type RenderStats struct {
+	DrawCalls     int // 提交的绘制调用数（DrawImage与DrawTriangles）
+	ChunksVisible int // 视野内含有方块的区块数
+	ChunksBuilt   int // 本帧重建缓存的区块数
+	Cached        int // 当前保留的区块缓存数
+}
+
+// chunkMesh 一个渲染区块的顶点数据，按纹理分为贴图和纯色两批
+type chunkMesh struct {
+	sprites   []ebiten.Vertex
+	spriteIdx []uint16
+	colors    []ebiten.Vertex
+	colorIdx  []uint16
+}
+
+// batches 返回需要提交的DrawTriangles次数
+func (m *chunkMesh) batches() int {
+	n := 0
+	if len(m.spriteIdx) > 0 {
+		n++
+	}
+	if len(m.colorIdx) > 0 {
+		n++
+	}
+	return n
+}
+
+// renderChunk 一个渲染区块（ChunkSize×ChunkSize格）的缓存
+type renderChunk struct {
+	pos        GridPos       // 区块坐标
+	image      *ebiten.Image // 缓存的离屏图像
+	mesh       *chunkMesh    // 等待提交到离屏图像的顶点数据
+	dirty      bool          // 方块或光照变化后需要重建
+	lightStamp int           // 生成缓存时的日光等级
+	empty      bool          // 区块内没有方块
+}
+
+// BlockRenderer 方块层渲染器
+//
+// 世界按区块缓存为离屏图像，每个区块只在方块或光照变化时重建一次，
+// 重建时所有方块以DrawTriangles批量提交；每帧只绘制与摄像机视野相交的区块。
+type BlockRenderer struct {
+	chunks map[GridPos]*renderChunk
+	Stats  RenderStats
+}
+
+// NewBlockRenderer 创建新的方块渲染器
+func NewBlockRenderer() *BlockRenderer {
+	return &BlockRenderer{chunks: make(map[GridPos]*renderChunk)}
+}
+
+// chunkOf 返回格子所在的渲染区块坐标
+func chunkOf(x, y int) GridPos {
+	return GridPos{floorDiv(x, ChunkSize), floorDiv(y, ChunkSize)}
+}
+
+// floorDiv 向下取整的整数除法
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// daylightStamp 将日光强度量化为光照等级，等级不变时缓存无需重建
+func daylightStamp(daylight float64) int {
+	return int(math.Round(daylight * MaxLightLevel))
+}
+
+// Invalidate 标记与格子矩形相交的区块缓存需要重建
+func (r *BlockRenderer) Invalidate(area gridRect) {
+	if area.minX > area.maxX || area.minY > area.maxY {
+		return
+	}
+	from, to := chunkOf(area.minX, area.minY), chunkOf(area.maxX, area.maxY)
+	// 缓存数量有限，区域很大时直接遍历缓存
+	if (to.X-from.X+1)*(to.Y-from.Y+1) > len(r.chunks) {
+		for pos, rc := range r.chunks {
+			if pos.X >= from.X && pos.X <= to.X && pos.Y >= from.Y && pos.Y <= to.Y {
+				rc.dirty = true
+			}
+		}
+		return
+	}
+	for cy := from.Y; cy <= to.Y; cy++ {
+		for cx := from.X; cx <= to.X; cx++ {
+			if rc, ok := r.chunks[GridPos{cx, cy}]; ok {
+				rc.dirty = true
+			}
+		}
+	}
+}
+
+// viewChunks 返回与屏幕可见区域相交的区块范围（视锥裁剪）
+func viewChunks(geoM ebiten.GeoM, screenW, screenH float64) gridRect {
+	inv := geoM
+	inv.Invert()
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range [][2]float64{{0, 0}, {screenW, 0}, {0, screenH}, {screenW, screenH}} {
+		x, y := inv.Apply(c[0], c[1])
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	from := chunkOf(int(math.Floor(minX/BlockSize)), int(math.Floor(minY/BlockSize)))
+	to := chunkOf(int(math.Floor(maxX/BlockSize)), int(math.Floor(maxY/BlockSize)))
+	return gridRect{from.X, from.Y, to.X, to.Y}
+}
+
+// prepare 裁剪出可见区块并为需要重建的区块生成顶点数据，不涉及GPU
+func (r *BlockRenderer) prepare(g *Game, view gridRect, daylight float64) []*renderChunk {
+	stamp := daylightStamp(daylight)
+	r.Stats = RenderStats{}
+
+	var visible []*renderChunk
+	for cy := view.minY; cy <= view.maxY; cy++ {
+		for cx := view.minX; cx <= view.maxX; cx++ {
+			pos := GridPos{cx, cy}
+			rc, ok := r.chunks[pos]
+			if !ok {
+				rc = &renderChunk{pos: pos, dirty: true}
+				r.chunks[pos] = rc
+			}
+			if rc.dirty || rc.lightStamp != stamp {
+				rc.mesh = g.buildChunkMesh(pos, float64(stamp)/MaxLightLevel)
+				rc.empty = rc.mesh.batches() == 0
+				rc.dirty = false
+				rc.lightStamp = stamp
+				r.Stats.ChunksBuilt++
+				r.Stats.DrawCalls += rc.mesh.batches()
+			}
+			if rc.empty {
+				continue
+			}
+			visible = append(visible, rc)
+			r.Stats.ChunksVisible++
+			r.Stats.DrawCalls++
+		}
+	}
+
+	// 释放远离视野的缓存
+	keep := view.expand(RenderCacheMargin)
+	for pos, rc := range r.chunks {
+		if !keep.contains(pos) {
+			if rc.image != nil {
+				rc.image.Deallocate()
+			}
+			delete(r.chunks, pos)
+		}
+	}
+	r.Stats.Cached = len(r.chunks)
+	return visible
+}
+
+// Draw 绘制方块层，geoM为世界坐标到屏幕坐标的变换
+func (r *BlockRenderer) Draw(screen *ebiten.Image, g *Game, geoM ebiten.GeoM, daylight float64) {
+	bounds := screen.Bounds()
+	view := viewChunks(geoM, float64(bounds.Dx()), float64(bounds.Dy()))
+	for _, rc := range r.prepare(g, view, daylight) {
+		if rc.mesh != nil {
+			rc.submit(g.atlas)
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(rc.pos.X*RenderChunkPixels), float64(rc.pos.Y*RenderChunkPixels))
+		op.GeoM.Concat(geoM)
+		screen.DrawImage(rc.image, op)
+	}
+}
+
+// submit 将顶点数据批量绘制到区块的离屏图像
+func (rc *renderChunk) submit(atlas *BlockAtlas) {
+	if rc.image == nil {
+		rc.image = ebiten.NewImage(RenderChunkPixels, RenderChunkPixels)
+	} else {
+		rc.image.Clear()
+	}
+	if len(rc.mesh.spriteIdx) > 0 {
+		rc.image.DrawTriangles(rc.mesh.sprites, rc.mesh.spriteIdx, atlas.sheet, nil)
+	}
+	if len(rc.mesh.colorIdx) > 0 {
+		rc.image.DrawTriangles(rc.mesh.colors, rc.mesh.colorIdx, getWhiteImage(), nil)
+	}
+	rc.mesh = nil
+}
+
+// buildChunkMesh 为区块内的每个方块格生成一个四边形：
+// 优先使用贴图，缺少贴图时使用注册表颜色，并按光照调暗
+func (g *Game) buildChunkMesh(chunk GridPos, daylight float64) *chunkMesh {
+	mesh := &chunkMesh{}
+	grid := g.pathGrid()
+	for ly := 0; ly < ChunkSize; ly++ {
+		for lx := 0; lx < ChunkSize; lx++ {
+			p := GridPos{chunk.X*ChunkSize + lx, chunk.Y*ChunkSize + ly}
+			blockType, ok := grid[p]
+			if !ok {
+				continue
+			}
+			brightness := float32(1)
+			if g.light != nil {
+				brightness = float32(g.light.Brightness(p.X, p.Y, daylight))
+			}
+			x, y := float32(lx*BlockSize), float32(ly*BlockSize)
+
+			variant := ""
+			if blockType == ItemTypeGrass {
+				variant = grassVariant(grid, p.X, p.Y)
+			}
+			if src, ok := g.atlas.Sprite(blockType, variant); ok {
+				mesh.sprites, mesh.spriteIdx = appendQuad(mesh.sprites, mesh.spriteIdx, x, y, src,
+					brightness, brightness, brightness, 1)
+				continue
+			}
+
+			// 根据方块类型改变颜色
+			blockColor := color.RGBA{100, 200, 100, 255} // 默认绿色
+			if item, exists := itemRegistry[blockType]; exists {
+				blockColor = item.Color
+			}
+			mesh.colors, mesh.colorIdx = appendQuad(mesh.colors, mesh.colorIdx, x, y, image.Rect(1, 1, 2, 2),
+				brightness*float32(blockColor.R)/255, brightness*float32(blockColor.G)/255,
+				brightness*float32(blockColor.B)/255, float32(blockColor.A)/255)
+		}
+	}
+	return mesh
+}
+
+// appendQuad 追加一个覆盖一格方块的四边形，src为纹理区域
+func appendQuad(vertices []ebiten.Vertex, indices []uint16, x, y float32, src image.Rectangle, r, g, b, a float32) ([]ebiten.Vertex, []uint16) {
+	base := uint16(len(vertices))
+	sx0, sy0 := float32(src.Min.X), float32(src.Min.Y)
+	sx1, sy1 := float32(src.Max.X), float32(src.Max.Y)
+	vertex := func(dx, dy, sx, sy float32) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX: dx, DstY: dy, SrcX: sx, SrcY: sy,
+			ColorR: r, ColorG: g, ColorB: b, ColorA: a,
+		}
+	}
+	vertices = append(vertices,
+		vertex(x, y, sx0, sy0),
+		vertex(x+BlockSize, y, sx1, sy0),
+		vertex(x, y+BlockSize, sx0, sy1),
+		vertex(x+BlockSize, y+BlockSize, sx1, sy1),
+	)
+	indices = append(indices, base, base+1, base+2, base+1, base+2, base+3)
+	return vertices, indices
+}
+
+// drawGridLines 以一次DrawTriangles绘制可见范围内的网格线（帮助观察移动）
+func drawGridLines(screen *ebiten.Image, geoM ebiten.GeoM) {
+	bounds := screen.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	inv := geoM
+	inv.Invert()
+	minX, minY := inv.Apply(0, 0)
+	maxX, maxY := inv.Apply(w, h)
+
+	var vertices []ebiten.Vertex
+	var indices []uint16
+	line := func(x0, y0, x1, y1 float64) {
+		sx0, sy0 := geoM.Apply(x0, y0)
+		sx1, sy1 := geoM.Apply(x1, y1)
+		// 屏幕上1像素宽的矩形
+		if sx0 == sx1 {
+			sx1++
+		} else {
+			sy1++
+		}
+		base := uint16(len(vertices))
+		c := float32(100) / 255
+		for _, p := range [][2]float64{{sx0, sy0}, {sx1, sy0}, {sx0, sy1}, {sx1, sy1}} {
+			vertices = append(vertices, ebiten.Vertex{
+				DstX: float32(p[0]), DstY: float32(p[1]), SrcX: 1, SrcY: 1,
+				ColorR: c, ColorG: c, ColorB: c, ColorA: 1,
+			})
+		}
+		indices = append(indices, base, base+1, base+2, base+1, base+2, base+3)
+	}
+	for x := math.Floor(minX/BlockSize) * BlockSize; x <= maxX; x += BlockSize {
+		line(x, minY, x, maxY)
+	}
+	for y := math.Floor(minY/BlockSize) * BlockSize; y <= maxY; y += BlockSize {
+		line(minX, y, maxX, y)
+	}
+	screen.DrawTriangles(vertices, indices, getWhiteImage(), nil)
+}
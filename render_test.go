@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// newExploredGame 生成一个已探索chunksX列区块的世界，不需要窗口
+func newExploredGame(chunksX int) *Game {
+	g := &Game{
+		chunks:   make(map[string]*Chunk),
+		entities: NewEntityManager(),
+		clock:    NewWorldClock(0),
+	}
+	g.player = g.entities.Spawn(newPlayerEntity(0, 0))
+	g.grid = newBlockGrid(nil)
+	g.light = NewLightEngine(g.grid)
+	g.renderer = NewBlockRenderer()
+	for x := -chunksX / 2; x < chunksX/2; x++ {
+		for y := -3; y <= 3; y++ {
+			g.loadChunk(x, y)
+		}
+	}
+	return g
+}
+
+// cameraAt 返回以世界坐标(x, y)为屏幕中心的变换
+func cameraAt(x, y float64) ebiten.GeoM {
+	var geoM ebiten.GeoM
+	geoM.Translate(ScreenWidth/2-x, ScreenHeight/2-y)
+	return geoM
+}
+
+func TestViewChunks(t *testing.T) {
+	view := viewChunks(cameraAt(0, 0), ScreenWidth, ScreenHeight)
+	want := gridRect{-1, -1, 0, 0}
+	if view != want {
+		t.Fatalf("viewChunks = %+v, want %+v", view, want)
+	}
+}
+
+func TestBlockRendererCachesChunks(t *testing.T) {
+	g := newExploredGame(8)
+	r := g.renderer
+	spawnY := float64(g.terrain().SurfaceHeight(0) * BlockSize)
+	view := viewChunks(cameraAt(0, spawnY), ScreenWidth, ScreenHeight)
+	daylight := g.clock.Daylight()
+
+	r.prepare(g, view, daylight)
+	if r.Stats.ChunksVisible == 0 {
+		t.Fatalf("no visible chunks around spawn")
+	}
+	if r.Stats.DrawCalls > 3*r.Stats.ChunksVisible {
+		t.Errorf("first frame draw calls = %d for %d chunks", r.Stats.DrawCalls, r.Stats.ChunksVisible)
+	}
+
+	// 没有变化时直接使用缓存，每个区块一次绘制
+	r.prepare(g, view, daylight)
+	if r.Stats.ChunksBuilt != 0 {
+		t.Errorf("unchanged frame rebuilt %d chunks", r.Stats.ChunksBuilt)
+	}
+	if r.Stats.DrawCalls != r.Stats.ChunksVisible {
+		t.Errorf("cached frame draw calls = %d, want %d", r.Stats.DrawCalls, r.Stats.ChunksVisible)
+	}
+
+	// 编辑方块后只重建受影响的区块
+	built := len(r.chunks)
+	g.onBlocksChanged([]Block{{0, spawnY - 5*BlockSize, BlockSize, BlockSize, ItemTypeStone}}, nil)
+	r.prepare(g, view, daylight)
+	if r.Stats.ChunksBuilt == 0 || r.Stats.ChunksBuilt > built {
+		t.Errorf("edit rebuilt %d chunks, want 1..%d", r.Stats.ChunksBuilt, built)
+	}
+
+	// 日光等级变化时全部重建
+	r.prepare(g, view, MoonLight)
+	if r.Stats.ChunksBuilt != len(r.chunks) {
+		t.Errorf("daylight change rebuilt %d of %d chunks", r.Stats.ChunksBuilt, len(r.chunks))
+	}
+
+	// 远离的区块缓存被释放
+	far := viewChunks(cameraAt(100*ChunkWorldSize, spawnY), ScreenWidth, ScreenHeight)
+	r.prepare(g, far, daylight)
+	for pos := range r.chunks {
+		if !far.expand(RenderCacheMargin).contains(pos) {
+			t.Fatalf("chunk %v still cached after moving away", pos)
+		}
+	}
+}
+
+// BenchmarkBlockLayerDrawCalls 在大片已探索的世界中平移摄像机，
+// 比较逐方块绘制与按区块缓存批量绘制的绘制调用数
+func BenchmarkBlockLayerDrawCalls(b *testing.B) {
+	g := newExploredGame(64)
+	spawnY := float64(g.terrain().SurfaceHeight(0) * BlockSize)
+	daylight := g.clock.Daylight()
+
+	calls, frames := 0, 0
+	for b.Loop() {
+		x := float64(frames%600-300) * BlockSize / 10
+		view := viewChunks(cameraAt(x, spawnY), ScreenWidth, ScreenHeight)
+		g.renderer.prepare(g, view, daylight)
+		calls += g.renderer.Stats.DrawCalls
+		frames++
+	}
+	b.ReportMetric(float64(calls)/float64(frames), "draws/frame")
+	b.ReportMetric(float64(len(g.blocks)), "naive-draws/frame")
+}
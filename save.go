@@ -0,0 +1,123 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"2d.go/world"
+)
+
+// 存档相关常量
+const (
+	WorldSavePath    = "saves/world.json" // 默认存档路径
+	WorldSaveVersion = 2                  // 存档格式版本（2：加入探索过的地图）
+)
+
+// WorldSave 定义写入磁盘的世界状态
+type WorldSave struct {
+	Version   int     `json:"version"`
+	Tick      int64   `json:"tick"`       // 世界时钟总帧数
+	DayLength int64   `json:"day_length"` // 一天的帧数
+	PlayerX   float64 `json:"player_x"`
+	PlayerY   float64 `json:"player_y"`
+	World     string  `json:"world,omitempty"` // 世界类型（见world.NewChunkGenerator），空为默认地形
+
+	Map []MapTileSave `json:"map,omitempty"` // 探索过的区块的地图贴图
+}
+
+// saveWorld 将世界状态写入指定文件
+func (g *Game) saveWorld(path string) error {
+	save := WorldSave{
+		Version:   WorldSaveVersion,
+		Tick:      g.clock.Tick,
+		DayLength: g.clock.DayLength,
+		PlayerX:   g.player.X,
+		PlayerY:   g.player.Y,
+		World:     g.worldType,
+	}
+	if g.worldMap != nil {
+		save.Map = g.worldMap.SavedTiles(g.pathGrid())
+	}
+	data, err := json.MarshalIndent(save, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// loadWorldSave 读取存档文件
+func loadWorldSave(path string) (*WorldSave, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var save WorldSave
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+	if save.Version > WorldSaveVersion {
+		return nil, fmt.Errorf("unsupported world save version %d", save.Version)
+	}
+	return &save, nil
+}
+
+// openWorldSave 读取g.savePath的存档并使用其中的世界类型，没有存档时返回nil
+//
+// 存档损坏或来自更新的版本时返回错误而不是开始新世界，避免保存时覆盖原来的存档。
+func (g *Game) openWorldSave() (*WorldSave, error) {
+	if g.savePath == "" {
+		return nil, nil
+	}
+	save, err := loadWorldSave(g.savePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load world save %s: %w (use -save to start a new world)", g.savePath, err)
+	}
+	if err := g.useSavedWorldType(save); err != nil {
+		return nil, err
+	}
+	return save, nil
+}
+
+// useSavedWorldType 使用存档的世界类型（没有记录时为默认地形）
+//
+// 已有的世界不能改变类型，命令行显式指定了不同的类型时返回错误。
+func (g *Game) useSavedWorldType(save *WorldSave) error {
+	saved := cmp.Or(save.World, world.WorldDefault)
+	if g.worldTypeSet && cmp.Or(g.worldType, world.WorldDefault) != saved {
+		return fmt.Errorf("-world %s conflicts with the %s world in the save %s (use -save to start a new world)", g.worldType, saved, g.savePath)
+	}
+	g.worldType = saved
+	return nil
+}
+
+// applyWorldSave 将存档中的状态应用到游戏，命令行显式指定的一天长度优先于存档
+func (g *Game) applyWorldSave(save *WorldSave) {
+	switch {
+	case save.DayLength <= 0:
+		g.clock.Tick = save.Tick
+	case g.dayLengthSet && save.DayLength != g.clock.DayLength:
+		// 显式指定的-daylength优先，保持存档中的天数和时刻
+		log.Printf("-daylength %d overrides the day length %d stored in the save", g.clock.DayLength, save.DayLength)
+		saved := &WorldClock{Tick: save.Tick, DayLength: save.DayLength}
+		g.clock.Tick = (saved.Day() - 1) * g.clock.DayLength
+		g.clock.SetTimeOfDay(saved.TimeOfDay())
+	default:
+		g.clock.DayLength = save.DayLength
+		g.clock.Tick = save.Tick
+	}
+	g.player.X = save.PlayerX
+	g.player.Y = save.PlayerY
+	if g.worldMap != nil {
+		g.worldMap.LoadTiles(save.Map)
+	}
+}
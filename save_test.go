@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSaveGame 创建只有时钟和玩家的游戏，用于存档测试
+func newSaveGame(dayLength int64) *Game {
+	g := &Game{entities: NewEntityManager(), clock: NewWorldClock(dayLength)}
+	g.player = g.entities.Spawn(newPlayerEntity(0, 0))
+	return g
+}
+
+func TestWorldSaveRoundTrip(t *testing.T) {
+	g := newSaveGame(1000)
+	g.worldType = "flat"
+	g.clock.Tick = 12345
+	g.player.X, g.player.Y = 250, -75.5
+	path := filepath.Join(t.TempDir(), "saves", "world.json")
+	if err := g.saveWorld(path); err != nil {
+		t.Fatal(err)
+	}
+
+	save, err := loadWorldSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if save.Version != WorldSaveVersion || save.World != "flat" {
+		t.Errorf("save version %d, world %q", save.Version, save.World)
+	}
+	loaded := newSaveGame(0)
+	loaded.applyWorldSave(save)
+	if loaded.clock.Tick != 12345 || loaded.clock.DayLength != 1000 {
+		t.Errorf("clock after load: tick %d, day length %d", loaded.clock.Tick, loaded.clock.DayLength)
+	}
+	if loaded.player.X != 250 || loaded.player.Y != -75.5 {
+		t.Errorf("player after load at (%v, %v)", loaded.player.X, loaded.player.Y)
+	}
+}
+
+func TestLoadWorldSaveErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadWorldSave(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("missing save: error = %v, want not exist", err)
+	}
+	newer := filepath.Join(dir, "newer.json")
+	if err := os.WriteFile(newer, []byte(`{"version": 99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadWorldSave(newer); err == nil {
+		t.Error("loading a save from a newer version succeeded")
+	}
+}
+
+func TestBadSaveIsNotOverwritten(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"corrupt.json": `{"version": 2, "tick": `,
+		"newer.json":   `{"version": 99}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// 无法读取的存档不能开始新世界，否则保存时会被覆盖
+		g := &Game{savePath: path}
+		if err := g.Update(); err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: Update error = %v, want a load error", name, err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != data {
+			t.Errorf("%s: save overwritten with %q", name, got)
+		}
+	}
+
+	// 没有存档时开始新世界
+	g := &Game{savePath: filepath.Join(dir, "missing.json")}
+	if save, err := g.openWorldSave(); save != nil || err != nil {
+		t.Errorf("missing save: %v, %v, want a new world", save, err)
+	}
+}
+
+func TestSavedDayLength(t *testing.T) {
+	// 存档记录第3天18:00，一天1000帧
+	save := &WorldSave{Tick: 2750, DayLength: 1000}
+
+	// 没有显式指定-daylength时使用存档中的长度
+	g := newSaveGame(500)
+	g.applyWorldSave(save)
+	if g.clock.DayLength != 1000 || g.clock.Tick != 2750 {
+		t.Errorf("day length %d, tick %d, want the saved 1000 and 2750", g.clock.DayLength, g.clock.Tick)
+	}
+
+	// 显式指定时命令行优先，天数和时刻保持不变
+	g = newSaveGame(500)
+	g.dayLengthSet = true
+	g.applyWorldSave(save)
+	if g.clock.DayLength != 500 || g.clock.String() != "Day 3 18:00" {
+		t.Errorf("day length %d at %s, want 500 at Day 3 18:00", g.clock.DayLength, g.clock)
+	}
+}
+
+func TestUseSavedWorldType(t *testing.T) {
+	tests := []struct {
+		flag    string
+		set     bool
+		saved   string
+		want    string
+		wantErr bool
+	}{
+		{flag: "default", saved: "flat", want: "flat"},
+		{flag: "default", saved: "", want: "default"},
+		{flag: "flat", set: true, saved: "flat", want: "flat"},
+		{flag: "default", set: true, saved: "", want: "default"},
+		{flag: "flat", set: true, saved: "skyblock", wantErr: true},
+		{flag: "flat", set: true, saved: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		g := &Game{worldType: tt.flag, worldTypeSet: tt.set, savePath: "world.json"}
+		err := g.useSavedWorldType(&WorldSave{World: tt.saved})
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "conflicts") {
+				t.Errorf("-world %s (set: %v) with saved %q: error = %v, want a conflict", tt.flag, tt.set, tt.saved, err)
+			}
+			continue
+		}
+		if err != nil || g.worldType != tt.want {
+			t.Errorf("-world %s (set: %v) with saved %q: world type = %q, %v, want %q", tt.flag, tt.set, tt.saved, g.worldType, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"2d.go/command"
+	"2d.go/world"
+)
+
+// 结构文件相关常量
+const (
+	SchematicVersion = 1                  // 结构文件格式版本
+	SchematicDir     = "saves/schematics" // 结构文件的默认目录
+	SchematicExt     = ".json"
+	SchematicAir     = -1 // 方块网格中表示空气的调色板索引
+)
+
+// Schematic 可保存和分享的方块结构
+//
+// 方块网格按行存储（从上到下、从左到右），每格是Palette的下标，
+// Palette按物品名称记录方块类型，不依赖ItemType的数值。
+type Schematic struct {
+	Version  int               `json:"version"`
+	Width    int               `json:"width"`
+	Height   int               `json:"height"`
+	Palette  []string          `json:"palette"`
+	Blocks   []int             `json:"blocks"`
+	Metadata map[string]string `json:"metadata,omitempty"` // 名称、作者、描述等可选信息
+}
+
+// legacyClipboardFile 版本1之前的剪贴板导出格式（逐个列出方块）
+type legacyClipboardFile struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Blocks []struct {
+		X    int    `json:"x"`
+		Y    int    `json:"y"`
+		Type string `json:"type"`
+	} `json:"blocks"`
+}
+
+// NewSchematic 由剪贴板创建结构
+func NewSchematic(c *Clipboard, metadata map[string]string) *Schematic {
+	s := &Schematic{
+		Version:  SchematicVersion,
+		Width:    c.W,
+		Height:   c.H,
+		Blocks:   make([]int, c.W*c.H),
+		Metadata: metadata,
+	}
+	// 调色板按名称排序，使相同的结构生成相同的文件
+	index := make(map[ItemType]int)
+	for _, t := range c.Blocks {
+		index[t] = 0
+	}
+	for t := range index {
+		s.Palette = append(s.Palette, itemRegistry[t].Name)
+	}
+	sort.Strings(s.Palette)
+	for i, name := range s.Palette {
+		t, _ := world.ItemTypeByName(name)
+		index[t] = i
+	}
+	for i := range s.Blocks {
+		s.Blocks[i] = SchematicAir
+	}
+	for p, t := range c.Blocks {
+		s.Blocks[p.Y*c.W+p.X] = index[t]
+	}
+	return s
+}
+
+// Clipboard 将结构转换为剪贴板（用于预览和粘贴）
+func (s *Schematic) Clipboard() (*Clipboard, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	types := make([]ItemType, len(s.Palette))
+	for i, name := range s.Palette {
+		t, ok := world.ItemTypeByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown block type %q in palette", name)
+		}
+		types[i] = t
+	}
+	c := &Clipboard{W: s.Width, H: s.Height, Blocks: make(map[GridPos]ItemType)}
+	for i, v := range s.Blocks {
+		if v != SchematicAir {
+			c.Blocks[GridPos{i % s.Width, i / s.Width}] = types[v]
+		}
+	}
+	return c, nil
+}
+
+// validate 检查尺寸、网格长度和调色板下标
+func (s *Schematic) validate() error {
+	if s.Version < 1 || s.Version > SchematicVersion {
+		return fmt.Errorf("unsupported schematic version %d", s.Version)
+	}
+	if s.Width <= 0 || s.Height <= 0 {
+		return fmt.Errorf("invalid schematic size %dx%d", s.Width, s.Height)
+	}
+	if len(s.Blocks) != s.Width*s.Height {
+		return fmt.Errorf("schematic has %d cells, want %dx%d", len(s.Blocks), s.Width, s.Height)
+	}
+	for i, v := range s.Blocks {
+		if v != SchematicAir && (v < 0 || v >= len(s.Palette)) {
+			return fmt.Errorf("cell %d uses palette index %d, palette has %d entries", i, v, len(s.Palette))
+		}
+	}
+	return nil
+}
+
+// WriteSchematic 以JSON写出结构
+func WriteSchematic(w io.Writer, s *Schematic) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// ReadSchematic 读取并校验结构，没有版本号的旧剪贴板文件会被转换为当前格式
+func ReadSchematic(r io.Reader) (*Schematic, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	if header.Version == nil {
+		return migrateLegacyClipboard(data)
+	}
+
+	var s Schematic
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// migrateLegacyClipboard 将旧剪贴板导出格式转换为结构
+func migrateLegacyClipboard(data []byte) (*Schematic, error) {
+	var file legacyClipboardFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if file.Width <= 0 || file.Height <= 0 {
+		return nil, fmt.Errorf("invalid clipboard size %dx%d", file.Width, file.Height)
+	}
+	c := &Clipboard{W: file.Width, H: file.Height, Blocks: make(map[GridPos]ItemType)}
+	for _, cell := range file.Blocks {
+		t, ok := world.ItemTypeByName(cell.Type)
+		if !ok {
+			return nil, fmt.Errorf("unknown block type %q", cell.Type)
+		}
+		if cell.X < 0 || cell.X >= c.W || cell.Y < 0 || cell.Y >= c.H {
+			return nil, fmt.Errorf("block (%d, %d) outside %dx%d clipboard", cell.X, cell.Y, c.W, c.H)
+		}
+		c.Blocks[GridPos{cell.X, cell.Y}] = t
+	}
+	return NewSchematic(c, nil), nil
+}
+
+// SaveSchematic 将结构写入文件
+func SaveSchematic(path string, s *Schematic) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteSchematic(f, s); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadSchematic 从文件读取结构
+func LoadSchematic(path string) (*Schematic, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := ReadSchematic(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// schematicPath 返回结构文件的路径（名称不含目录时放在dir下，没有扩展名时补上）
+func schematicPath(dir, name string) string {
+	if filepath.Ext(name) == "" {
+		name += SchematicExt
+	}
+	if filepath.Base(name) == name {
+		return filepath.Join(dir, name)
+	}
+	return name
+}
+
+// listSchematics 返回结构库中的结构名称
+func listSchematics(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+SchematicExt))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = strings.TrimSuffix(filepath.Base(m), SchematicExt)
+	}
+	return names, nil
+}
+
+// placeSchematic 将结构放置到以at为左上角的位置，作为一步撤销历史
+func (g *Game) placeSchematic(s *Schematic, at GridPos) error {
+	c, err := s.Clipboard()
+	if err != nil {
+		return err
+	}
+	g.pasteBlocks(c, at)
+	return nil
+}
+
+// cmdSchem 结构库命令：从选区保存、加载到剪贴板放置、在光标处直接放置、列出和查看
+func cmdSchem(g *Game, args []string) (string, error) {
+	if len(args) == 1 && args[0] == "list" {
+		names, err := listSchematics(SchematicDir)
+		if err != nil {
+			return "", err
+		}
+		if len(names) == 0 {
+			return "no schematics in " + SchematicDir, nil
+		}
+		return strings.Join(names, " "), nil
+	}
+	if len(args) < 2 {
+		return "", command.ErrUsage
+	}
+	name, path := args[1], schematicPath(SchematicDir, args[1])
+
+	switch args[0] {
+	case "save":
+		if !g.hasSelection {
+			return "", fmt.Errorf("no selection (use the Select tool)")
+		}
+		metadata := map[string]string{
+			"name":    name,
+			"created": time.Now().UTC().Format(time.RFC3339),
+		}
+		if len(args) > 2 {
+			metadata["description"] = strings.Join(args[2:], " ")
+		}
+		s := NewSchematic(copyRegion(g.pathGrid(), g.selection), metadata)
+		if err := SaveSchematic(path, s); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("saved %dx%d schematic to %s", s.Width, s.Height, path), nil
+	case "load", "place", "info":
+		if len(args) != 2 {
+			return "", command.ErrUsage
+		}
+		s, err := LoadSchematic(path)
+		if err != nil {
+			return "", err
+		}
+		if args[0] == "place" {
+			if g.gameMode != GameModeCreative {
+				return "", fmt.Errorf("schematics can only be placed in creative mode")
+			}
+			at := g.pasteOrigin()
+			if err := g.placeSchematic(s, at); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("placed %s at (%d, %d)", name, at.X, at.Y), nil
+		}
+		c, err := s.Clipboard()
+		if err != nil {
+			return "", err
+		}
+		switch args[0] {
+		case "load":
+			g.clipboard, g.pasting = c, g.gameMode == GameModeCreative
+			return fmt.Sprintf("loaded %dx%d schematic %s into the clipboard", c.W, c.H, name), nil
+		default:
+			info := fmt.Sprintf("%s: v%d %dx%d, %d blocks, palette %s", name, s.Version, c.W, c.H, len(c.Blocks), strings.Join(s.Palette, ","))
+			if desc := s.Metadata["description"]; desc != "" {
+				info += " - " + desc
+			}
+			return info, nil
+		}
+	default:
+		return "", command.ErrUsage
+	}
+}
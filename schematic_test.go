@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// equalClipboards 比较两个剪贴板的尺寸和方块
+func equalClipboards(a, b *Clipboard) bool {
+	if a.W != b.W || a.H != b.H || len(a.Blocks) != len(b.Blocks) {
+		return false
+	}
+	for p, t := range a.Blocks {
+		if b.Blocks[p] != t {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSchematicRoundTrip(t *testing.T) {
+	c := lShape()
+	meta := map[string]string{"name": "l", "author": "test", "description": "an L"}
+	s := NewSchematic(c, meta)
+	if s.Version != SchematicVersion || len(s.Palette) != 2 || len(s.Blocks) != 6 {
+		t.Fatalf("NewSchematic = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSchematic(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.String()
+	read, err := ReadSchematic(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := read.Clipboard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalClipboards(got, c) {
+		t.Fatalf("round trip = %+v, want %+v", got, c)
+	}
+	for k, v := range meta {
+		if read.Metadata[k] != v {
+			t.Errorf("metadata %q = %q, want %q", k, read.Metadata[k], v)
+		}
+	}
+
+	// 相同的结构编码结果相同（调色板顺序稳定）
+	buf.Reset()
+	WriteSchematic(&buf, NewSchematic(got, meta))
+	if buf.String() != encoded {
+		t.Fatalf("re-encoding changed the file:\n%s\nwant\n%s", buf.String(), encoded)
+	}
+}
+
+func TestSchematicFile(t *testing.T) {
+	path := schematicPath(t.TempDir(), "house")
+	if filepath.Ext(path) != SchematicExt {
+		t.Fatalf("schematicPath = %q, want %s extension", path, SchematicExt)
+	}
+	if err := SaveSchematic(path, NewSchematic(lShape(), nil)); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadSchematic(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := s.Clipboard()
+	if !equalClipboards(c, lShape()) {
+		t.Fatalf("loaded %+v", c)
+	}
+	names, err := listSchematics(filepath.Dir(path))
+	if err != nil || len(names) != 1 || names[0] != "house" {
+		t.Fatalf("listSchematics = %v, %v", names, err)
+	}
+}
+
+func TestReadSchematicErrors(t *testing.T) {
+	tests := map[string]string{
+		"future version": `{"version": 99, "width": 1, "height": 1, "palette": ["Stone"], "blocks": [0]}`,
+		"bad size":       `{"version": 1, "width": 0, "height": 1, "palette": [], "blocks": []}`,
+		"short grid":     `{"version": 1, "width": 2, "height": 2, "palette": ["Stone"], "blocks": [0, 0]}`,
+		"bad index":      `{"version": 1, "width": 1, "height": 1, "palette": ["Stone"], "blocks": [1]}`,
+		"not json":       `schematic`,
+	}
+	for name, data := range tests {
+		if _, err := ReadSchematic(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: ReadSchematic succeeded, want error", name)
+		}
+	}
+
+	// 未知方块名称在转换为剪贴板时报错
+	s, err := ReadSchematic(strings.NewReader(`{"version": 1, "width": 1, "height": 1, "palette": ["Diamond"], "blocks": [0]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Clipboard(); err == nil {
+		t.Fatal("Clipboard with unknown palette entry succeeded, want error")
+	}
+}
+
+func TestReadLegacyClipboard(t *testing.T) {
+	legacy := `{"width": 2, "height": 1, "blocks": [{"x": 1, "y": 0, "type": "sand"}]}`
+	s, err := ReadSchematic(strings.NewReader(legacy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != SchematicVersion {
+		t.Fatalf("migrated version = %d, want %d", s.Version, SchematicVersion)
+	}
+	c, err := s.Clipboard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.W != 2 || c.H != 1 || len(c.Blocks) != 1 || c.Blocks[GridPos{1, 0}] != ItemTypeSand {
+		t.Fatalf("migrated clipboard = %+v", c)
+	}
+}
+
+func TestSchemPlaceCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := SaveSchematic(schematicPath(SchematicDir, "l"), NewSchematic(lShape(), nil)); err != nil {
+		t.Fatal(err)
+	}
+	g := newToolGame(nil, GridPos{}, GridPos{})
+	g.history = NewEditHistory(0)
+	g.camera = NewCamera(0, 0)
+
+	// 结构放置在光标处
+	at := g.pasteOrigin()
+	if _, err := cmdSchem(g, []string{"place", "l"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.blocks) != 4 || g.grid[GridPos{at.X + 2, at.Y + 1}] != ItemTypeDirt || g.grid[at] != ItemTypeStone {
+		t.Fatalf("placed blocks %+v at %v", g.blocks, at)
+	}
+	g.undo()
+	if len(g.blocks) != 0 {
+		t.Fatal("placing a schematic should be a single undo step")
+	}
+
+	g.gameMode = GameModeSurvival
+	if _, err := cmdSchem(g, []string{"place", "l"}); err == nil {
+		t.Error("placing a schematic in survival mode succeeded")
+	}
+	if _, err := cmdSchem(g, []string{"place", "missing"}); err == nil {
+		t.Error("placing a missing schematic succeeded")
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+
+	"2d.go/world"
+)
+
+// 消息类型
+//
+// 客户端发送input、place和break，服务器发送其余类型。
+const (
+	MsgInput   = "input"   // 客户端当前按下的按键
+	MsgPlace   = "place"   // 在格子(X, Y)放置方块Block
+	MsgBreak   = "break"   // 破坏格子(X, Y)的方块
+	MsgWelcome = "welcome" // 连接成功，ID为客户端的玩家编号
+	MsgChunk   = "chunk"   // 区块(ChunkX, ChunkY)的全部方块
+	MsgUnload  = "unload"  // 区块(ChunkX, ChunkY)已离开玩家附近，客户端可以丢弃
+	MsgBlock   = "block"   // 格子(X, Y)变为Block，Removed为true时变为空
+	MsgPlayers = "players" // 每个tick的所有玩家位置
+	MsgError   = "error"   // 请求被拒绝的原因
+)
+
+// Message 服务器与客户端之间的消息，每条消息编码为一行JSON
+//
+// 只有与Type相关的字段有意义。
+type Message struct {
+	Type string `json:"type"`
+
+	Left  bool `json:"left,omitempty"`
+	Right bool `json:"right,omitempty"`
+	Jump  bool `json:"jump,omitempty"`
+
+	X       int            `json:"x,omitempty"`
+	Y       int            `json:"y,omitempty"`
+	Block   world.ItemType `json:"block,omitempty"`
+	Removed bool           `json:"removed,omitempty"`
+
+	ID      int           `json:"id,omitempty"`
+	Tick    int64         `json:"tick,omitempty"`
+	ChunkX  int           `json:"chunk_x,omitempty"`
+	ChunkY  int           `json:"chunk_y,omitempty"`
+	Cells   []Cell        `json:"cells,omitempty"`
+	Players []PlayerState `json:"players,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Cell 区块中的一个方块格子
+type Cell struct {
+	X     int            `json:"x"`
+	Y     int            `json:"y"`
+	Block world.ItemType `json:"block"`
+}
+
+// PlayerState 玩家的位置（像素坐标，与游戏中的实体坐标相同）
+type PlayerState struct {
+	ID int     `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
+}
+
+// Client 连接到服务器的客户端
+//
+// Send和Receive可以在不同的goroutine中同时调用，但各自不能并发调用。
+type Client struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial 连接到addr上的服务器
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(conn), nil
+}
+
+// newClient 在已建立的连接上创建客户端
+func newClient(conn net.Conn) *Client {
+	return &Client{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(bufio.NewReader(conn))}
+}
+
+// Send 发送一条消息
+func (c *Client) Send(msg Message) error {
+	return c.enc.Encode(msg)
+}
+
+// Receive 阻塞直到收到下一条消息
+func (c *Client) Receive() (Message, error) {
+	var msg Message
+	err := c.dec.Decode(&msg)
+	return msg, err
+}
+
+// Conn 返回底层连接（例如用于设置读取超时）
+func (c *Client) Conn() net.Conn {
+	return c.conn
+}
+
+// Close 断开连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
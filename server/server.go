@@ -0,0 +1,356 @@
+// Package server 实现本地多人游戏的服务器：服务器拥有权威的世界状态，
+// 以固定的tick运行模拟，并通过TCP与客户端交换按行分隔的JSON消息（见Message）。
+//
+// 客户端发送按键输入和方块修改，服务器向每个客户端发送其玩家附近的区块、
+// 这些区块中的方块变化以及每个tick的所有玩家位置。
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"2d.go/region"
+	"2d.go/world"
+)
+
+// 服务器参数
+const (
+	DefaultTickRate = 60   // 默认每秒tick数，与游戏相同
+	eventBuffer     = 1024 // 等待tick处理的客户端事件数
+	sendBuffer      = 1024 // 每个客户端等待发送的消息数，超过时断开该客户端
+)
+
+// Config 服务器配置
+type Config struct {
+	World    string        // 世界类型（见world.NewChunkGenerator），空为默认地形
+	Seed     int64         // 地形种子
+	TickRate int           // 每秒tick数，为0时使用DefaultTickRate
+	Limits   region.Limits // 区块生成范围，为零值时使用region.LimitsFor
+}
+
+// Server 多人游戏服务器
+type Server struct {
+	tickRate int
+	state    *state
+	events   chan event
+
+	// 以下字段只在tick goroutine中访问
+	clients map[int]*conn
+	nextID  int
+}
+
+// conn 服务器上的一个客户端连接
+type conn struct {
+	id     int
+	client *Client
+	out    chan Message
+	chunks map[world.GridPos]bool // 已发送给客户端的区块
+}
+
+// event 读取goroutine交给tick goroutine处理的事件
+type event struct {
+	c    *conn
+	kind eventKind
+	msg  Message
+}
+
+// eventKind 事件类型
+type eventKind int
+
+const (
+	eventJoin eventKind = iota
+	eventLeave
+	eventMessage
+)
+
+// New 按配置创建服务器
+func New(cfg Config) (*Server, error) {
+	gen, err := world.NewChunkGenerator(cfg.World, cfg.Seed)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TickRate <= 0 {
+		cfg.TickRate = DefaultTickRate
+	}
+	if cfg.Limits == (region.Limits{}) {
+		cfg.Limits = region.LimitsFor(gen)
+	}
+	return &Server{
+		tickRate: cfg.TickRate,
+		state:    newState(gen, cfg.Limits),
+		events:   make(chan event, eventBuffer),
+		clients:  make(map[int]*conn),
+		nextID:   1,
+	}, nil
+}
+
+// Serve 接受l上的连接并运行模拟，直到ctx取消
+//
+// 返回前关闭l和所有客户端连接。
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	acceptErr := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		acceptErr <- s.accept(ctx, l, &wg)
+	}()
+
+	ticker := time.NewTicker(time.Second / time.Duration(s.tickRate))
+	defer ticker.Stop()
+	var err error
+	accepting := true
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case err = <-acceptErr:
+			accepting = false
+			break loop
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+
+	cancel()
+	l.Close()
+	if accepting {
+		err = <-acceptErr
+	}
+	s.shutdown()
+	wg.Wait()
+	return err
+}
+
+// shutdown 断开所有客户端，包括尚未处理加入事件的客户端
+func (s *Server) shutdown() {
+	for {
+		select {
+		case e := <-s.events:
+			if e.kind == eventJoin {
+				close(e.c.out)
+				e.c.client.Close()
+			}
+			continue
+		default:
+		}
+		break
+	}
+	for _, c := range s.clients {
+		s.drop(c)
+	}
+}
+
+// accept 接受连接，为每个连接启动读取和发送goroutine
+func (s *Server) accept(ctx context.Context, l net.Listener, wg *sync.WaitGroup) error {
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		c := &conn{client: newClient(nc), out: make(chan Message, sendBuffer), chunks: make(map[world.GridPos]bool)}
+		if !s.post(ctx, event{c: c, kind: eventJoin}) {
+			nc.Close()
+			return nil
+		}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.read(ctx, c)
+		}()
+		go func() {
+			defer wg.Done()
+			write(c)
+		}()
+	}
+}
+
+// post 把事件交给tick goroutine，ctx取消时返回false
+func (s *Server) post(ctx context.Context, e event) bool {
+	select {
+	case s.events <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// read 读取客户端消息直到连接断开
+func (s *Server) read(ctx context.Context, c *conn) {
+	for {
+		msg, err := c.client.Receive()
+		if err != nil {
+			s.post(ctx, event{c: c, kind: eventLeave})
+			return
+		}
+		if !s.post(ctx, event{c: c, kind: eventMessage, msg: msg}) {
+			return
+		}
+	}
+}
+
+// write 发送客户端的消息队列直到队列关闭
+func write(c *conn) {
+	for msg := range c.out {
+		if err := c.client.Send(msg); err != nil {
+			c.client.Close()
+			for range c.out {
+			}
+			return
+		}
+	}
+}
+
+// tick 处理等待中的事件，推进一个tick，并向客户端同步区块和玩家位置
+func (s *Server) tick() {
+	for {
+		select {
+		case e := <-s.events:
+			s.handle(e)
+			continue
+		default:
+		}
+		break
+	}
+	s.state.step()
+	for _, id := range s.clientIDs() {
+		s.syncChunks(s.clients[id])
+	}
+	players := Message{Type: MsgPlayers, Tick: s.state.tick, Players: s.state.playerStates()}
+	for _, id := range s.clientIDs() {
+		s.send(s.clients[id], players)
+	}
+}
+
+// handle 处理一个客户端事件
+func (s *Server) handle(e event) {
+	c := e.c
+	switch e.kind {
+	case eventJoin:
+		c.id = s.nextID
+		s.nextID++
+		s.clients[c.id] = c
+		s.state.spawn(c.id)
+		s.send(c, Message{Type: MsgWelcome, ID: c.id, Tick: s.state.tick})
+		return
+	case eventLeave:
+		if s.clients[c.id] == c {
+			s.drop(c)
+		}
+		return
+	}
+	if s.clients[c.id] != c {
+		return
+	}
+
+	msg := e.msg
+	p := s.state.players[c.id]
+	switch msg.Type {
+	case MsgInput:
+		p.left, p.right, p.jump = msg.Left, msg.Right, msg.Jump
+	case MsgPlace:
+		if err := s.state.setBlock(msg.X, msg.Y, msg.Block); err != nil {
+			s.send(c, Message{Type: MsgError, Error: err.Error()})
+			return
+		}
+		s.broadcastBlock(Message{Type: MsgBlock, X: msg.X, Y: msg.Y, Block: msg.Block})
+	case MsgBreak:
+		if err := s.state.breakBlock(msg.X, msg.Y); err != nil {
+			s.send(c, Message{Type: MsgError, Error: err.Error()})
+			return
+		}
+		s.broadcastBlock(Message{Type: MsgBlock, X: msg.X, Y: msg.Y, Removed: true})
+	default:
+		s.send(c, Message{Type: MsgError, Error: "unknown message type " + msg.Type})
+	}
+}
+
+// broadcastBlock 把方块变化发送给已收到该区块的客户端
+func (s *Server) broadcastBlock(msg Message) {
+	chunk := world.ChunkOf(msg.X, msg.Y)
+	for _, id := range s.clientIDs() {
+		if c := s.clients[id]; c.chunks[chunk] {
+			s.send(c, msg)
+		}
+	}
+}
+
+// syncChunks 向客户端发送其玩家附近新生成的区块，并通知离开附近的区块
+func (s *Server) syncChunks(c *conn) {
+	limits := s.state.region.Limits()
+	cell := s.state.players[c.id].cell()
+	center := world.ChunkOf(cell.X, cell.Y)
+
+	var gone []world.GridPos
+	for p := range c.chunks {
+		if !limits.Near(p, center) {
+			gone = append(gone, p)
+		}
+	}
+	sort.Slice(gone, func(i, j int) bool {
+		if gone[i].X != gone[j].X {
+			return gone[i].X < gone[j].X
+		}
+		return gone[i].Y < gone[j].Y
+	})
+	for _, p := range gone {
+		delete(c.chunks, p)
+		s.send(c, Message{Type: MsgUnload, ChunkX: p.X, ChunkY: p.Y})
+	}
+
+	for x := center.X - limits.RadiusX; x <= center.X+limits.RadiusX; x++ {
+		for y := center.Y - limits.RadiusY; y <= center.Y+limits.RadiusY; y++ {
+			p := world.GridPos{X: x, Y: y}
+			if c.chunks[p] {
+				continue
+			}
+			cells, ok := s.state.chunkCells(x, y)
+			if !ok {
+				continue
+			}
+			c.chunks[p] = true
+			s.send(c, Message{Type: MsgChunk, ChunkX: x, ChunkY: y, Cells: cells})
+		}
+	}
+}
+
+// send 把消息放入客户端的发送队列，队列已满时断开该客户端
+func (s *Server) send(c *conn, msg Message) {
+	if s.clients[c.id] != c {
+		return
+	}
+	select {
+	case c.out <- msg:
+	default:
+		s.drop(c)
+	}
+}
+
+// drop 移除客户端及其玩家并关闭连接
+func (s *Server) drop(c *conn) {
+	delete(s.clients, c.id)
+	delete(s.state.players, c.id)
+	close(c.out)
+	c.client.Close()
+}
+
+// clientIDs 返回按编号排序的客户端编号
+func (s *Server) clientIDs() []int {
+	ids := make([]int, 0, len(s.clients))
+	for id := range s.clients {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
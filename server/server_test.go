@@ -0,0 +1,263 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"2d.go/region"
+	"2d.go/world"
+)
+
+// testLimits 测试用的小范围区块限制，覆盖虚空世界出生点的平台
+var testLimits = region.Limits{MinChunkY: -3, MaxChunkY: 2, RadiusX: 1, RadiusY: 1}
+
+// startServer 在回环地址上启动虚空世界的服务器，返回监听地址
+func startServer(t *testing.T) string {
+	t.Helper()
+	return startWorldServer(t, Config{World: world.WorldVoid, TickRate: 200, Limits: testLimits})
+}
+
+// startWorldServer 在回环地址上按配置启动服务器，返回监听地址
+func startWorldServer(t *testing.T, cfg Config) string {
+	t.Helper()
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, l) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return l.Addr().String()
+}
+
+// join 连接到服务器并等待welcome消息，返回客户端和玩家编号
+func join(t *testing.T, addr string) (*Client, int) {
+	t.Helper()
+	c, err := Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	msg := waitFor(t, c, func(m Message) bool { return m.Type == MsgWelcome })
+	return c, msg.ID
+}
+
+// waitFor 读取消息直到pred返回true，超时则测试失败
+func waitFor(t *testing.T, c *Client, pred func(Message) bool) Message {
+	t.Helper()
+	c.Conn().SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		msg, err := c.Receive()
+		if err != nil {
+			t.Fatalf("waiting for message: %v", err)
+		}
+		if pred(msg) {
+			return msg
+		}
+	}
+}
+
+// hasCell 判断区块消息中是否有指定的方块
+func hasCell(m Message, x, y int, t world.ItemType) bool {
+	for _, c := range m.Cells {
+		if c.X == x && c.Y == y && c.Block == t {
+			return true
+		}
+	}
+	return false
+}
+
+// spawnChunkY 虚空世界出生点平台所在的区块行
+var spawnChunkY = world.ChunkOf(0, world.FlatSurface).Y
+
+// isSpawnChunk 判断消息是否为出生点平台右半部分所在的区块
+func isSpawnChunk(m Message) bool {
+	return m.Type == MsgChunk && m.ChunkX == 0 && m.ChunkY == spawnChunkY
+}
+
+// playerOf 返回players消息中指定玩家的位置
+func playerOf(m Message, id int) (PlayerState, bool) {
+	for _, p := range m.Players {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return PlayerState{}, false
+}
+
+func TestJoinReceivesChunks(t *testing.T) {
+	addr := startServer(t)
+	c, id := join(t, addr)
+	if id != 1 {
+		t.Errorf("first player id = %d, want 1", id)
+	}
+	chunk := waitFor(t, c, isSpawnChunk)
+	if !hasCell(chunk, 0, world.FlatSurface, world.ItemTypeStone) || !hasCell(chunk, 1, world.FlatSurface, world.ItemTypeStone) {
+		t.Errorf("spawn chunk cells = %v, want the platform", chunk.Cells)
+	}
+}
+
+func TestPlayersMoveAndSeeEachOther(t *testing.T) {
+	addr := startServer(t)
+	a, idA := join(t, addr)
+	b, idB := join(t, addr)
+	if idA == idB {
+		t.Fatalf("both players have id %d", idA)
+	}
+
+	// 两名玩家都落在出生点的平台上
+	ground := float64(world.FlatSurface*world.BlockSize - PlayerSize)
+	waitFor(t, b, func(m Message) bool {
+		pa, okA := playerOf(m, idA)
+		pb, okB := playerOf(m, idB)
+		return m.Type == MsgPlayers && okA && okB && pa.Y == ground && pb.Y == ground
+	})
+
+	// A向右移动，B看到A的位置变化
+	if err := a.Send(Message{Type: MsgInput, Right: true}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, b, func(m Message) bool {
+		p, ok := playerOf(m, idA)
+		return m.Type == MsgPlayers && ok && p.X >= 20
+	})
+	if err := a.Send(Message{Type: MsgInput}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultWorldPlayerLandsAndWalks(t *testing.T) {
+	addr := startWorldServer(t, Config{TickRate: 200})
+	c, id := join(t, addr)
+
+	// 玩家落在默认地形出生点的地表上，而不是嵌在地下
+	gen, err := world.NewChunkGenerator(world.WorldDefault, world.TerrainSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ground := float64(gen.SurfaceHeight(0)*world.BlockSize - PlayerSize)
+	waitFor(t, c, func(m Message) bool {
+		p, ok := playerOf(m, id)
+		return m.Type == MsgPlayers && ok && p.Y == ground
+	})
+
+	// 按住右键（需要时跳跃）向右行走
+	if err := c.Send(Message{Type: MsgInput, Right: true, Jump: true}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, c, func(m Message) bool {
+		p, ok := playerOf(m, id)
+		return m.Type == MsgPlayers && ok && p.X >= 3*world.BlockSize
+	})
+}
+
+func TestPlayerSinksThroughWater(t *testing.T) {
+	gen, err := world.NewChunkGenerator(world.WorldVoid, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newState(gen, testLimits)
+	p := s.spawn(1)
+	s.step()
+
+	// 把出生点的平台换成两格水，玩家穿过水落到下面的石头上
+	floor := world.FlatSurface + 2
+	for _, y := range []int{world.FlatSurface, world.FlatSurface + 1} {
+		for _, x := range []int{-1, 0, 1} {
+			s.cells[world.GridPos{X: x, Y: y}] = world.ItemTypeWater
+		}
+	}
+	for _, x := range []int{-1, 0, 1} {
+		s.cells[world.GridPos{X: x, Y: floor}] = world.ItemTypeStone
+	}
+	for i := 0; i < 60; i++ {
+		s.movePlayer(p)
+	}
+	if !p.onGround || p.y != float64(floor*world.BlockSize-PlayerSize) {
+		t.Errorf("player at y=%v (on ground: %v), want standing on the stone at y=%v", p.y, p.onGround, floor*world.BlockSize-PlayerSize)
+	}
+}
+
+func TestBlockChangesBroadcast(t *testing.T) {
+	addr := startServer(t)
+	a, _ := join(t, addr)
+	b, _ := join(t, addr)
+	for _, c := range []*Client{a, b} {
+		waitFor(t, c, isSpawnChunk)
+	}
+
+	if err := a.Send(Message{Type: MsgPlace, X: 3, Y: world.FlatSurface, Block: world.ItemTypeDirt}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*Client{a, b} {
+		m := waitFor(t, c, func(m Message) bool { return m.Type == MsgBlock })
+		if m.X != 3 || m.Y != world.FlatSurface || m.Block != world.ItemTypeDirt || m.Removed {
+			t.Errorf("block message = %+v, want dirt placed at (3, %d)", m, world.FlatSurface)
+		}
+	}
+
+	if err := b.Send(Message{Type: MsgBreak, X: 1, Y: world.FlatSurface}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*Client{a, b} {
+		m := waitFor(t, c, func(m Message) bool { return m.Type == MsgBlock })
+		if m.X != 1 || m.Y != world.FlatSurface || !m.Removed {
+			t.Errorf("block message = %+v, want (1, %d) removed", m, world.FlatSurface)
+		}
+	}
+
+	// 服务器拒绝在已有方块的格子上放置方块
+	if err := a.Send(Message{Type: MsgPlace, X: -1, Y: world.FlatSurface, Block: world.ItemTypeDirt}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, a, func(m Message) bool { return m.Type == MsgError })
+}
+
+func TestEditsSurviveReload(t *testing.T) {
+	gen, err := world.NewChunkGenerator(world.WorldVoid, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newState(gen, testLimits)
+	p := s.spawn(1)
+	s.step()
+	if err := s.breakBlock(-1, world.FlatSurface); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.breakBlock(-1, world.FlatSurface); err == nil {
+		t.Error("breaking an empty cell succeeded")
+	}
+
+	// 玩家离开后区块被卸载，返回后重新生成的区块保留修改
+	p.x, p.y, p.vy = 100*world.BlockSize, 0, 0
+	s.updateChunks()
+	if _, ok := s.region.Chunk(-1, spawnChunkY); ok {
+		t.Fatal("spawn chunk still loaded after the player left")
+	}
+	p.x, p.y = 0, float64(world.FlatSurface*world.BlockSize-PlayerSize)
+	s.updateChunks()
+	cells, ok := s.chunkCells(-1, spawnChunkY)
+	if !ok {
+		t.Fatal("spawn chunk not loaded after the player returned")
+	}
+	for _, c := range cells {
+		if c.X == -1 && c.Y == world.FlatSurface {
+			t.Errorf("broken block at (-1, %d) came back after reload", world.FlatSurface)
+		}
+	}
+	if _, ok := s.cells[world.GridPos{X: 0, Y: world.FlatSurface}]; !ok {
+		t.Error("unedited platform block missing after reload")
+	}
+}
@@ -0,0 +1,291 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"2d.go/region"
+	"2d.go/world"
+)
+
+// 玩家物理参数，与游戏中的玩家实体相同
+const (
+	PlayerSize    = 50
+	PlayerSpeed   = 4.0
+	Gravity       = 0.5
+	JumpPower     = 12.0
+	PlayerMaxFall = 10.0
+)
+
+// edit 玩家对格子的修改，区块重新生成后仍然有效
+type edit struct {
+	block   world.ItemType
+	removed bool
+}
+
+// state 服务器拥有的权威世界状态
+//
+// 方块按格子保存，宽方块拆分为多个格子；玩家的修改单独记录，
+// 因此区块卸载后再次生成时修改不会丢失。state只在tick goroutine中访问。
+type state struct {
+	gen     world.ChunkGenerator
+	region  *region.Region
+	cells   map[world.GridPos]world.ItemType
+	edits   map[world.GridPos]edit
+	players map[int]*player
+	tick    int64
+}
+
+// player 服务器上的玩家
+type player struct {
+	id           int
+	x, y, vx, vy float64
+	onGround     bool
+	left, right  bool
+	jump         bool
+}
+
+// newState 创建使用gen生成区块的世界状态
+func newState(gen world.ChunkGenerator, limits region.Limits) *state {
+	return &state{
+		gen:     gen,
+		region:  region.New(gen, limits),
+		cells:   make(map[world.GridPos]world.ItemType),
+		edits:   make(map[world.GridPos]edit),
+		players: make(map[int]*player),
+	}
+}
+
+// spawn 在出生点加入玩家，位置与游戏中的出生点相同
+func (s *state) spawn(id int) *player {
+	p := &player{id: id, y: float64(s.gen.SurfaceHeight(0)*world.BlockSize - PlayerSize - 10)}
+	s.players[id] = p
+	return p
+}
+
+// playerCells 返回所有玩家所在的格子，按玩家编号排序
+func (s *state) playerCells() []world.GridPos {
+	ids := s.playerIDs()
+	cells := make([]world.GridPos, len(ids))
+	for i, id := range ids {
+		cells[i] = s.players[id].cell()
+	}
+	return cells
+}
+
+// playerIDs 返回按编号排序的玩家编号
+func (s *state) playerIDs() []int {
+	ids := make([]int, 0, len(s.players))
+	for id := range s.players {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// updateChunks 生成玩家附近的区块并卸载远处的区块
+func (s *state) updateChunks() {
+	cells := s.playerCells()
+	for _, c := range s.region.Update(cells...) {
+		for _, b := range c.Blocks {
+			for _, p := range world.BlockCells(b) {
+				s.cells[p] = b.Type
+			}
+		}
+		for _, p := range s.chunkPositions(c.X, c.Y) {
+			if e, ok := s.edits[p]; ok {
+				if e.removed {
+					delete(s.cells, p)
+				} else {
+					s.cells[p] = e.block
+				}
+			}
+		}
+	}
+	for _, c := range s.region.Unload(cells...) {
+		for _, p := range s.chunkPositions(c.X, c.Y) {
+			delete(s.cells, p)
+		}
+	}
+}
+
+// chunkPositions 返回区块中的所有格子
+func (s *state) chunkPositions(chunkX, chunkY int) []world.GridPos {
+	cells := make([]world.GridPos, 0, world.ChunkSize*world.ChunkSize)
+	for x := chunkX * world.ChunkSize; x < (chunkX+1)*world.ChunkSize; x++ {
+		for y := chunkY * world.ChunkSize; y < (chunkY+1)*world.ChunkSize; y++ {
+			cells = append(cells, world.GridPos{X: x, Y: y})
+		}
+	}
+	return cells
+}
+
+// chunkCells 返回已生成区块中的方块格子，区块未生成时返回false
+func (s *state) chunkCells(chunkX, chunkY int) ([]Cell, bool) {
+	if _, ok := s.region.Chunk(chunkX, chunkY); !ok {
+		return nil, false
+	}
+	var cells []Cell
+	for _, p := range s.chunkPositions(chunkX, chunkY) {
+		if t, ok := s.cells[p]; ok {
+			cells = append(cells, Cell{X: p.X, Y: p.Y, Block: t})
+		}
+	}
+	return cells, true
+}
+
+// setBlock 在格子上放置方块，格子必须位于已生成的区块中且为空
+func (s *state) setBlock(x, y int, t world.ItemType) error {
+	if _, ok := world.ItemRegistry[t]; !ok {
+		return fmt.Errorf("unknown block type %d", t)
+	}
+	p := world.GridPos{X: x, Y: y}
+	if err := s.checkLoaded(p); err != nil {
+		return err
+	}
+	if _, ok := s.cells[p]; ok {
+		return fmt.Errorf("cell (%d, %d) is occupied", x, y)
+	}
+	for _, pl := range s.players {
+		if overlapsCell(pl.x, pl.y, p) {
+			return fmt.Errorf("cell (%d, %d) is occupied by player %d", x, y, pl.id)
+		}
+	}
+	s.cells[p] = t
+	s.edits[p] = edit{block: t}
+	return nil
+}
+
+// breakBlock 破坏格子上的方块
+func (s *state) breakBlock(x, y int) error {
+	p := world.GridPos{X: x, Y: y}
+	if err := s.checkLoaded(p); err != nil {
+		return err
+	}
+	if _, ok := s.cells[p]; !ok {
+		return fmt.Errorf("cell (%d, %d) is empty", x, y)
+	}
+	delete(s.cells, p)
+	s.edits[p] = edit{removed: true}
+	return nil
+}
+
+// checkLoaded 检查格子所在的区块是否已生成
+func (s *state) checkLoaded(p world.GridPos) error {
+	c := world.ChunkOf(p.X, p.Y)
+	if _, ok := s.region.Chunk(c.X, c.Y); !ok {
+		return fmt.Errorf("chunk (%d, %d) is not loaded", c.X, c.Y)
+	}
+	return nil
+}
+
+// step 推进一个tick：按输入移动所有玩家，再生成和卸载区块
+func (s *state) step() {
+	s.tick++
+	for _, id := range s.playerIDs() {
+		s.movePlayer(s.players[id])
+	}
+	s.updateChunks()
+}
+
+// movePlayer 处理玩家输入并移动玩家，与游戏中的updatePlayer和moveEntity相同
+func (s *state) movePlayer(p *player) {
+	p.vx = 0
+	if p.left {
+		p.vx -= PlayerSpeed
+	}
+	if p.right {
+		p.vx += PlayerSpeed
+	}
+	if p.jump && p.onGround {
+		p.vy = -JumpPower
+		p.onGround = false
+	}
+
+	// 水平移动
+	oldX := p.x
+	p.x += p.vx
+	for _, c := range s.solidCells(p.x, p.y) {
+		bx := float64(c.X * world.BlockSize)
+		if !overlapsCell(p.x, p.y, c) {
+			continue
+		}
+		if oldX <= bx-PlayerSize {
+			p.x = bx - PlayerSize
+		} else if oldX >= bx+world.BlockSize {
+			p.x = bx + world.BlockSize
+		}
+	}
+
+	// 重力
+	p.vy += Gravity
+	if p.vy > PlayerMaxFall {
+		p.vy = PlayerMaxFall
+	}
+
+	// 垂直移动
+	oldY := p.y
+	p.y += p.vy
+	p.onGround = false
+	for _, c := range s.solidCells(p.x, p.y) {
+		by := float64(c.Y * world.BlockSize)
+		if !overlapsCell(p.x, p.y, c) {
+			continue
+		}
+		if p.vy > 0 && oldY <= by-PlayerSize {
+			p.y = by - PlayerSize
+			p.vy = 0
+			p.onGround = true
+		} else if p.vy < 0 && oldY >= by+world.BlockSize {
+			p.y = by + world.BlockSize
+			p.vy = 0
+		}
+	}
+}
+
+// solidCells 返回与位于(x, y)的玩家重叠的实心方块格子，液体不阻挡移动
+func (s *state) solidCells(x, y float64) []world.GridPos {
+	var cells []world.GridPos
+	x0, x1 := cellRange(x)
+	y0, y1 := cellRange(y)
+	for cx := x0; cx <= x1; cx++ {
+		for cy := y0; cy <= y1; cy++ {
+			p := world.GridPos{X: cx, Y: cy}
+			if t, ok := s.cells[p]; ok && t.Solid() {
+				cells = append(cells, p)
+			}
+		}
+	}
+	return cells
+}
+
+// cellRange 返回从pos开始、长度为PlayerSize的区间覆盖的格子范围
+func cellRange(pos float64) (int, int) {
+	return int(math.Floor(pos / world.BlockSize)), int(math.Ceil((pos+PlayerSize)/world.BlockSize)) - 1
+}
+
+// overlapsCell 判断位于(x, y)的玩家是否与格子重叠
+func overlapsCell(x, y float64, c world.GridPos) bool {
+	bx, by := float64(c.X*world.BlockSize), float64(c.Y*world.BlockSize)
+	return x < bx+world.BlockSize && x+PlayerSize > bx && y < by+world.BlockSize && y+PlayerSize > by
+}
+
+// cell 返回玩家中心所在的格子
+func (p *player) cell() world.GridPos {
+	return world.GridPos{
+		X: int(math.Floor((p.x + PlayerSize/2) / world.BlockSize)),
+		Y: int(math.Floor((p.y + PlayerSize/2) / world.BlockSize)),
+	}
+}
+
+// playerStates 返回按编号排序的玩家位置
+func (s *state) playerStates() []PlayerState {
+	ids := s.playerIDs()
+	states := make([]PlayerState, len(ids))
+	for i, id := range ids {
+		p := s.players[id]
+		states[i] = PlayerState{ID: id, X: p.x, Y: p.y}
+	}
+	return states
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 建造工具常量
+const (
+	ToolMaxCells   = 10000 // 框选区域超过该格数时不执行（避免误操作卡顿）
+	FloodFillLimit = 4096  // 漫水填充最多填充的格数
+)
+
+// 预览配色
+var (
+	ghostRemove     = color.RGBA{255, 60, 60, 110}
+	selectionBorder = color.RGBA{255, 220, 0, 255}
+)
+
+// ghostAlpha 放置预览的不透明度
+const ghostAlpha = 115
+
+// BuildTool 框选（鼠标中键）时使用的建造工具
+type BuildTool int
+
+// 建造工具
+const (
+	ToolFill    BuildTool = iota // 填充：在区域内的空格放置当前物品
+	ToolClear                    // 清除：移除区域内的所有方块
+	ToolReplace                  // 替换：将区域内与起点同类型的方块替换为当前物品
+	ToolHollow                   // 空心框：只在区域边缘放置
+	ToolLine                     // 直线：从起点到终点放置一条线
+	ToolFlood                    // 漫水填充：从起点向四周填充相连的同类格子，不超出区域
+	ToolSelect                   // 选择：只记录区域，供复制和剪切使用
+	toolCount
+)
+
+// 建造工具名称
+var buildToolNames = [toolCount]string{
+	ToolFill:    "Fill",
+	ToolClear:   "Clear",
+	ToolReplace: "Replace",
+	ToolHollow:  "Hollow",
+	ToolLine:    "Line",
+	ToolFlood:   "Flood",
+	ToolSelect:  "Select",
+}
+
+// String 返回建造工具名称
+func (t BuildTool) String() string {
+	if t >= 0 && t < toolCount {
+		return buildToolNames[t]
+	}
+	return fmt.Sprintf("BuildTool(%d)", int(t))
+}
+
+// ToolPlan 建造工具将要执行的操作（替换时同一格既在Remove中也在Place中）
+type ToolPlan struct {
+	Remove   []GridPos
+	Place    []GridPos
+	TooLarge bool // 区域超过ToolMaxCells，不执行
+}
+
+// toolPreview 缓存的预览，框选和工具不变时不重新计算
+type toolPreview struct {
+	tool       BuildTool
+	item       ItemType
+	mode       int
+	start, end GridPos
+	plan       ToolPlan
+	valid      bool
+}
+
+// selectionRect 返回框选区域覆盖的格子
+func (g *Game) selectionRect() gridRect {
+	start := toGridPos(g.selectionStartX, g.selectionStartY)
+	end := toGridPos(g.selectionEndX, g.selectionEndY)
+	return gridRect{min(start.X, end.X), min(start.Y, end.Y), max(start.X, end.X), max(start.Y, end.Y)}
+}
+
+// lineCells 返回从a到b的直线经过的格子（Bresenham算法，每步只沿一个方向移动，
+// 因此格子四向相连，斜线没有对角缝隙）
+func lineCells(a, b GridPos) []GridPos {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	cells := []GridPos{a}
+	err := dx + dy
+	for p := a; p != b; {
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			p.X += sx
+		} else {
+			err += dx
+			p.Y += sy
+		}
+		cells = append(cells, p)
+	}
+	return cells
+}
+
+// abs 返回整数的绝对值
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// floodCells 从start出发，返回与其内容（空气或同类型方块）相同且四向相连的格子，
+// 不超出bounds，最多limit格
+func floodCells(grid blockGrid, start GridPos, bounds gridRect, limit int) []GridPos {
+	if !bounds.contains(start) {
+		return nil
+	}
+	seedType, seedSolid := grid[start]
+	same := func(p GridPos) bool {
+		t, solid := grid[p]
+		return solid == seedSolid && t == seedType
+	}
+	visited := map[GridPos]bool{start: true}
+	queue := []GridPos{start}
+	for i := 0; i < len(queue) && len(queue) < limit; i++ {
+		p := queue[i]
+		for _, n := range []GridPos{{p.X + 1, p.Y}, {p.X - 1, p.Y}, {p.X, p.Y + 1}, {p.X, p.Y - 1}} {
+			if !visited[n] && bounds.contains(n) && same(n) && len(queue) < limit {
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return queue
+}
+
+// toolPlan 根据当前工具、物品和框选区域计算将要移除和放置的格子，
+// 与框选放置一样跳过玩家视线被阻挡的格子，生存模式下还跳过超出放置距离的格子
+func (g *Game) toolPlan() ToolPlan {
+	grid := g.pathGrid()
+	rect := g.selectionRect()
+	start := toGridPos(g.selectionStartX, g.selectionStartY)
+	end := toGridPos(g.selectionEndX, g.selectionEndY)
+	item := g.currentItemType
+
+	var plan ToolPlan
+	if (rect.maxX-rect.minX+1)*(rect.maxY-rect.minY+1) > ToolMaxCells {
+		plan.TooLarge = true
+		return plan
+	}
+	playerCenterX, playerCenterY := g.player.CenterX(), g.player.CenterY()
+	visible := func(p GridPos) bool {
+		x, y := float64(p.X)*BlockSize, float64(p.Y)*BlockSize
+		// 先排除远处的格子，视线检查需要沿直线逐点查询
+		if g.gameMode == GameModeSurvival && distance(playerCenterX, playerCenterY, x+BlockSize/2, y+BlockSize/2) > MaxPlaceDistance {
+			return false
+		}
+		return g.hasLineOfSight(x, y, playerCenterX, playerCenterY)
+	}
+	place := func(p GridPos) {
+		if _, solid := grid[p]; !solid && visible(p) {
+			plan.Place = append(plan.Place, p)
+		}
+	}
+	replace := func(p GridPos) {
+		if visible(p) {
+			plan.Remove = append(plan.Remove, p)
+			plan.Place = append(plan.Place, p)
+		}
+	}
+	each := func(fn func(p GridPos)) {
+		for y := rect.minY; y <= rect.maxY; y++ {
+			for x := rect.minX; x <= rect.maxX; x++ {
+				fn(GridPos{x, y})
+			}
+		}
+	}
+
+	switch g.buildTool {
+	case ToolFill:
+		each(place)
+	case ToolClear:
+		each(func(p GridPos) {
+			if _, solid := grid[p]; solid && visible(p) {
+				plan.Remove = append(plan.Remove, p)
+			}
+		})
+	case ToolReplace:
+		from, solid := grid[start]
+		if !solid || from == item {
+			break
+		}
+		each(func(p GridPos) {
+			if t, ok := grid[p]; ok && t == from {
+				replace(p)
+			}
+		})
+	case ToolHollow:
+		each(func(p GridPos) {
+			if p.X == rect.minX || p.X == rect.maxX || p.Y == rect.minY || p.Y == rect.maxY {
+				place(p)
+			}
+		})
+	case ToolLine:
+		for _, p := range lineCells(start, end) {
+			place(p)
+		}
+	case ToolFlood:
+		seedType, seedSolid := grid[start]
+		if seedSolid && seedType == item {
+			break
+		}
+		for _, p := range floodCells(grid, start, rect, FloodFillLimit) {
+			if seedSolid {
+				replace(p)
+			} else {
+				place(p)
+			}
+		}
+	}
+	return plan
+}
+
+// previewPlan 返回当前框选的预览（缓存到框选、工具、物品或模式变化为止）
+func (g *Game) previewPlan() ToolPlan {
+	p := &g.toolPreview
+	start := toGridPos(g.selectionStartX, g.selectionStartY)
+	end := toGridPos(g.selectionEndX, g.selectionEndY)
+	if !p.valid || p.tool != g.buildTool || p.item != g.currentItemType || p.mode != g.gameMode || p.start != start || p.end != end {
+		*p = toolPreview{
+			tool: g.buildTool, item: g.currentItemType, mode: g.gameMode,
+			start: start, end: end, plan: g.toolPlan(), valid: true,
+		}
+	}
+	return p.plan
+}
+
+// applyTool 对框选区域执行当前工具，整个操作作为一步撤销历史
+//
+// 移除在前、放置在后；放置仍经过addBlock，遵守生存模式的距离和相邻规则，
+// 生存模式下移除的方块会生成掉落物。
+func (g *Game) applyTool() {
+	if g.buildTool == ToolSelect {
+		g.selection, g.hasSelection = g.selectionRect(), true
+		return
+	}
+	plan := g.toolPlan()
+	g.toolPreview.valid = false
+	if plan.TooLarge {
+		return
+	}
+
+	g.history.Begin()
+	defer g.history.End()
+	for _, p := range plan.Remove {
+		block, removed := g.removeBlock(float64(p.X)*BlockSize, float64(p.Y)*BlockSize)
+		if removed && g.gameMode == GameModeSurvival {
+			g.spawnItemDrop(block.Type, block.X, block.Y)
+		}
+	}
+	for _, p := range plan.Place {
+		g.addBlock(float64(p.X)*BlockSize, float64(p.Y)*BlockSize)
+	}
+}
+
+// updateBuildTool 处理工具切换：T切换到下一个工具，Shift+T切换到上一个
+func (g *Game) updateBuildTool() {
+	if !inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		return
+	}
+	step := BuildTool(1)
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		step = toolCount - 1
+	}
+	g.buildTool = (g.buildTool + step) % toolCount
+}
+
+// ghostBatch 批量绘制半透明格子（工具预览、粘贴预览）
+type ghostBatch struct {
+	screen   *ebiten.Image
+	geoM     ebiten.GeoM
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// add 追加一个格子，颜色为非预乘alpha
+func (b *ghostBatch) add(p GridPos, c color.RGBA) {
+	// 每批最多16383个四边形，避免uint16索引溢出
+	if len(b.vertices)+4 > math.MaxUint16 {
+		b.flush()
+	}
+	x0, y0 := b.geoM.Apply(float64(p.X)*BlockSize, float64(p.Y)*BlockSize)
+	x1, y1 := b.geoM.Apply(float64(p.X+1)*BlockSize, float64(p.Y+1)*BlockSize)
+	a := float32(c.A) / 255
+	cr, cg, cb := float32(c.R)/255*a, float32(c.G)/255*a, float32(c.B)/255*a
+	base := uint16(len(b.vertices))
+	for _, v := range [][2]float64{{x0, y0}, {x1, y0}, {x0, y1}, {x1, y1}} {
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX: float32(v[0]), DstY: float32(v[1]), SrcX: 1, SrcY: 1,
+			ColorR: cr, ColorG: cg, ColorB: cb, ColorA: a,
+		})
+	}
+	b.indices = append(b.indices, base, base+1, base+2, base+1, base+2, base+3)
+}
+
+// flush 绘制已追加的格子
+func (b *ghostBatch) flush() {
+	if len(b.indices) > 0 {
+		b.screen.DrawTriangles(b.vertices, b.indices, getWhiteImage(), nil)
+	}
+	b.vertices, b.indices = b.vertices[:0], b.indices[:0]
+}
+
+// ghostColor 返回物品的预览颜色
+func ghostColor(t ItemType) color.RGBA {
+	c := itemRegistry[t].Color
+	c.A = ghostAlpha
+	return c
+}
+
+// drawToolPreview 以半透明方块绘制工具将要放置（当前物品颜色）和移除（红色）的格子
+func (g *Game) drawToolPreview(screen *ebiten.Image, geoM ebiten.GeoM) {
+	plan := g.previewPlan()
+	batch := ghostBatch{screen: screen, geoM: geoM}
+	for _, p := range plan.Remove {
+		batch.add(p, ghostRemove)
+	}
+	for _, p := range plan.Place {
+		batch.add(p, ghostColor(g.currentItemType))
+	}
+	batch.flush()
+}
+
+// toolStatus 返回界面上显示的工具状态
+func (g *Game) toolStatus() string {
+	status := fmt.Sprintf("Tool: %s (T)", g.buildTool)
+	if g.buildTool == ToolSelect {
+		if g.selecting || g.hasSelection {
+			rect := g.selection
+			if g.selecting {
+				rect = g.selectionRect()
+			}
+			status += fmt.Sprintf(" - %dx%d", rect.maxX-rect.minX+1, rect.maxY-rect.minY+1)
+		}
+		return status
+	}
+	if g.selecting {
+		plan := g.previewPlan()
+		if plan.TooLarge {
+			return status + fmt.Sprintf(" - selection larger than %d blocks", ToolMaxCells)
+		}
+		status += fmt.Sprintf(" - place %d, remove %d", len(plan.Place), len(plan.Remove))
+	}
+	return status
+}
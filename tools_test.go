@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+)
+
+// newToolGame 创建只有给定方块的创造模式游戏，框选从start到end（格子坐标）
+func newToolGame(blocks []Block, start, end GridPos) *Game {
+	g := &Game{
+		entities: NewEntityManager(),
+		blocks:   blocks,
+		gameMode: GameModeCreative,
+	}
+	g.player = g.entities.Spawn(newPlayerEntity(0, -10*BlockSize))
+	g.currentItemType = ItemTypeStone
+	g.selectionStartX, g.selectionStartY = float64(start.X)*BlockSize+1, float64(start.Y)*BlockSize+1
+	g.selectionEndX, g.selectionEndY = float64(end.X)*BlockSize+1, float64(end.Y)*BlockSize+1
+	return g
+}
+
+// blockAtCell 创建占据一格的方块
+func blockAtCell(x, y int, t ItemType) Block {
+	return Block{float64(x) * BlockSize, float64(y) * BlockSize, BlockSize, BlockSize, t}
+}
+
+func TestLineCells(t *testing.T) {
+	tests := []struct {
+		a, b GridPos
+		want int
+	}{
+		{GridPos{0, 0}, GridPos{0, 0}, 1},
+		{GridPos{0, 0}, GridPos{5, 0}, 6},
+		{GridPos{3, 4}, GridPos{3, -2}, 7},
+		{GridPos{0, 0}, GridPos{4, 4}, 9},
+		{GridPos{-2, 1}, GridPos{6, -2}, 12},
+	}
+	for _, tt := range tests {
+		cells := lineCells(tt.a, tt.b)
+		if len(cells) != tt.want {
+			t.Errorf("lineCells(%v, %v) has %d cells, want %d", tt.a, tt.b, len(cells), tt.want)
+		}
+		if cells[0] != tt.a || cells[len(cells)-1] != tt.b {
+			t.Errorf("lineCells(%v, %v) = %v, want endpoints included", tt.a, tt.b, cells)
+		}
+		// 相邻格子四向相连，没有对角缝隙
+		for i := 1; i < len(cells); i++ {
+			if abs(cells[i].X-cells[i-1].X)+abs(cells[i].Y-cells[i-1].Y) != 1 {
+				t.Errorf("lineCells(%v, %v) skips from %v to %v", tt.a, tt.b, cells[i-1], cells[i])
+			}
+		}
+	}
+}
+
+func TestToolPlans(t *testing.T) {
+	blocks := []Block{
+		blockAtCell(0, 0, ItemTypeDirt),
+		blockAtCell(1, 0, ItemTypeDirt),
+		blockAtCell(2, 0, ItemTypeGrass),
+	}
+	tests := []struct {
+		tool          BuildTool
+		start, end    GridPos
+		remove, place int
+	}{
+		{ToolFill, GridPos{0, -1}, GridPos{3, 0}, 0, 5},   // 8格中3格已有方块
+		{ToolClear, GridPos{0, -1}, GridPos{3, 0}, 3, 0},  // 移除所有方块
+		{ToolReplace, GridPos{0, 0}, GridPos{3, 0}, 2, 2}, // 起点为泥土，只替换泥土
+		{ToolHollow, GridPos{0, -4}, GridPos{4, -1}, 0, 14},
+		{ToolLine, GridPos{0, -1}, GridPos{4, -3}, 0, 7},
+		{ToolFlood, GridPos{1, 0}, GridPos{-1, 0}, 2, 2}, // 相连的泥土
+		{ToolFlood, GridPos{4, 0}, GridPos{-2, 0}, 0, 2}, // 空气被方块隔开，只填充起点所在一侧
+	}
+	for _, tt := range tests {
+		g := newToolGame(append([]Block(nil), blocks...), tt.start, tt.end)
+		g.buildTool = tt.tool
+		plan := g.toolPlan()
+		if len(plan.Remove) != tt.remove || len(plan.Place) != tt.place {
+			t.Errorf("%s %v-%v: remove %d place %d, want remove %d place %d",
+				tt.tool, tt.start, tt.end, len(plan.Remove), len(plan.Place), tt.remove, tt.place)
+		}
+	}
+}
+
+func TestSurvivalToolPlanDistance(t *testing.T) {
+	// 玩家中心在(25, -475)，填充下方两格的一整行
+	g := newToolGame(nil, GridPos{-20, -12}, GridPos{20, -12})
+	g.buildTool = ToolFill
+	if n := len(g.toolPlan().Place); n != 41 {
+		t.Errorf("creative fill places %d cells, want 41", n)
+	}
+
+	// 生存模式只保留放置距离内的9格
+	g.gameMode = GameModeSurvival
+	plan := g.toolPlan()
+	if len(plan.Place) != 9 {
+		t.Fatalf("survival fill places %d cells, want 9", len(plan.Place))
+	}
+	for _, p := range plan.Place {
+		if abs(p.X) > 4 {
+			t.Errorf("survival fill places %v beyond the place distance", p)
+		}
+	}
+}
+
+func TestFloodFillBounded(t *testing.T) {
+	// 没有任何方块时只能填满框选区域
+	g := newToolGame(nil, GridPos{0, 0}, GridPos{9, 9})
+	g.buildTool = ToolFlood
+	if plan := g.toolPlan(); len(plan.Place) != 100 {
+		t.Fatalf("flood placed %d cells, want 100 (selection bounds)", len(plan.Place))
+	}
+
+	cells := floodCells(blockGrid{}, GridPos{0, 0}, gridRect{-100, -100, 100, 100}, 50)
+	if len(cells) != 50 {
+		t.Fatalf("floodCells returned %d cells, want limit 50", len(cells))
+	}
+}
+
+func TestApplyToolReplace(t *testing.T) {
+	g := newToolGame([]Block{
+		blockAtCell(0, 0, ItemTypeDirt),
+		blockAtCell(1, 0, ItemTypeGrass),
+		blockAtCell(2, 0, ItemTypeDirt),
+	}, GridPos{0, 0}, GridPos{2, 0})
+	g.history = NewEditHistory(0)
+	g.buildTool = ToolReplace
+	g.applyTool()
+
+	want := map[GridPos]ItemType{{0, 0}: ItemTypeStone, {1, 0}: ItemTypeGrass, {2, 0}: ItemTypeStone}
+	for p, typ := range want {
+		if got := g.grid[p]; got != typ {
+			t.Errorf("cell %v = %v, want %v", p, got, typ)
+		}
+	}
+	if len(g.blocks) != 3 {
+		t.Fatalf("%d blocks after replace, want 3", len(g.blocks))
+	}
+
+	// 替换作为一步撤销
+	if undo, _ := g.history.Len(); undo != 1 {
+		t.Fatalf("undo steps = %d, want 1", undo)
+	}
+	g.undo()
+	if g.grid[GridPos{0, 0}] != ItemTypeDirt || g.grid[GridPos{2, 0}] != ItemTypeDirt {
+		t.Fatal("undo did not restore replaced blocks")
+	}
+}
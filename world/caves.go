@@ -0,0 +1,148 @@
+package world
+
+import (
+	"math"
+)
+
+// CaveParams 洞穴生成参数
+//
+// 洞穴由两部分组成：沿随机路径挖出的蠕虫隧道（互相连通，部分从地表开始形成洞口），
+// 以及较深处由噪声决定的大型洞窟。
+type CaveParams struct {
+	WormRegion     int     // 蠕虫区域的宽度（方块），每个区域独立生成蠕虫
+	WormsPerRegion int     // 每个区域的蠕虫数
+	WormLength     int     // 蠕虫的步数（每步前进一格）
+	WormMinRadius  float64 // 隧道半径范围
+	WormMaxRadius  float64
+	WormTurn       float64 // 每步最大转向角（弧度）
+	WormMinDepth   int     // 隧道行进的深度范围（地表以下的格数）
+	WormMaxDepth   int
+	EntranceChance float64 // 蠕虫从地表开始、形成洞口的概率
+
+	CavernScale     float64 // 洞窟噪声尺度，越大洞窟越小
+	CavernThreshold float64 // 洞窟噪声阈值，越大洞窟越少
+	CavernMinDepth  int     // 洞窟的最浅深度
+}
+
+// DefaultCaveParams 返回默认的洞穴生成参数
+func DefaultCaveParams() CaveParams {
+	return CaveParams{
+		WormRegion:      64,
+		WormsPerRegion:  2,
+		WormLength:      120,
+		WormMinRadius:   1.2,
+		WormMaxRadius:   2.2,
+		WormTurn:        0.35,
+		WormMinDepth:    6,
+		WormMaxDepth:    TerrainDepth - 8,
+		EntranceChance:  0.3,
+		CavernScale:     0.06,
+		CavernThreshold: 0.42,
+		CavernMinDepth:  24,
+	}
+}
+
+// caveSlot 蠕虫使用的区域随机数槽位（结构使用0和1）
+const caveSlot = 16
+
+// hasCave 判断指定位置是否有洞穴（height为该列的地表高度）
+func (tg *TerrainGenerator) hasCave(x, y, height int) bool {
+	if y-height >= tg.Caves.CavernMinDepth && tg.CaveNoise(x, y) > tg.Caves.CavernThreshold {
+		return true
+	}
+	return tg.wormCarved(x, y)
+}
+
+// wormCarved 判断格子是否被蠕虫隧道挖空，需要检查蠕虫可能到达该列的所有区域
+func (tg *TerrainGenerator) wormCarved(x, y int) bool {
+	p := tg.Caves
+	if p.WormRegion <= 0 || p.WormsPerRegion <= 0 {
+		return false
+	}
+	reach := p.WormLength + int(math.Ceil(p.WormMaxRadius))
+	for region := FloorDiv(x-reach, p.WormRegion); region <= FloorDiv(x+reach, p.WormRegion); region++ {
+		if tg.wormCells(region)[GridPos{x, y}] {
+			return true
+		}
+	}
+	return false
+}
+
+// wormCells 返回从区域内出发的蠕虫挖出的格子，结果只由种子和区域决定并被缓存
+func (tg *TerrainGenerator) wormCells(region int) map[GridPos]bool {
+	tg.wormMu.Lock()
+	defer tg.wormMu.Unlock()
+	if cells, ok := tg.worms[region]; ok {
+		return cells
+	}
+	cells := make(map[GridPos]bool)
+	for i := 0; i < tg.Caves.WormsPerRegion; i++ {
+		for _, c := range tg.wormPath(region, i) {
+			carveDisc(cells, c.x, c.y, c.radius)
+		}
+	}
+	if tg.worms == nil {
+		tg.worms = make(map[int]map[GridPos]bool)
+	}
+	tg.worms[region] = cells
+	return cells
+}
+
+// wormStep 蠕虫路径上的一点
+type wormStep struct {
+	x, y, radius float64
+}
+
+// wormPath 计算区域内第i条蠕虫的路径
+//
+// 蠕虫每步前进一格并随机转向；过浅时转向下方，过深时转向上方，
+// 使隧道保持在深度范围内。从地表出发的蠕虫先向下挖出洞口。
+func (tg *TerrainGenerator) wormPath(region, i int) []wormStep {
+	p := tg.Caves
+	rng := structureRNG(tg.seed, region, caveSlot+i)
+	x := float64(region*p.WormRegion + rng.Intn(p.WormRegion))
+	surface := tg.getHeight(int(x))
+
+	var y, angle float64
+	if rng.Float64() < p.EntranceChance && math.Abs(x) > StructureSpawnClear {
+		y = float64(surface)
+		angle = math.Pi/2 + (rng.Float64()-0.5)*math.Pi/3 // 向下（y增大）
+	} else {
+		y = float64(surface + p.WormMinDepth + rng.Intn(p.WormMaxDepth-p.WormMinDepth+1))
+		angle = rng.Float64() * 2 * math.Pi
+	}
+	radius := p.WormMinRadius + rng.Float64()*(p.WormMaxRadius-p.WormMinRadius)
+
+	path := make([]wormStep, 0, p.WormLength)
+	for step := 0; step < p.WormLength; step++ {
+		path = append(path, wormStep{x, y, radius})
+		x += math.Cos(angle)
+		y += math.Sin(angle)
+
+		angle += (rng.Float64()*2 - 1) * p.WormTurn
+		depth := y - float64(tg.getHeight(int(math.Floor(x))))
+		switch {
+		case depth < float64(p.WormMinDepth):
+			angle += 0.3 * math.Sin(math.Pi/2-angle)
+		case depth > float64(p.WormMaxDepth):
+			angle += 0.3 * math.Sin(-math.Pi/2-angle)
+		}
+		radius = math.Max(p.WormMinRadius, math.Min(p.WormMaxRadius, radius+(rng.Float64()-0.5)*0.2))
+	}
+	return path
+}
+
+// carveDisc 挖空以(x, y)为中心、半径为r的圆内的格子
+//
+// 半径不小于1时，相距一步的两个圆总有共同的格子，因此整条隧道四连通。
+func carveDisc(cells map[GridPos]bool, x, y, r float64) {
+	cx, cy := int(math.Floor(x)), int(math.Floor(y))
+	n := int(math.Ceil(r))
+	for dy := -n; dy <= n; dy++ {
+		for dx := -n; dx <= n; dx++ {
+			if float64(dx*dx+dy*dy) <= r*r {
+				cells[GridPos{cx + dx, cy + dy}] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package world
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+)
+
+// findWorm 返回出生点右侧第一条不与结构重叠的蠕虫路径，entrance指定是否从地表出发
+func findWorm(t *testing.T, tg *TerrainGenerator, entrance bool) []wormStep {
+	t.Helper()
+	for region := 1; region < 100; region++ {
+		for i := 0; i < tg.Caves.WormsPerRegion; i++ {
+			path := tg.wormPath(region, i)
+			minX, maxX := math.Inf(1), math.Inf(-1)
+			for _, s := range path {
+				minX, maxX = math.Min(minX, s.x), math.Max(maxX, s.x)
+			}
+			start := path[0]
+			if (float64(tg.getHeight(int(math.Floor(start.x)))) == start.y) != entrance {
+				continue
+			}
+			if len(tg.structuresInRange(int(minX)-3, int(maxX)+3)) == 0 {
+				return path
+			}
+		}
+	}
+	t.Fatalf("no worm (entrance: %v) away from structures", entrance)
+	return nil
+}
+
+// wormBounds 返回蠕虫路径经过的格子范围
+func wormBounds(path []wormStep) Rect {
+	bounds := Rect{math.MaxInt, math.MaxInt, math.MinInt, math.MinInt}
+	for _, s := range path {
+		x, y := int(math.Floor(s.x)), int(math.Floor(s.y))
+		bounds = Rect{min(bounds.MinX, x), min(bounds.MinY, y), max(bounds.MaxX, x), max(bounds.MaxY, y)}
+	}
+	return bounds
+}
+
+// solidCells 生成from~to之间的区块，返回实心方块占据的格子（水不阻挡通行）
+func solidCells(tg *TerrainGenerator, from, to GridPos) map[GridPos]bool {
+	solid := make(map[GridPos]bool)
+	for cx := from.X; cx <= to.X; cx++ {
+		for cy := from.Y; cy <= to.Y; cy++ {
+			for _, block := range tg.GenerateChunk(cx, cy).Blocks {
+				if block.Type == ItemTypeWater {
+					continue // 被淹没的隧道仍然连通
+				}
+				for _, c := range BlockCells(block) {
+					solid[c] = true
+				}
+			}
+		}
+	}
+	return solid
+}
+
+// floodAir 从start出发，在inside范围内沿四邻搜索非实心格子，返回到达的格子
+func floodAir(solid map[GridPos]bool, start []GridPos, inside func(GridPos) bool) map[GridPos]bool {
+	visited := make(map[GridPos]bool)
+	queue := append([]GridPos(nil), start...)
+	for _, p := range start {
+		visited[p] = true
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range []GridPos{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			n := GridPos{p.X + d.X, p.Y + d.Y}
+			if !visited[n] && !solid[n] && inside(n) {
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return visited
+}
+
+func TestWormsDeterministic(t *testing.T) {
+	a, b := NewTerrainGenerator(TerrainSeed), NewTerrainGenerator(TerrainSeed)
+	// 以不同顺序查询区域，结果相同
+	for region := 5; region >= -5; region-- {
+		a.wormCells(region)
+	}
+	for region := -5; region <= 5; region++ {
+		if fmt.Sprint(sortedCells(a.wormCells(region))) != fmt.Sprint(sortedCells(b.wormCells(region))) {
+			t.Fatalf("region %d differs between generators", region)
+		}
+	}
+
+	// 部分蠕虫从地表出发形成洞口
+	entrances := 0
+	for x := -2000; x < 2000; x++ {
+		height := a.getHeight(x)
+		if a.hasCave(x, height, height) {
+			entrances++
+		}
+	}
+	if entrances == 0 {
+		t.Error("no cave entrances within 2000 blocks of spawn")
+	}
+
+	// 关闭蠕虫后没有隧道
+	a.Caves.WormsPerRegion = 0
+	if a.wormCarved(int(b.wormPath(1, 0)[0].x), int(math.Floor(b.wormPath(1, 0)[0].y))) {
+		t.Error("worm carved with WormsPerRegion = 0")
+	}
+}
+
+// sortedCells 返回排序后的格子列表
+func sortedCells(cells map[GridPos]bool) []GridPos {
+	var out []GridPos
+	for p := range cells {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].X != out[j].X {
+			return out[i].X < out[j].X
+		}
+		return out[i].Y < out[j].Y
+	})
+	return out
+}
+
+func TestCaveConnectivity(t *testing.T) {
+	tg := NewTerrainGenerator(TerrainSeed)
+	path := findWorm(t, tg, false)
+
+	// 生成蠕虫经过的所有区块
+	bounds := wormBounds(path)
+	from, to := ChunkOf(bounds.MinX-3, bounds.MinY-3), ChunkOf(bounds.MaxX+3, bounds.MaxY+3)
+	solid := solidCells(tg, from, to)
+
+	// 只在地形柱内（地表以下）搜索空气，避免经过地表以上或地形底部以下的空间连通
+	inTerrain := func(p GridPos) bool {
+		height := tg.getHeight(p.X)
+		return p.Y >= height && p.Y < height+TerrainDepth && p.X >= from.X*ChunkSize && p.X < (to.X+1)*ChunkSize
+	}
+	start := GridPos{int(math.Floor(path[0].x)), int(math.Floor(path[0].y))}
+	if solid[start] {
+		t.Fatalf("worm start %v is solid", start)
+	}
+	visited := floodAir(solid, []GridPos{start}, inTerrain)
+	chunks := make(map[GridPos]bool)
+	for p := range visited {
+		chunks[ChunkOf(p.X, p.Y)] = true
+	}
+
+	reached := 0
+	for _, s := range path {
+		p := GridPos{int(math.Floor(s.x)), int(math.Floor(s.y))}
+		if !inTerrain(p) {
+			continue
+		}
+		if !visited[p] {
+			t.Fatalf("worm cell %v (chunk %v) is not connected to the start %v (chunk %v)", p, ChunkOf(p.X, p.Y), start, ChunkOf(start.X, start.Y))
+		}
+		reached++
+	}
+	if reached < len(path)/2 {
+		t.Fatalf("only %d of %d worm cells lie in the terrain", reached, len(path))
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("tunnel stays inside chunk %v", ChunkOf(start.X, start.Y))
+	}
+}
+
+func TestCaveEntranceOpensToSky(t *testing.T) {
+	tg := NewTerrainGenerator(TerrainSeed)
+	path := findWorm(t, tg, true)
+
+	// 从地表以上（y更小）的天空出发，经过洞口可以到达隧道深处
+	bounds := wormBounds(path)
+	sky := bounds.MinY
+	for x := bounds.MinX - 3; x <= bounds.MaxX+3; x++ {
+		sky = min(sky, tg.getHeight(x))
+	}
+	sky -= 20 // 高于树木
+	from, to := ChunkOf(bounds.MinX-3, sky), ChunkOf(bounds.MaxX+3, bounds.MaxY+3)
+	solid := solidCells(tg, from, to)
+	inside := func(p GridPos) bool {
+		return p.X >= bounds.MinX-3 && p.X <= bounds.MaxX+3 && p.Y >= sky && p.Y <= bounds.MaxY+3
+	}
+	var start []GridPos
+	for x := bounds.MinX - 3; x <= bounds.MaxX+3; x++ {
+		start = append(start, GridPos{x, sky})
+	}
+	visited := floodAir(solid, start, inside)
+
+	deep := 0
+	for _, s := range path {
+		p := GridPos{int(math.Floor(s.x)), int(math.Floor(s.y))}
+		if p.Y-tg.getHeight(p.X) >= tg.Caves.WormMinDepth && visited[p] {
+			deep++
+		}
+	}
+	if deep == 0 {
+		t.Fatalf("entrance at x=%v does not connect the sky to the tunnel below the surface", path[0].x)
+	}
+}
@@ -0,0 +1,179 @@
+package world
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// OreVein 一种矿石的分布：深度范围内矿脉噪声超过阈值的石头变为矿石
+type OreVein struct {
+	Type      ItemType
+	MinDepth  int     // 最浅深度（地表以下的格数）
+	MaxDepth  int     // 最深深度（不包含）
+	Threshold float64 // 矿脉噪声阈值，越大矿石越少
+	Scale     float64 // 噪声尺度，越大矿脉越小越分散
+}
+
+// defaultOreVeins 默认的矿石分布，按顺序检查，稀有的矿石优先
+var defaultOreVeins = []OreVein{
+	{Type: ItemTypeDiamondOre, MinDepth: 44, MaxDepth: TerrainDepth, Threshold: 0.5, Scale: 0.3},
+	{Type: ItemTypeGoldOre, MinDepth: 28, MaxDepth: TerrainDepth, Threshold: 0.45, Scale: 0.25},
+	{Type: ItemTypeIronOre, MinDepth: 10, MaxDepth: 48, Threshold: 0.36, Scale: 0.2},
+	{Type: ItemTypeCoalOre, MinDepth: 4, MaxDepth: 36, Threshold: 0.32, Scale: 0.15},
+}
+
+// biomeOreVeins 各地形类型特有的矿石分布，没有列出的地形使用defaultOreVeins
+var biomeOreVeins = map[TerrainType][]OreVein{
+	// 山脉矿石更浅也更多
+	TerrainTypeMountains: {
+		{Type: ItemTypeDiamondOre, MinDepth: 36, MaxDepth: TerrainDepth, Threshold: 0.46, Scale: 0.3},
+		{Type: ItemTypeGoldOre, MinDepth: 24, MaxDepth: TerrainDepth, Threshold: 0.42, Scale: 0.25},
+		{Type: ItemTypeIronOre, MinDepth: 4, MaxDepth: 48, Threshold: 0.32, Scale: 0.2},
+		{Type: ItemTypeCoalOre, MinDepth: 2, MaxDepth: 40, Threshold: 0.3, Scale: 0.15},
+	},
+	// 沙漠金矿较浅，煤矿稀少
+	TerrainTypeDesert: {
+		{Type: ItemTypeDiamondOre, MinDepth: 44, MaxDepth: TerrainDepth, Threshold: 0.5, Scale: 0.3},
+		{Type: ItemTypeGoldOre, MinDepth: 12, MaxDepth: TerrainDepth, Threshold: 0.42, Scale: 0.25},
+		{Type: ItemTypeIronOre, MinDepth: 10, MaxDepth: 48, Threshold: 0.36, Scale: 0.2},
+		{Type: ItemTypeCoalOre, MinDepth: 4, MaxDepth: 24, Threshold: 0.4, Scale: 0.15},
+	},
+}
+
+// oreVeins 返回地形类型的矿石分布
+func oreVeins(terrainType TerrainType) []OreVein {
+	if veins, ok := biomeOreVeins[terrainType]; ok {
+		return veins
+	}
+	return defaultOreVeins
+}
+
+// oreNoise 矿脉噪声，每种矿石使用独立的噪声通道，使不同矿石的矿脉互不重合
+func (tg *TerrainGenerator) oreNoise(x, y int, vein OreVein) float64 {
+	return fbm(tg.noises.ores[vein.Type], 2, 0.5, vein.Scale, float64(x), float64(y))
+}
+
+// oreAt 返回石头层中指定位置的矿石（depth为地表以下的格数），没有矿石时返回false
+func (tg *TerrainGenerator) oreAt(x, y, depth int, terrainType TerrainType) (ItemType, bool) {
+	for _, vein := range oreVeins(terrainType) {
+		if depth >= vein.MinDepth && depth < vein.MaxDepth && tg.oreNoise(x, y, vein) > vein.Threshold {
+			return vein.Type, true
+		}
+	}
+	return 0, false
+}
+
+// OreStatsBand 统计矿石分布时每个深度段的格数
+const OreStatsBand = 8
+
+// oreTypes 统计报告中矿石的列顺序
+var oreTypes = []ItemType{ItemTypeCoalOre, ItemTypeIronOre, ItemTypeGoldOre, ItemTypeDiamondOre}
+
+// OreStats 按深度段统计的石头层方块和矿石数量
+type OreStats struct {
+	Chunks  int                // 采样的区块列数
+	Columns int                // 统计的方块列数（过滤地形类型后）
+	Stone   []int              // 每个深度段石头层的方块数（包括矿石）
+	Ores    []map[ItemType]int // 每个深度段各种矿石的数量
+}
+
+// SampleOreStats 采样从fromChunk开始的chunks个区块列，统计地表以下的矿石分布，
+// biomes不为空时只统计这些地形类型的列
+func SampleOreStats(tg *TerrainGenerator, fromChunk, chunks int, biomes []TerrainType) *OreStats {
+	bands := (TerrainDepth + OreStatsBand - 1) / OreStatsBand
+	stats := &OreStats{Chunks: chunks, Stone: make([]int, bands), Ores: make([]map[ItemType]int, bands)}
+	for i := range stats.Ores {
+		stats.Ores[i] = make(map[ItemType]int)
+	}
+	for x := fromChunk * ChunkSize; x < (fromChunk+chunks)*ChunkSize; x++ {
+		terrainType := tg.getTerrainType(x)
+		if len(biomes) > 0 && !slices.Contains(biomes, terrainType) {
+			continue
+		}
+		stats.Columns++
+		height := tg.getHeight(x)
+		for depth := 0; depth < TerrainDepth; depth++ {
+			y := height + depth
+			if tg.hasCave(x, y, height) || tg.layerBlockType(x, y, height, terrainType) != ItemTypeStone {
+				continue
+			}
+			band := depth / OreStatsBand
+			stats.Stone[band]++
+			if t := tg.getBlockType(x, y, height, terrainType); t != ItemTypeStone {
+				stats.Ores[band][t]++
+			}
+		}
+	}
+	return stats
+}
+
+// Total 返回所有深度段中某种矿石的数量
+func (s *OreStats) Total(t ItemType) int {
+	n := 0
+	for _, ores := range s.Ores {
+		n += ores[t]
+	}
+	return n
+}
+
+// Write 以表格写出每个深度段石头层中各种矿石的比例
+func (s *OreStats) Write(w io.Writer) error {
+	fmt.Fprintf(w, "%d chunks, %d columns\n", s.Chunks, s.Columns)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"depth", "stone"}
+	for _, t := range oreTypes {
+		header = append(header, ItemRegistry[t].Name)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+
+	percent := func(n, total int) string {
+		if total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f%%", 100*float64(n)/float64(total))
+	}
+	stone := 0
+	for band, n := range s.Stone {
+		row := []string{fmt.Sprintf("%d-%d", band*OreStatsBand, min((band+1)*OreStatsBand, TerrainDepth)-1), fmt.Sprint(n)}
+		for _, t := range oreTypes {
+			row = append(row, percent(s.Ores[band][t], n))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+		stone += n
+	}
+	row := []string{"all", fmt.Sprint(stone)}
+	for _, t := range oreTypes {
+		row = append(row, percent(s.Total(t), stone))
+	}
+	fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	return tw.Flush()
+}
+
+// terrainTypeByName 按名称（不区分大小写）查找地形类型
+func terrainTypeByName(name string) (TerrainType, bool) {
+	for t, n := range terrainTypeNames {
+		if strings.EqualFold(n, name) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// ParseBiomes 解析逗号分隔的地形类型名称，空字符串表示所有地形
+func ParseBiomes(s string) ([]TerrainType, error) {
+	var biomes []TerrainType
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		t, ok := terrainTypeByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown terrain type %q", name)
+		}
+		biomes = append(biomes, t)
+	}
+	return biomes, nil
+}
@@ -0,0 +1,79 @@
+package world
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestOreDepthBands(t *testing.T) {
+	stats := SampleOreStats(NewTerrainGenerator(TerrainSeed), -100, 200, []TerrainType{TerrainTypePlains, TerrainTypeForest})
+	if stats.Columns == 0 {
+		t.Fatal("no plains or forest columns sampled")
+	}
+	for _, vein := range defaultOreVeins {
+		if stats.Total(vein.Type) == 0 {
+			t.Errorf("no %s generated", ItemRegistry[vein.Type].Name)
+		}
+		// 完全在深度范围之外的深度段没有这种矿石
+		for band, ores := range stats.Ores {
+			from, to := band*OreStatsBand, (band+1)*OreStatsBand
+			if (to <= vein.MinDepth || from >= vein.MaxDepth) && ores[vein.Type] != 0 {
+				t.Errorf("%d %s at depth %d-%d, outside %d-%d", ores[vein.Type], ItemRegistry[vein.Type].Name, from, to-1, vein.MinDepth, vein.MaxDepth-1)
+			}
+		}
+	}
+	if coal, diamond := stats.Total(ItemTypeCoalOre), stats.Total(ItemTypeDiamondOre); diamond >= coal {
+		t.Errorf("diamond ore (%d) is not rarer than coal ore (%d)", diamond, coal)
+	}
+}
+
+func TestOreBiomeVeins(t *testing.T) {
+	tg := NewTerrainGenerator(TerrainSeed)
+	// 沙漠的金矿比默认分布浅
+	var desert, plains int
+	for x := 0; x < 500; x++ {
+		for depth := 12; depth < 28; depth++ {
+			if ore, ok := tg.oreAt(x, depth, depth, TerrainTypeDesert); ok && ore == ItemTypeGoldOre {
+				desert++
+			}
+			if ore, ok := tg.oreAt(x, depth, depth, TerrainTypePlains); ok && ore == ItemTypeGoldOre {
+				plains++
+			}
+		}
+	}
+	if desert == 0 || plains != 0 {
+		t.Errorf("gold ore at depth 12-27: %d in desert, %d in plains; want some in desert only", desert, plains)
+	}
+
+	// 矿石只替换石头层，地表和泥土层不变
+	for x := 0; x < 200; x++ {
+		height := tg.getHeight(x)
+		if height >= SeaLevel-BeachHeight {
+			continue // 海平面附近的地表为沙滩
+		}
+		if got := tg.getBlockType(x, height, height, TerrainTypePlains); got != ItemTypeGrass {
+			t.Fatalf("surface block at x=%d is %v, want grass", x, got)
+		}
+	}
+}
+
+func TestOreStatsWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SampleOreStats(NewTerrainGenerator(TerrainSeed), 0, 4, nil).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"4 chunks, 40 columns", "Diamond Ore", "0-7", "56-63", "all"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := ParseBiomes("desert, Snowy Plains"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseBiomes("volcano"); err == nil {
+		t.Error("ParseBiomes accepted an unknown terrain type")
+	}
+}
@@ -0,0 +1,290 @@
+package world
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// 结构生成常量（方块坐标）
+const (
+	StructureSpacing    = 48 // 每个结构区域的宽度，每个区域最多一个地面结构和一个地下结构
+	StructureMargin     = 2  // 结构与区域边缘的最小距离，保证相邻区域的结构不重叠
+	StructureSpawnClear = 8  // 出生点左右这个范围内不生成结构
+)
+
+// Prefab 预制结构的方块布局
+//
+// 行按世界y递增（从上到下）排列，与字符画的阅读顺序相同。
+// 地面结构的最后Base行埋在地下，其上一行紧贴地面。
+type Prefab struct {
+	W, H   int
+	Blocks map[GridPos]ItemType
+	Clear  map[GridPos]bool // 放置时清空的格子（结构内部的空气）
+}
+
+// parsePrefab 由字符画创建预制结构：'.'为清空的空气，' '保留原有地形，其余字符按palette映射
+func parsePrefab(rows []string, palette map[rune]ItemType) *Prefab {
+	p := &Prefab{H: len(rows), Blocks: make(map[GridPos]ItemType), Clear: make(map[GridPos]bool)}
+	for y, row := range rows {
+		for x, r := range []rune(row) {
+			p.W = max(p.W, x+1)
+			switch r {
+			case ' ':
+			case '.':
+				p.Clear[GridPos{x, y}] = true
+			default:
+				t, ok := palette[r]
+				if !ok {
+					panic("prefab: no palette entry for " + string(r))
+				}
+				p.Blocks[GridPos{x, y}] = t
+			}
+		}
+	}
+	return p
+}
+
+// Structure 一种可生成的结构
+type Structure struct {
+	Name        string
+	Prefab      *Prefab
+	Biomes      []TerrainType // 可以生成的地形类型
+	Chance      float64       // 选中后实际生成的概率
+	Underground bool          // 地下结构：顶部位于地表以下Depth格
+	Base        int           // 地面结构埋入地下的行数（从底部算起）
+	Depth       int
+}
+
+// 预制结构的调色板
+var prefabPalette = map[rune]ItemType{
+	'W': ItemTypeWood,
+	'S': ItemTypeStone,
+	's': ItemTypeSand,
+	'D': ItemTypeDirt,
+	'~': ItemTypeWater,
+	'L': ItemTypeLava,
+}
+
+// 地面结构
+var surfaceStructures = []*Structure{
+	{
+		Name: "hut",
+		Prefab: parsePrefab([]string{
+			"  WWW  ", // 屋顶
+			" WWWWW ",
+			"W.....W",
+			"......W", // 门
+			"......W",
+			"WWWWWWW", // 地板
+		}, prefabPalette),
+		Biomes: []TerrainType{TerrainTypePlains, TerrainTypeForest, TerrainTypeTaiga, TerrainTypeSnowyPlains},
+		Chance: 0.5,
+	},
+	{
+		Name: "ruins",
+		Prefab: parsePrefab([]string{
+			"S",
+			"S       ",
+			"S    S  S",
+			"S. ..S..S",
+			"SSSSSSSSS",
+		}, prefabPalette),
+		Biomes: []TerrainType{TerrainTypePlains, TerrainTypeSavanna, TerrainTypeHills, TerrainTypeMountains},
+		Chance: 0.4,
+	},
+	{
+		Name: "well",
+		Prefab: parsePrefab([]string{
+			"WWWWW",
+			"W...W",
+			"s...s",
+			"s~~~s", // 地面
+			"S~~~S",
+			"S~~~S",
+			"SSSSS", // 井底
+		}, prefabPalette),
+		Biomes: []TerrainType{TerrainTypeDesert, TerrainTypeSavanna},
+		Chance: 0.6,
+		Base:   4,
+	},
+}
+
+// 地下结构
+var undergroundStructures = []*Structure{
+	{
+		Name: "dungeon",
+		Prefab: parsePrefab([]string{
+			"SSSSSSSSS",
+			"S.......S",
+			"SL.....LS",
+			"S.......S",
+			"SSSSSSSSS",
+		}, prefabPalette),
+		Biomes: []TerrainType{
+			TerrainTypePlains, TerrainTypeForest, TerrainTypeHills, TerrainTypeMountains,
+			TerrainTypeSnowyPlains, TerrainTypeDesert, TerrainTypeSavanna,
+		},
+		Chance:      0.35,
+		Underground: true,
+		Depth:       3,
+	},
+}
+
+// StructureByName 按名称查找结构
+func StructureByName(name string) (*Structure, bool) {
+	for _, list := range [][]*Structure{surfaceStructures, undergroundStructures} {
+		for _, s := range list {
+			if s.Name == name {
+				return s, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// PlacedStructure 确定了位置的结构，Origin为预制结构(0, 0)格的方块坐标
+type PlacedStructure struct {
+	*Structure
+	Origin GridPos
+}
+
+// Bounds 返回结构占据的格子范围
+func (ps PlacedStructure) Bounds() Rect {
+	p := ps.Prefab
+	return Rect{ps.Origin.X, ps.Origin.Y, ps.Origin.X + p.W - 1, ps.Origin.Y + p.H - 1}
+}
+
+// structureRNG 返回结构区域某一槽位的随机数生成器，只由种子、区域和槽位决定，
+// 因此与区块的生成顺序无关
+func structureRNG(seed int64, region, slot int) *rand.Rand {
+	h := uint64(seed) ^ uint64(int64(region))*0x9E3779B97F4A7C15 ^ uint64(slot)*0xBF58476D1CE4E5B9
+	// splitmix64终结函数，使相邻区域的种子充分打散
+	h ^= h >> 30
+	h *= 0xBF58476D1CE4E5B9
+	h ^= h >> 27
+	h *= 0x94D049BB133111EB
+	h ^= h >> 31
+	return rand.New(rand.NewSource(int64(h)))
+}
+
+// StructuresInRegion 返回区域内生成的结构（最多一个地面结构和一个地下结构）
+func (tg *TerrainGenerator) StructuresInRegion(region int) []PlacedStructure {
+	var placed []PlacedStructure
+	for slot, list := range [][]*Structure{surfaceStructures, undergroundStructures} {
+		rng := structureRNG(tg.seed, region, slot)
+
+		// 先确定位置，再按该位置的地形类型选择结构
+		maxW := 0
+		for _, s := range list {
+			maxW = max(maxW, s.Prefab.W)
+		}
+		x := region*StructureSpacing + StructureMargin + rng.Intn(StructureSpacing-2*StructureMargin-maxW+1)
+		biome := tg.getTerrainType(x)
+		var candidates []*Structure
+		for _, s := range list {
+			if slices.Contains(s.Biomes, biome) {
+				candidates = append(candidates, s)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		s := candidates[rng.Intn(len(candidates))]
+		if rng.Float64() >= s.Chance {
+			continue
+		}
+		if x <= StructureSpawnClear && x+s.Prefab.W-1 >= -StructureSpawnClear {
+			continue
+		}
+
+		// 地面结构放在占据各列的最高地面（最小的行）之上，地下结构放在起点列地表以下
+		ps := PlacedStructure{Structure: s}
+		if s.Underground {
+			ps.Origin = GridPos{x, tg.getHeight(x) + s.Depth}
+		} else {
+			ground := tg.getHeight(x)
+			for dx := 1; dx < s.Prefab.W; dx++ {
+				ground = min(ground, tg.getHeight(x+dx))
+			}
+			ps.Origin = GridPos{x, ground + s.Base - s.Prefab.H}
+		}
+		placed = append(placed, ps)
+	}
+	return placed
+}
+
+// structuresInRange 返回与minX~maxX列相交的结构
+func (tg *TerrainGenerator) structuresInRange(minX, maxX int) []PlacedStructure {
+	var out []PlacedStructure
+	for region := FloorDiv(minX, StructureSpacing); region <= FloorDiv(maxX, StructureSpacing); region++ {
+		for _, ps := range tg.StructuresInRegion(region) {
+			if b := ps.Bounds(); b.MaxX >= minX && b.MinX <= maxX {
+				out = append(out, ps)
+			}
+		}
+	}
+	return out
+}
+
+// structureLayout 区块各列上结构占据的格子
+type structureLayout struct {
+	claimed map[GridPos]bool // 结构的方块和空气格，地形不在这些格子生成
+	columns map[int]bool     // 有地面结构的列，不生成树木和仙人掌
+}
+
+// layoutStructures 汇总结构占据的格子
+func layoutStructures(structures []PlacedStructure) structureLayout {
+	layout := structureLayout{claimed: make(map[GridPos]bool), columns: make(map[int]bool)}
+	for _, ps := range structures {
+		for p := range ps.Prefab.Blocks {
+			layout.claimed[GridPos{ps.Origin.X + p.X, ps.Origin.Y + p.Y}] = true
+		}
+		for p := range ps.Prefab.Clear {
+			layout.claimed[GridPos{ps.Origin.X + p.X, ps.Origin.Y + p.Y}] = true
+		}
+		if !ps.Underground {
+			for x := 0; x < ps.Prefab.W; x++ {
+				layout.columns[ps.Origin.X+x] = true
+			}
+		}
+	}
+	return layout
+}
+
+// blocksClaimed 判断方块是否与结构占据的格子重叠
+func (l structureLayout) blocksClaimed(block Block) bool {
+	for _, p := range BlockCells(block) {
+		if l.claimed[p] {
+			return true
+		}
+	}
+	return false
+}
+
+// structureBlocks 返回结构落在区块内的方块
+//
+// 结构可能跨越多个区块，每个方块只由所在的区块生成，因此不会重复放置，
+// 结果也与区块的生成顺序无关。
+func structureBlocks(structures []PlacedStructure, chunkX, chunkY int) []Block {
+	bounds := Rect{chunkX * ChunkSize, chunkY * ChunkSize, chunkX*ChunkSize + ChunkSize - 1, chunkY*ChunkSize + ChunkSize - 1}
+	var blocks []Block
+	for _, ps := range structures {
+		for y := 0; y < ps.Prefab.H; y++ {
+			for x := 0; x < ps.Prefab.W; x++ {
+				t, ok := ps.Prefab.Blocks[GridPos{x, y}]
+				cell := GridPos{ps.Origin.X + x, ps.Origin.Y + y}
+				if !ok || !bounds.Contains(cell) {
+					continue
+				}
+				blocks = append(blocks, Block{
+					X:    float64(cell.X) * BlockSize,
+					Y:    float64(cell.Y) * BlockSize,
+					W:    BlockSize,
+					H:    BlockSize,
+					Type: t,
+				})
+			}
+		}
+	}
+	return blocks
+}
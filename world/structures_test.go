@@ -0,0 +1,183 @@
+package world
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// searchRegions 测试中向出生点两侧搜索结构的区域数
+const searchRegions = 200
+
+// chunkSignature 返回区块方块的排序后文本，便于比较两次生成的结果
+func chunkSignature(chunk *Chunk) string {
+	blocks := append([]Block(nil), chunk.Blocks...)
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].X != blocks[j].X {
+			return blocks[i].X < blocks[j].X
+		}
+		return blocks[i].Y < blocks[j].Y
+	})
+	return fmt.Sprint(blocks)
+}
+
+// firstStructure 返回出生点右侧第一个指定结构
+func firstStructure(t *testing.T, tg *TerrainGenerator, name string) PlacedStructure {
+	t.Helper()
+	for region := 0; region < searchRegions; region++ {
+		for _, ps := range tg.StructuresInRegion(region) {
+			if ps.Name == name {
+				return ps
+			}
+		}
+	}
+	t.Fatalf("no %s within %d regions", name, searchRegions)
+	return PlacedStructure{}
+}
+
+func TestStructuresDeterministic(t *testing.T) {
+	a, b := NewTerrainGenerator(TerrainSeed), NewTerrainGenerator(TerrainSeed)
+	counts := make(map[string]int)
+	for region := -searchRegions; region < searchRegions; region++ {
+		pa, pb := a.StructuresInRegion(region), b.StructuresInRegion(region)
+		if fmt.Sprint(pa) != fmt.Sprint(pb) {
+			t.Fatalf("region %d: %v vs %v", region, pa, pb)
+		}
+		for _, ps := range pa {
+			counts[ps.Name]++
+			// 结构不超出所在区域，相邻区域的结构不会重叠
+			bounds := ps.Bounds()
+			if bounds.MinX < region*StructureSpacing || bounds.MaxX >= (region+1)*StructureSpacing {
+				t.Errorf("%s at %v leaves region %d", ps.Name, bounds, region)
+			}
+			if bounds.MinX <= StructureSpawnClear && bounds.MaxX >= -StructureSpawnClear {
+				t.Errorf("%s at %v overlaps the spawn area", ps.Name, bounds)
+			}
+		}
+	}
+	for _, name := range []string{"hut", "ruins", "well", "dungeon"} {
+		if counts[name] == 0 {
+			t.Errorf("no %s generated in %d regions", name, 2*searchRegions)
+		}
+	}
+
+	// 不同种子生成不同的结构
+	other := NewTerrainGenerator(TerrainSeed + 1)
+	same := true
+	for region := 1; region < 20 && same; region++ {
+		same = fmt.Sprint(a.StructuresInRegion(region)) == fmt.Sprint(other.StructuresInRegion(region))
+	}
+	if same {
+		t.Fatal("structures do not depend on the seed")
+	}
+}
+
+func TestStructureChunkOrder(t *testing.T) {
+	ps := firstStructure(t, NewTerrainGenerator(TerrainSeed), "hut")
+	bounds := ps.Bounds()
+	from, to := ChunkOf(bounds.MinX, bounds.MinY), ChunkOf(bounds.MaxX, bounds.MaxY)
+
+	// 结构所在及左右相邻的区块
+	var positions []GridPos
+	for cx := from.X - 1; cx <= to.X+1; cx++ {
+		for cy := -3; cy <= 3; cy++ {
+			positions = append(positions, GridPos{cx, cy})
+		}
+	}
+	generate := func(order []GridPos) map[GridPos]*Chunk {
+		tg := NewTerrainGenerator(TerrainSeed)
+		out := make(map[GridPos]*Chunk)
+		for _, p := range order {
+			out[p] = tg.GenerateChunk(p.X, p.Y)
+		}
+		return out
+	}
+
+	forward := generate(positions)
+	shuffled := append([]GridPos(nil), positions...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	reordered := generate(shuffled)
+	for _, p := range positions {
+		if chunkSignature(forward[p]) != chunkSignature(reordered[p]) {
+			t.Fatalf("chunk %v differs between generation orders", p)
+		}
+	}
+
+	// 每个结构方块只由一个区块生成，结构内部的空气没有地形
+	cells := make(map[GridPos][]ItemType)
+	for _, chunk := range forward {
+		for _, block := range chunk.Blocks {
+			for _, c := range BlockCells(block) {
+				cells[c] = append(cells[c], block.Type)
+			}
+		}
+	}
+	var checked int
+	for p, typ := range ps.Prefab.Blocks {
+		cell := GridPos{ps.Origin.X + p.X, ps.Origin.Y + p.Y}
+		if cy := ChunkOf(cell.X, cell.Y).Y; cy < -3 || cy > 3 {
+			continue
+		}
+		checked++
+		if got := cells[cell]; len(got) != 1 || got[0] != typ {
+			t.Errorf("structure cell %v has blocks %v, want exactly one %v", cell, got, typ)
+		}
+	}
+	if checked == 0 {
+		t.Fatal("structure lies outside the generated chunks")
+	}
+	for p := range ps.Prefab.Clear {
+		cell := GridPos{ps.Origin.X + p.X, ps.Origin.Y + p.Y}
+		if got := cells[cell]; len(got) != 0 {
+			t.Errorf("structure interior %v has blocks %v, want air", cell, got)
+		}
+	}
+}
+
+func TestTreeCanopyNextToStructure(t *testing.T) {
+	// 种子302的小屋紧挨区块-116的右边缘，区块边缘列上的树冠伸向小屋
+	tg := NewTerrainGenerator(302)
+	var hut PlacedStructure
+	for _, ps := range tg.StructuresInRegion(FloorDiv(-1150, StructureSpacing)) {
+		if ps.Name == "hut" {
+			hut = ps
+		}
+	}
+	if hut.Origin != (GridPos{-1150, -10}) {
+		t.Fatalf("hut at %v, want (-1150, -10)", hut.Origin)
+	}
+
+	claimed := layoutStructures([]PlacedStructure{hut}).claimed
+	trunk := false
+	for cy := -2; cy <= 0; cy++ {
+		for _, block := range tg.GenerateChunk(-116, cy).Blocks {
+			if block.Type == ItemTypeWood && block.X == -1151*BlockSize {
+				trunk = true
+			}
+			for _, c := range BlockCells(block) {
+				if claimed[c] {
+					t.Errorf("chunk (-116, %d) block %v overlaps the hut at %v", cy, block, c)
+				}
+			}
+		}
+	}
+	if !trunk {
+		t.Error("no tree on the chunk edge next to the hut")
+	}
+}
+
+func TestParsePrefab(t *testing.T) {
+	p := parsePrefab([]string{"S.S", " W"}, prefabPalette)
+	if p.W != 3 || p.H != 2 {
+		t.Fatalf("size %dx%d, want 3x2", p.W, p.H)
+	}
+	var solid []string
+	for c, typ := range p.Blocks {
+		solid = append(solid, fmt.Sprint(c, ItemRegistry[typ].Name))
+	}
+	sort.Strings(solid)
+	if fmt.Sprint(solid) != "[{0 0}Stone {1 1}Wood {2 0}Stone]" || len(p.Clear) != 1 || !p.Clear[GridPos{1, 0}] {
+		t.Fatalf("blocks %v, clear %v", solid, p.Clear)
+	}
+}
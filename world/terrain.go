@@ -0,0 +1,420 @@
+package world
+
+import (
+	"math"
+	"sync"
+
+	"2d.go/noise"
+)
+
+// spawnClearRadius 出生点左右这个范围内（方块）的地表之上不生成树木和仙人掌
+const spawnClearRadius = 3
+
+// terrainNoises 地形生成各用途的噪声通道，由世界种子按名称派生，互相独立
+//
+// 只随x变化的通道沿y=0采样单纯形噪声：Perlin噪声在整数格点处为0，
+// 沿坐标轴采样时每隔一个格子就会回到0。
+type terrainNoises struct {
+	height, detail, mountains, continental noise.Noise2D
+	trees, treeHeight, cactus, ponds       noise.Noise2D
+	caverns, aquifers                      noise.Noise2D
+	ores                                   map[ItemType]noise.Noise2D // 每种矿石的矿脉
+}
+
+// newTerrainNoises 创建种子对应的噪声通道
+func newTerrainNoises(seed int64) terrainNoises {
+	c := noise.Channels{Seed: seed}
+	n := terrainNoises{
+		height:      c.Simplex("height"),
+		detail:      c.Simplex("detail"),
+		mountains:   c.Simplex("mountains"),
+		continental: c.Simplex("continental"),
+		trees:       c.Simplex("trees"),
+		treeHeight:  c.Simplex("tree height"),
+		cactus:      c.Simplex("cactus"),
+		ponds:       c.Simplex("ponds"),
+		caverns:     c.Perlin("caverns"),
+		aquifers:    c.Perlin("aquifers"),
+		ores:        make(map[ItemType]noise.Noise2D),
+	}
+	for _, t := range oreTypes {
+		n.ores[t] = c.Perlin("ore " + ItemRegistry[t].Name)
+	}
+	return n
+}
+
+// fbm 以scale为基础频率叠加octaves层噪声，每层频率加倍、振幅乘以persistence
+func fbm(n noise.Noise2D, octaves int, persistence, scale, x, y float64) float64 {
+	return noise.Octaves(octaves, persistence).FBM2D(n, x*scale, y*scale)
+}
+
+// TerrainGenerator 地形生成器
+type TerrainGenerator struct {
+	noises terrainNoises
+	seed   int64
+	Caves  CaveParams // 洞穴生成参数，生成区块前可以修改
+
+	HeightScale float64 // 地形起伏倍数（放大化世界大于1）
+	SingleBiome bool    // 所有列都使用FixedBiome（单一地形世界）
+	FixedBiome  TerrainType
+
+	wormMu sync.Mutex
+	worms  map[int]map[GridPos]bool // 区域 -> 该区域蠕虫挖出的格子
+}
+
+// NewTerrainGenerator 创建新的地形生成器
+func NewTerrainGenerator(seed int64) *TerrainGenerator {
+	return &TerrainGenerator{
+		noises:      newTerrainNoises(seed),
+		seed:        seed,
+		Caves:       DefaultCaveParams(),
+		HeightScale: 1,
+	}
+}
+
+// Seed 返回地形种子
+func (tg *TerrainGenerator) Seed() int64 {
+	return tg.seed
+}
+
+// getHeight 获取指定位置的高度
+func (tg *TerrainGenerator) getHeight(x int) int {
+	// 基础地形高度，调整垂直偏移使地面更接近玩家出生点
+	baseHeight := fbm(tg.noises.height, 4, 0.5, 0.01, float64(x), 0) * 20
+
+	// 添加细节变化
+	detail := fbm(tg.noises.detail, 3, 0.6, 0.05, float64(x), 0) * 5
+
+	// 添加山脉
+	mountains := 0.0
+	if val := fbm(tg.noises.mountains, 2, 0.7, 0.005, float64(x), 0); val > 0.6 {
+		mountains = val * 20
+	}
+
+	// 起伏越高地表所在的行越小（y向下增大）；调整整体高度偏移，使地面更适合玩家出生，
+	// 海岸和海洋区域向下沉到海平面以下
+	return tg.coastHeight(x, 5-int((baseHeight+detail+mountains)*tg.HeightScale))
+}
+
+// ContinentalNoise 决定地形类型的大尺度噪声
+func (tg *TerrainGenerator) ContinentalNoise(x int) float64 {
+	return fbm(tg.noises.continental, 3, 0.5, 0.005, float64(x), 0)
+}
+
+// CaveNoise 决定大型洞窟位置的噪声
+func (tg *TerrainGenerator) CaveNoise(x, y int) float64 {
+	return fbm(tg.noises.caverns, 3, 0.5, tg.Caves.CavernScale, float64(x), float64(y))
+}
+
+// getTerrainType 获取指定位置的地形类型
+func (tg *TerrainGenerator) getTerrainType(x int) TerrainType {
+	if tg.SingleBiome {
+		return tg.FixedBiome
+	}
+
+	// 使用不同的噪声尺度获取地形类型
+	continental := tg.ContinentalNoise(x)
+
+	switch {
+	case continental < OceanContinental:
+		return TerrainTypeOcean
+	case continental < BeachContinental:
+		return TerrainTypeBeach
+	case continental < -0.3:
+		return TerrainTypeDesert
+	case continental < -0.2:
+		return TerrainTypeSavanna
+	case continental < 0:
+		return TerrainTypePlains
+	case continental < 0.2:
+		return TerrainTypeForest
+	case continental < 0.4:
+		return TerrainTypeHills
+	case continental < 0.6:
+		return TerrainTypeMountains
+	default:
+		return TerrainTypeSnowyPlains
+	}
+}
+
+// getBlockType 获取指定位置和高度的方块类型，石头层中按矿脉分布替换为矿石
+func (tg *TerrainGenerator) getBlockType(x, y, height int, terrainType TerrainType) ItemType {
+	blockType := tg.layerBlockType(x, y, height, terrainType)
+	if blockType == ItemTypeStone {
+		if ore, ok := tg.oreAt(x, y, y-height, terrainType); ok {
+			return ore
+		}
+	}
+	return blockType
+}
+
+// layerBlockType 获取地形分层（地表、泥土、石头）中的方块类型
+func (tg *TerrainGenerator) layerBlockType(x, y, height int, terrainType TerrainType) ItemType {
+	depth := y - height
+
+	// 海底、湖底和海平面附近的地表为沙子
+	if height >= SeaLevel-BeachHeight && depth < 3 {
+		return ItemTypeSand
+	}
+
+	switch terrainType {
+	case TerrainTypeDesert, TerrainTypeOcean:
+		if depth < 3 {
+			return ItemTypeSand
+		}
+		return ItemTypeStone
+
+	case TerrainTypeBeach:
+		if depth < 4 {
+			return ItemTypeSand
+		}
+		return ItemTypeStone
+
+	case TerrainTypeSavanna:
+		if depth == 0 {
+			return ItemTypeGrass
+		} else if depth < 4 {
+			return ItemTypeDirt
+		}
+		return ItemTypeStone
+
+	case TerrainTypePlains:
+		if depth == 0 {
+			return ItemTypeGrass
+		} else if depth < 3 {
+			return ItemTypeDirt
+		}
+		return ItemTypeStone
+
+	case TerrainTypeForest:
+		if depth == 0 {
+			return ItemTypeGrass
+		} else if depth < 3 {
+			return ItemTypeDirt
+		}
+		return ItemTypeStone
+
+	case TerrainTypeHills:
+		if depth == 0 {
+			return ItemTypeGrass
+		} else if depth < 5 {
+			return ItemTypeDirt
+		}
+		return ItemTypeStone
+
+	case TerrainTypeMountains:
+		if depth == 0 {
+			if y < -5 {
+				return ItemTypeSnow
+			}
+			return ItemTypeStone
+		} else if depth < 3 {
+			return ItemTypeStone
+		}
+		return ItemTypeStone
+
+	case TerrainTypeSnowyPlains:
+		if depth == 0 {
+			return ItemTypeSnow
+		} else if depth < 3 {
+			return ItemTypeDirt
+		}
+		return ItemTypeStone
+
+	default:
+		if depth == 0 {
+			return ItemTypeGrass
+		} else if depth < 3 {
+			return ItemTypeDirt
+		}
+		return ItemTypeStone
+	}
+}
+
+// hasTree 判断指定位置是否有树
+func (tg *TerrainGenerator) hasTree(x, height int, terrainType TerrainType) bool {
+	treeNoise := fbm(tg.noises.trees, 2, 0.5, 0.05, float64(x), 0)
+
+	switch terrainType {
+	case TerrainTypeForest:
+		return treeNoise > 0.6 && height <= 0
+	case TerrainTypeJungle:
+		return treeNoise > 0.5 && height <= 0
+	case TerrainTypeTaiga:
+		return treeNoise > 0.55 && height <= 2
+	default:
+		return false
+	}
+}
+
+// getTreeHeight 获取树的高度
+func (tg *TerrainGenerator) getTreeHeight(x int, terrainType TerrainType) int {
+	treeNoise := fbm(tg.noises.treeHeight, 2, 0.5, 0.1, float64(x), 0)
+
+	switch terrainType {
+	case TerrainTypeForest:
+		return 4 + int(treeNoise*4)
+	case TerrainTypeJungle:
+		return 6 + int(treeNoise*6)
+	case TerrainTypeTaiga:
+		return 5 + int(treeNoise*3)
+	default:
+		return 3 + int(treeNoise*3)
+	}
+}
+
+// GenerateChunk 生成地形区块
+func (tg *TerrainGenerator) GenerateChunk(chunkX, chunkY int) *Chunk {
+	chunk := &Chunk{
+		X: chunkX,
+		Y: chunkY,
+	}
+
+	// 区块覆盖的方块行，每个区块只生成左上角落在这些行内的方块
+	minY := chunkY * ChunkSize
+	maxY := minY + ChunkSize - 1
+
+	// 与本区块各列及两侧相交的结构，地形、树木等不在结构占据的格子生成
+	// （两侧的范围覆盖最宽的树冠，边缘列上的树冠可能伸入相邻区块的结构）
+	structures := tg.structuresInRange(chunkX*ChunkSize-2, chunkX*ChunkSize+ChunkSize+1)
+	layout := layoutStructures(structures)
+
+	// 为每个X坐标生成地形
+	for x := 0; x < ChunkSize; x++ {
+		worldX := chunkX*ChunkSize + x
+
+		// 获取地形高度和类型
+		height := tg.getHeight(worldX)
+		terrainType := tg.getTerrainType(worldX)
+
+		// 计算方块X坐标
+		blockX := float64(worldX * BlockSize)
+
+		// 出生点（x=0）附近的地表之上不生成树木和仙人掌，玩家出生后可以站立和行走
+		isNearPlayerSpawn := math.Abs(blockX) <= spawnClearRadius*BlockSize
+
+		// 生成地形柱（从地表向下TerrainDepth格，只取落在本区块内的部分）
+		for y := max(height, minY); y < min(height+TerrainDepth, maxY+1); y++ {
+			blockY := float64(y * BlockSize)
+
+			// 获取方块类型，洞穴为空气，被淹没的洞穴充满水
+			var blockType ItemType
+			if tg.hasCave(worldX, y, height) {
+				if !tg.floodedCave(worldX, y, height) {
+					continue
+				}
+				blockType = ItemTypeWater
+			} else {
+				blockType = tg.getBlockType(worldX, y, height, terrainType)
+			}
+
+			// 添加方块到区块
+			chunk.Blocks = append(chunk.Blocks, Block{
+				X:    blockX,
+				Y:    blockY,
+				W:    BlockSize,
+				H:    BlockSize,
+				Type: blockType,
+			})
+		}
+
+		// 海平面以下的地表以上充满水（海洋和湖泊）
+		chunk.Blocks = append(chunk.Blocks, waterBlocks(worldX, height, minY, maxY)...)
+
+		// 生成树木
+		if tg.hasTree(worldX, height, terrainType) && !isNearPlayerSpawn && !layout.columns[worldX] {
+			treeHeight := tg.getTreeHeight(worldX, terrainType)
+
+			// 生成树干
+			for i := 1; i <= treeHeight; i++ {
+				chunk.Blocks = append(chunk.Blocks, Block{
+					X:    blockX,
+					Y:    float64(height-i) * BlockSize,
+					W:    BlockSize,
+					H:    BlockSize,
+					Type: ItemTypeWood,
+				})
+			}
+
+			// 生成树叶
+			switch terrainType {
+			case TerrainTypeForest, TerrainTypeJungle:
+				// 简单的树冠
+				chunk.Blocks = append(chunk.Blocks, Block{
+					X:    blockX - BlockSize,
+					Y:    float64(height-treeHeight-1) * BlockSize,
+					W:    BlockSize * 3,
+					H:    BlockSize,
+					Type: ItemTypeGrass,
+				})
+
+				if terrainType == TerrainTypeJungle && treeHeight > 6 {
+					chunk.Blocks = append(chunk.Blocks, Block{
+						X:    blockX - BlockSize,
+						Y:    float64(height-treeHeight+2) * BlockSize,
+						W:    BlockSize * 3,
+						H:    BlockSize,
+						Type: ItemTypeGrass,
+					})
+				}
+
+			case TerrainTypeTaiga:
+				// 针叶树冠（下宽上窄）
+				for i := 0; i < 3; i++ {
+					chunk.Blocks = append(chunk.Blocks, Block{
+						X:    blockX - float64(2-i)*BlockSize/2,
+						Y:    float64(height-treeHeight+1-i) * BlockSize,
+						W:    BlockSize * float64(3-i),
+						H:    BlockSize,
+						Type: ItemTypeGrass,
+					})
+				}
+			}
+		}
+
+		// 在特定地形生成特殊元素
+		switch terrainType {
+		case TerrainTypeDesert:
+			// 生成仙人掌
+			cactusNoise := fbm(tg.noises.cactus, 2, 0.5, 0.1, float64(worldX), 0)
+			if cactusNoise > 0.7 && height <= 0 && !isNearPlayerSpawn && !layout.columns[worldX] {
+				cactusHeight := 1 + int(cactusNoise*3)
+				for i := 1; i <= cactusHeight; i++ {
+					chunk.Blocks = append(chunk.Blocks, Block{
+						X:    blockX,
+						Y:    float64(height-i) * BlockSize,
+						W:    BlockSize,
+						H:    BlockSize,
+						Type: ItemTypeSand,
+					})
+				}
+			}
+
+		case TerrainTypeSwamp:
+			// 生成水池
+			waterNoise := fbm(tg.noises.ponds, 2, 0.5, 0.1, float64(worldX), 0)
+			if waterNoise > 0.6 && height <= 1 {
+				chunk.Blocks = append(chunk.Blocks, Block{
+					X:    blockX,
+					Y:    float64(height) * BlockSize,
+					W:    BlockSize,
+					H:    BlockSize,
+					Type: ItemTypeWater,
+				})
+			}
+		}
+	}
+
+	// 移除伸出本区块行的方块（树木、仙人掌）和与结构重叠的方块（包括相邻列伸入结构的树冠），
+	// 再放置落在本区块内的结构方块
+	kept := chunk.Blocks[:0]
+	for _, block := range chunk.Blocks {
+		y := int(math.Floor(block.Y / BlockSize))
+		if y >= minY && y <= maxY && !layout.blocksClaimed(block) {
+			kept = append(kept, block)
+		}
+	}
+	chunk.Blocks = append(kept, structureBlocks(structures, chunkX, chunkY)...)
+
+	return chunk
+}
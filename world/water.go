@@ -0,0 +1,65 @@
+package world
+
+// 海洋与水体生成常量（方块坐标，y向下增大）
+const (
+	SeaLevel    = 10 // 海平面：地表低于海平面（行大于SeaLevel）的列，地表以上直到海平面充满水
+	BeachHeight = 1  // 地表不高于海平面以上这么多格时为沙滩
+
+	// 大陆噪声低于BeachContinental的区域地形逐渐下沉，低于OceanContinental为海洋
+	BeachContinental = -0.38
+	OceanContinental = -0.45
+	OceanSlope       = 100.0 // 大陆噪声每降低1，地形下沉的格数
+
+	AquiferMinDepth  = 16  // 含水层的最浅深度（地表以下的格数）
+	AquiferThreshold = 0.3 // 含水层噪声阈值，越大含水层越少
+)
+
+// coastHeight 调整海岸和海洋区域的地表高度：大陆噪声越低地形下沉越多，
+// 使海岸线平缓地过渡到海底，海洋的地表总在海平面以下
+func (tg *TerrainGenerator) coastHeight(x, height int) int {
+	if tg.SingleBiome {
+		// 单一地形世界没有海岸，海洋世界整体沉到海平面以下
+		if tg.FixedBiome == TerrainTypeOcean {
+			return max(height+8, SeaLevel+1)
+		}
+		return height
+	}
+	continental := tg.ContinentalNoise(x)
+	if continental >= BeachContinental {
+		return height
+	}
+	height += int((BeachContinental - continental) * OceanSlope)
+	if continental < OceanContinental {
+		height = max(height, SeaLevel+1)
+	}
+	return height
+}
+
+// aquiferNoise 决定地下含水层位置的噪声
+func (tg *TerrainGenerator) aquiferNoise(x, y int) float64 {
+	return fbm(tg.noises.aquifers, 2, 0.5, 0.05, float64(x), float64(y))
+}
+
+// floodedCave 判断洞穴格子是否充满水：海底和湖底下方的洞穴被淹没，
+// 较深处含水层噪声超过阈值的洞穴形成地下水
+func (tg *TerrainGenerator) floodedCave(x, y, height int) bool {
+	if height > SeaLevel {
+		return true
+	}
+	return y-height >= AquiferMinDepth && tg.aquiferNoise(x, y) > AquiferThreshold
+}
+
+// waterBlocks 返回海平面到地表以上之间落在minY~maxY行内的水方块
+func waterBlocks(worldX, height, minY, maxY int) []Block {
+	var blocks []Block
+	for y := max(SeaLevel, minY); y <= min(height-1, maxY); y++ {
+		blocks = append(blocks, Block{
+			X:    float64(worldX * BlockSize),
+			Y:    float64(y * BlockSize),
+			W:    BlockSize,
+			H:    BlockSize,
+			Type: ItemTypeWater,
+		})
+	}
+	return blocks
+}
@@ -0,0 +1,97 @@
+package world
+
+import (
+	"testing"
+)
+
+// columnCells 生成包含整个地形柱的区块，返回该列各格的方块类型
+func columnCells(tg *TerrainGenerator, x int) map[int]ItemType {
+	height := tg.getHeight(x)
+	cells := make(map[int]ItemType)
+	cx := FloorDiv(x, ChunkSize)
+	for cy := FloorDiv(min(height, SeaLevel), ChunkSize); cy <= FloorDiv(height+TerrainDepth, ChunkSize); cy++ {
+		for _, block := range tg.GenerateChunk(cx, cy).Blocks {
+			for _, c := range BlockCells(block) {
+				if c.X == x {
+					cells[c.Y] = block.Type
+				}
+			}
+		}
+	}
+	return cells
+}
+
+func TestOceans(t *testing.T) {
+	tg := NewTerrainGenerator(TerrainSeed)
+	biomes := make(map[TerrainType]int)
+	ocean, beach := 0, 0
+	for x := -3000; x < 3000; x++ {
+		terrainType := tg.getTerrainType(x)
+		biomes[terrainType]++
+		switch terrainType {
+		case TerrainTypeOcean:
+			if h := tg.getHeight(x); h <= SeaLevel {
+				t.Fatalf("ocean column %d has surface %d at or above sea level %d", x, h, SeaLevel)
+			}
+			if ocean == 0 {
+				ocean = x
+			}
+		case TerrainTypeBeach:
+			beach = x
+		}
+	}
+	if ocean == 0 || beach == 0 {
+		t.Fatalf("no ocean or beach within 3000 blocks: %v", biomes)
+	}
+
+	// 海洋列：海底为沙子，海底以上直到海平面充满水
+	height := tg.getHeight(ocean)
+	cells := columnCells(tg, ocean)
+	if cells[height] != ItemTypeSand {
+		t.Errorf("ocean floor at (%d, %d) is %v, want sand", ocean, height, cells[height])
+	}
+	for y := SeaLevel; y < height; y++ {
+		if cells[y] != ItemTypeWater {
+			t.Fatalf("ocean cell (%d, %d) is %v, want water", ocean, y, cells[y])
+		}
+	}
+	if _, ok := cells[SeaLevel-1]; ok {
+		t.Errorf("block above sea level in ocean column %d", ocean)
+	}
+
+	// 海平面附近的地表为沙滩
+	for x := beach - 200; x < beach+200; x++ {
+		h := tg.getHeight(x)
+		if h <= SeaLevel && h >= SeaLevel-BeachHeight && tg.getBlockType(x, h, h, tg.getTerrainType(x)) != ItemTypeSand {
+			t.Fatalf("shoreline surface at (%d, %d) is not sand", x, h)
+		}
+	}
+}
+
+func TestAquifers(t *testing.T) {
+	tg := NewTerrainGenerator(TerrainSeed)
+	flooded, dry := 0, 0
+	for x := -2000; x < 2000; x++ {
+		height := tg.getHeight(x)
+		if height > SeaLevel {
+			continue
+		}
+		for depth := 0; depth < TerrainDepth; depth++ {
+			y := height + depth
+			if !tg.hasCave(x, y, height) {
+				continue
+			}
+			if !tg.floodedCave(x, y, height) {
+				dry++
+				continue
+			}
+			if depth < AquiferMinDepth {
+				t.Fatalf("aquifer at (%d, %d), only %d below the surface", x, y, depth)
+			}
+			flooded++
+		}
+	}
+	if flooded == 0 || dry == 0 {
+		t.Fatalf("%d flooded and %d dry cave cells, want both", flooded, dry)
+	}
+}
@@ -0,0 +1,250 @@
+// Package world 实现世界的方块数据和区块生成：地形、洞穴、矿石、水体、结构，
+// 以及各种世界类型的区块生成器。
+//
+// 生成只依赖种子和区块坐标，不依赖游戏状态，因此游戏、命令行工具和服务器
+// 可以共用同一套生成代码。
+//
+// 方块坐标与游戏的屏幕坐标方向相同：y向下增大，重力指向y增大的方向。
+// 列的地表高度是地表方块所在的行，地表以下的深度为y减去地表高度，
+// 树木和地面结构向y减小的方向生长。
+package world
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// 世界尺寸常量
+const (
+	BlockSize    = 50    // 方块的世界尺寸
+	ChunkSize    = 10    // 每个区块的方块数
+	TerrainDepth = 64    // 地形柱的格数（包括地表，矿石分布在这一范围内）
+	TerrainSeed  = 12345 // 默认地形生成种子
+)
+
+// ItemType 定义游戏中可用的方块类型
+type ItemType int
+
+// 方块类型常量定义
+const (
+	ItemTypeGrass      ItemType = iota // 草地
+	ItemTypeDirt                       // 泥土
+	ItemTypeStone                      // 石头
+	ItemTypeSand                       // 沙子
+	ItemTypeWood                       // 木头
+	ItemTypeWater                      // 水
+	ItemTypeLava                       // 岩浆
+	ItemTypeSnow                       // 雪
+	ItemTypeCoalOre                    // 煤矿石
+	ItemTypeIronOre                    // 铁矿石
+	ItemTypeGoldOre                    // 金矿石
+	ItemTypeDiamondOre                 // 钻石矿石
+)
+
+// Item 定义游戏中可用的物品结构
+type Item struct {
+	Type        ItemType
+	Name        string
+	Color       color.RGBA
+	Description string
+	Light       int  // 发光强度（0表示不发光）
+	Liquid      bool // 液体：实体和光线可以穿过，不阻挡寻路
+}
+
+// ItemRegistry 全局物品注册表，包含所有可用方块类型及其属性
+var ItemRegistry = map[ItemType]Item{
+	ItemTypeGrass: {
+		Type:        ItemTypeGrass,
+		Name:        "Grass",
+		Color:       color.RGBA{50, 180, 50, 255},
+		Description: "Green grass block",
+	},
+	ItemTypeDirt: {
+		Type:        ItemTypeDirt,
+		Name:        "Dirt",
+		Color:       color.RGBA{150, 100, 50, 255},
+		Description: "Brown dirt block",
+	},
+	ItemTypeStone: {
+		Type:        ItemTypeStone,
+		Name:        "Stone",
+		Color:       color.RGBA{100, 100, 100, 255},
+		Description: "Gray stone block",
+	},
+	ItemTypeSand: {
+		Type:        ItemTypeSand,
+		Name:        "Sand",
+		Color:       color.RGBA{255, 220, 100, 255},
+		Description: "Golden sand block",
+	},
+	ItemTypeWood: {
+		Type:        ItemTypeWood,
+		Name:        "Wood",
+		Color:       color.RGBA{150, 100, 50, 255},
+		Description: "Brown wood block",
+	},
+	ItemTypeWater: {
+		Type:        ItemTypeWater,
+		Name:        "Water",
+		Color:       color.RGBA{50, 100, 255, 200},
+		Description: "Blue water block",
+		Liquid:      true,
+	},
+	ItemTypeLava: {
+		Type:        ItemTypeLava,
+		Name:        "Lava",
+		Color:       color.RGBA{255, 100, 0, 200},
+		Description: "Hot lava block",
+		Light:       14,
+		Liquid:      true,
+	},
+	ItemTypeSnow: {
+		Type:        ItemTypeSnow,
+		Name:        "Snow",
+		Color:       color.RGBA{230, 230, 255, 255},
+		Description: "White snow block",
+	},
+	ItemTypeCoalOre: {
+		Type:        ItemTypeCoalOre,
+		Name:        "Coal Ore",
+		Color:       color.RGBA{45, 45, 50, 255},
+		Description: "Stone with coal veins",
+	},
+	ItemTypeIronOre: {
+		Type:        ItemTypeIronOre,
+		Name:        "Iron Ore",
+		Color:       color.RGBA{190, 140, 110, 255},
+		Description: "Stone with iron veins",
+	},
+	ItemTypeGoldOre: {
+		Type:        ItemTypeGoldOre,
+		Name:        "Gold Ore",
+		Color:       color.RGBA{240, 200, 40, 255},
+		Description: "Stone with gold veins",
+	},
+	ItemTypeDiamondOre: {
+		Type:        ItemTypeDiamondOre,
+		Name:        "Diamond Ore",
+		Color:       color.RGBA{90, 220, 230, 255},
+		Description: "Stone with diamonds",
+	},
+}
+
+// TerrainType 定义地形类型枚举
+type TerrainType int
+
+// 地形类型常量定义
+const (
+	TerrainTypePlains      TerrainType = iota // 平原
+	TerrainTypeHills                          // 丘陵
+	TerrainTypeMountains                      // 山脉
+	TerrainTypeDesert                         // 沙漠
+	TerrainTypeForest                         // 森林
+	TerrainTypeSnowyPlains                    // 雪原
+	TerrainTypeSwamp                          // 沼泽
+	TerrainTypeJungle                         // 丛林
+	TerrainTypeTaiga                          // 针叶林
+	TerrainTypeSavanna                        // 热带草原
+	TerrainTypeCanyon                         // 峡谷
+	TerrainTypeOcean                          // 海洋
+	TerrainTypeBeach                          // 海滩
+)
+
+// terrainTypeNames 地形类型名称
+var terrainTypeNames = map[TerrainType]string{
+	TerrainTypePlains:      "Plains",
+	TerrainTypeHills:       "Hills",
+	TerrainTypeMountains:   "Mountains",
+	TerrainTypeDesert:      "Desert",
+	TerrainTypeForest:      "Forest",
+	TerrainTypeSnowyPlains: "Snowy Plains",
+	TerrainTypeSwamp:       "Swamp",
+	TerrainTypeJungle:      "Jungle",
+	TerrainTypeTaiga:       "Taiga",
+	TerrainTypeSavanna:     "Savanna",
+	TerrainTypeCanyon:      "Canyon",
+	TerrainTypeOcean:       "Ocean",
+	TerrainTypeBeach:       "Beach",
+}
+
+// String 返回地形类型名称
+func (t TerrainType) String() string {
+	if name, ok := terrainTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TerrainType(%d)", int(t))
+}
+
+// Block 定义游戏中的方块结构
+type Block struct {
+	X, Y, W, H float64
+	Type       ItemType // 方块类型
+}
+
+// Chunk 定义地形区块结构
+type Chunk struct {
+	X, Y   int
+	Blocks []Block
+}
+
+// ItemTypeByName 按名称（不区分大小写）查找物品类型
+func ItemTypeByName(name string) (ItemType, bool) {
+	for t, item := range ItemRegistry {
+		if strings.EqualFold(item.Name, name) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// Solid 判断方块是否为实心（阻挡实体、光线和寻路），液体和未注册的类型不是实心
+func (t ItemType) Solid() bool {
+	item, ok := ItemRegistry[t]
+	return ok && !item.Liquid
+}
+
+// GridPos 方块网格坐标
+type GridPos struct {
+	X, Y int
+}
+
+// BlockCells 返回方块占据的格子，宽方块（如树冠）会占据多个格子
+func BlockCells(block Block) []GridPos {
+	x0 := int(math.Floor(block.X / BlockSize))
+	y0 := int(math.Floor(block.Y / BlockSize))
+	x1 := int(math.Ceil((block.X+block.W)/BlockSize)) - 1
+	y1 := int(math.Ceil((block.Y+block.H)/BlockSize)) - 1
+	cells := make([]GridPos, 0, (x1-x0+1)*(y1-y0+1))
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			cells = append(cells, GridPos{x, y})
+		}
+	}
+	return cells
+}
+
+// ChunkOf 返回格子所在的区块坐标
+func ChunkOf(x, y int) GridPos {
+	return GridPos{FloorDiv(x, ChunkSize), FloorDiv(y, ChunkSize)}
+}
+
+// FloorDiv 向下取整的整数除法
+func FloorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// Rect 格子坐标矩形（包含边界）
+type Rect struct {
+	MinX, MinY, MaxX, MaxY int
+}
+
+// Contains 判断格子是否在矩形内
+func (r Rect) Contains(p GridPos) bool {
+	return p.X >= r.MinX && p.X <= r.MaxX && p.Y >= r.MinY && p.Y <= r.MaxY
+}
@@ -0,0 +1,211 @@
+package world
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChunkGenerator 区块生成器
+//
+// 生成结果只由生成器的参数和区块坐标决定，与区块的生成顺序无关。
+type ChunkGenerator interface {
+	// GenerateChunk 生成区块的方块
+	GenerateChunk(chunkX, chunkY int) *Chunk
+	// SurfaceHeight 返回列的地表高度，即地表方块所在的行（出生点位于其上）
+	SurfaceHeight(x int) int
+	// Biome 返回列的地形类型（用于生物生成和视差背景）
+	Biome(x int) TerrainType
+}
+
+// 世界类型名称
+const (
+	WorldDefault     = "default"   // 默认地形
+	WorldAmplified   = "amplified" // 起伏放大的默认地形
+	WorldFlat        = "flat"      // 超平坦，可以用"flat:层列表"指定各层
+	WorldSkyblock    = "skyblock"  // 虚空中的一座空岛
+	WorldVoid        = "void"      // 虚空，只有出生点的平台
+	WorldSingleBiome = "single"    // 单一地形，用"single:地形名称"指定
+)
+
+// 世界类型参数
+const (
+	AmplifiedHeightScale = 2.5 // 放大化世界的地形起伏倍数
+	FlatSurface          = 5   // 超平坦世界和空岛的地表高度
+)
+
+// DefaultFlatLayers 超平坦世界的默认分层（从地表向下）
+const DefaultFlatLayers = "grass,dirt*3,stone*40"
+
+// NewChunkGenerator 按世界类型创建区块生成器
+//
+// spec为default、amplified、flat[:层列表]、skyblock、void或single:地形名称，
+// 层列表见ParseFlatLayers。
+func NewChunkGenerator(spec string, seed int64) (ChunkGenerator, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch strings.ToLower(kind) {
+	case "", WorldDefault:
+		return NewTerrainGenerator(seed), nil
+	case WorldAmplified:
+		tg := NewTerrainGenerator(seed)
+		tg.HeightScale = AmplifiedHeightScale
+		return tg, nil
+	case WorldFlat:
+		if arg == "" {
+			arg = DefaultFlatLayers
+		}
+		layers, err := ParseFlatLayers(arg)
+		if err != nil {
+			return nil, err
+		}
+		return &FlatGenerator{Layers: layers, Surface: FlatSurface, TerrainType: TerrainTypePlains}, nil
+	case WorldSkyblock:
+		return &VoidGenerator{Island: skyblockIsland, SurfaceRow: 3}, nil
+	case WorldVoid:
+		return &VoidGenerator{Island: voidPlatform}, nil
+	case WorldSingleBiome:
+		biome, ok := terrainTypeByName(arg)
+		if !ok {
+			return nil, fmt.Errorf("unknown terrain type %q", arg)
+		}
+		tg := NewTerrainGenerator(seed)
+		tg.SingleBiome, tg.FixedBiome = true, biome
+		return tg, nil
+	default:
+		return nil, fmt.Errorf("unknown world type %q", kind)
+	}
+}
+
+// SurfaceHeight 实现ChunkGenerator接口
+func (tg *TerrainGenerator) SurfaceHeight(x int) int {
+	return tg.getHeight(x)
+}
+
+// Biome 实现ChunkGenerator接口
+func (tg *TerrainGenerator) Biome(x int) TerrainType {
+	return tg.getTerrainType(x)
+}
+
+// FlatLayer 超平坦世界的一层
+type FlatLayer struct {
+	Type  ItemType
+	Count int // 层的厚度（格数）
+}
+
+// ParseFlatLayers 解析逗号分隔的分层（从地表向下），每层为"方块名称"或"方块名称*厚度"
+func ParseFlatLayers(s string) ([]FlatLayer, error) {
+	var layers []FlatLayer
+	for _, part := range strings.Split(s, ",") {
+		name, count := strings.TrimSpace(part), 1
+		if n, c, found := strings.Cut(name, "*"); found {
+			v, err := strconv.Atoi(strings.TrimSpace(c))
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("invalid layer thickness in %q", part)
+			}
+			name, count = strings.TrimSpace(n), v
+		}
+		t, ok := ItemTypeByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown block type %q", name)
+		}
+		layers = append(layers, FlatLayer{Type: t, Count: count})
+	}
+	return layers, nil
+}
+
+// FlatGenerator 超平坦世界：每列都由相同的分层组成
+type FlatGenerator struct {
+	Layers      []FlatLayer // 从地表向下的各层
+	Surface     int         // 最上层（地表）所在的行
+	TerrainType TerrainType
+}
+
+// layerAt 返回地表以下depth格的方块类型，超出所有层时返回false
+func (f *FlatGenerator) layerAt(depth int) (ItemType, bool) {
+	for _, layer := range f.Layers {
+		if depth < layer.Count {
+			return layer.Type, true
+		}
+		depth -= layer.Count
+	}
+	return 0, false
+}
+
+// GenerateChunk 实现ChunkGenerator接口
+func (f *FlatGenerator) GenerateChunk(chunkX, chunkY int) *Chunk {
+	chunk := &Chunk{X: chunkX, Y: chunkY}
+	for y := chunkY * ChunkSize; y < (chunkY+1)*ChunkSize; y++ {
+		t, ok := f.layerAt(y - f.Surface)
+		if !ok || y < f.Surface {
+			continue
+		}
+		for x := chunkX * ChunkSize; x < (chunkX+1)*ChunkSize; x++ {
+			chunk.Blocks = append(chunk.Blocks, Block{
+				X:    float64(x * BlockSize),
+				Y:    float64(y * BlockSize),
+				W:    BlockSize,
+				H:    BlockSize,
+				Type: t,
+			})
+		}
+	}
+	return chunk
+}
+
+// SurfaceHeight 实现ChunkGenerator接口
+func (f *FlatGenerator) SurfaceHeight(x int) int {
+	return f.Surface
+}
+
+// Biome 实现ChunkGenerator接口
+func (f *FlatGenerator) Biome(x int) TerrainType {
+	return f.TerrainType
+}
+
+// 空岛：行按世界y递增排列（与预制结构相同），第3行为地表
+var skyblockIsland = parsePrefab([]string{
+	"GGG    ", // 树冠
+	" W     ", // 树干
+	" W     ",
+	"GGGGGGG",
+	"DDDDDDD",
+	" DDDDD ",
+	"  SSS  ",
+	"   S   ",
+}, map[rune]ItemType{'S': ItemTypeStone, 'D': ItemTypeDirt, 'G': ItemTypeGrass, 'W': ItemTypeWood})
+
+// 虚空世界出生点下方的平台
+var voidPlatform = parsePrefab([]string{"SSS"}, prefabPalette)
+
+// VoidGenerator 虚空世界：除了出生点下方的岛屿之外没有方块
+type VoidGenerator struct {
+	Island     *Prefab // 出生点的岛屿，为nil时整个世界为空
+	SurfaceRow int     // 岛屿中地表所在的行
+}
+
+// islandStructure 返回放置在出生点的岛屿，地表行位于FlatSurface
+func (v *VoidGenerator) islandStructure() PlacedStructure {
+	return PlacedStructure{
+		Structure: &Structure{Name: "island", Prefab: v.Island},
+		Origin:    GridPos{-v.Island.W / 2, FlatSurface - v.SurfaceRow},
+	}
+}
+
+// GenerateChunk 实现ChunkGenerator接口
+func (v *VoidGenerator) GenerateChunk(chunkX, chunkY int) *Chunk {
+	chunk := &Chunk{X: chunkX, Y: chunkY}
+	if v.Island != nil {
+		chunk.Blocks = structureBlocks([]PlacedStructure{v.islandStructure()}, chunkX, chunkY)
+	}
+	return chunk
+}
+
+// SurfaceHeight 实现ChunkGenerator接口，虚空中返回出生点岛屿的地表高度
+func (v *VoidGenerator) SurfaceHeight(x int) int {
+	return FlatSurface
+}
+
+// Biome 实现ChunkGenerator接口
+func (v *VoidGenerator) Biome(x int) TerrainType {
+	return TerrainTypePlains
+}
@@ -0,0 +1,183 @@
+package world
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// worldSpecs 测试覆盖的世界类型
+var worldSpecs = []string{"default", "amplified", "flat", "flat:sand,stone*2", "skyblock", "void", "single:desert", "single:ocean"}
+
+func TestGeneratorsDeterministic(t *testing.T) {
+	var positions []GridPos
+	for cx := -3; cx <= 3; cx++ {
+		for cy := -8; cy <= 1; cy++ {
+			positions = append(positions, GridPos{cx, cy})
+		}
+	}
+	shuffled := append([]GridPos(nil), positions...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	for _, spec := range worldSpecs {
+		a, err := NewChunkGenerator(spec, TerrainSeed)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		b, _ := NewChunkGenerator(spec, TerrainSeed)
+		forward := make(map[GridPos]string)
+		blocks := 0
+		for _, p := range positions {
+			chunk := a.GenerateChunk(p.X, p.Y)
+			forward[p] = chunkSignature(chunk)
+			blocks += len(chunk.Blocks)
+			for _, block := range chunk.Blocks {
+				if cy := FloorDiv(int(math.Floor(block.Y/BlockSize)), ChunkSize); cy != p.Y {
+					t.Fatalf("%s: chunk %v generated a block in row %d", spec, p, cy)
+				}
+			}
+		}
+		for _, p := range shuffled {
+			if got := chunkSignature(b.GenerateChunk(p.X, p.Y)); got != forward[p] {
+				t.Fatalf("%s: chunk %v differs between generation orders", spec, p)
+			}
+		}
+		if blocks == 0 {
+			t.Errorf("%s: no blocks generated around spawn", spec)
+		}
+		// 出生点位于地表之上：地表方块存在，其上（y更小）的两格为空气（海洋世界中为水）
+		surface := a.SurfaceHeight(0)
+		if !generatesCell(a, GridPos{0, surface}) {
+			t.Errorf("%s: no surface block at spawn", spec)
+		}
+		for y := surface - 2; y < surface; y++ {
+			if typ, ok := cellType(a, GridPos{0, y}); ok && typ.Solid() {
+				t.Errorf("%s: cell (0, %d) above the spawn surface is %s, want air or liquid", spec, y, ItemRegistry[typ].Name)
+			}
+		}
+	}
+}
+
+// generatesCell 判断生成器是否在格子处生成方块
+func generatesCell(gen ChunkGenerator, cell GridPos) bool {
+	_, ok := cellType(gen, cell)
+	return ok
+}
+
+// cellType 返回生成器在格子处生成的方块类型，没有方块时返回false
+func cellType(gen ChunkGenerator, cell GridPos) (ItemType, bool) {
+	c := ChunkOf(cell.X, cell.Y)
+	for _, block := range gen.GenerateChunk(c.X, c.Y).Blocks {
+		for _, p := range BlockCells(block) {
+			if p == cell {
+				return block.Type, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func TestTerrainSeeds(t *testing.T) {
+	// 地形只由种子决定：全局随机数的状态不影响生成，不同的种子生成不同的地形
+	heights := func(seed int64) []int {
+		rand.Shuffle(10, func(i, j int) {})
+		tg := NewTerrainGenerator(seed)
+		h := make([]int, 200)
+		for x := range h {
+			h[x] = tg.SurfaceHeight(x * 7)
+		}
+		return h
+	}
+	a, b, c := heights(TerrainSeed), heights(TerrainSeed), heights(TerrainSeed+1)
+	same, differs := true, false
+	for x := range a {
+		same = same && a[x] == b[x]
+		differs = differs || a[x] != c[x]
+	}
+	if !same {
+		t.Error("the same seed gives different surface heights")
+	}
+	if !differs {
+		t.Error("seeds differing by one give identical surface heights")
+	}
+}
+
+func TestFlatGenerator(t *testing.T) {
+	gen, err := NewChunkGenerator("flat:grass,dirt*2,stone", TerrainSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]ItemType{FlatSurface: ItemTypeGrass, FlatSurface + 1: ItemTypeDirt, FlatSurface + 2: ItemTypeDirt, FlatSurface + 3: ItemTypeStone}
+	for _, x := range []int{-37, 0, 123} {
+		cells := make(map[int]ItemType)
+		for cy := -1; cy <= 2; cy++ {
+			for _, block := range gen.GenerateChunk(FloorDiv(x, ChunkSize), cy).Blocks {
+				for _, p := range BlockCells(block) {
+					if p.X == x {
+						cells[p.Y] = block.Type
+					}
+				}
+			}
+		}
+		if len(cells) != len(want) {
+			t.Fatalf("column %d has %d blocks, want %d", x, len(cells), len(want))
+		}
+		for y, typ := range want {
+			if cells[y] != typ {
+				t.Errorf("cell (%d, %d) = %v, want %v", x, y, cells[y], typ)
+			}
+		}
+	}
+
+	for _, bad := range []string{"flat:grass,bogus", "flat:dirt*0", "flat:stone*x"} {
+		if _, err := NewChunkGenerator(bad, TerrainSeed); err == nil {
+			t.Errorf("NewChunkGenerator(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestVoidGenerators(t *testing.T) {
+	for _, spec := range []string{"void", "skyblock"} {
+		gen, _ := NewChunkGenerator(spec, TerrainSeed)
+		if n := len(gen.GenerateChunk(5, 0).Blocks) + len(gen.GenerateChunk(0, -5).Blocks); n != 0 {
+			t.Errorf("%s: %d blocks away from spawn", spec, n)
+		}
+	}
+	gen, _ := NewChunkGenerator("skyblock", TerrainSeed)
+	if !generatesCell(gen, GridPos{-2, FlatSurface - 1}) {
+		t.Error("skyblock island has no tree above the spawn surface")
+	}
+}
+
+func TestTerrainWorldTypes(t *testing.T) {
+	def, _ := NewChunkGenerator("default", TerrainSeed)
+	amp, _ := NewChunkGenerator("amplified", TerrainSeed)
+	spread := func(gen ChunkGenerator) int {
+		lo, hi := gen.SurfaceHeight(0), gen.SurfaceHeight(0)
+		for x := -2000; x < 2000; x++ {
+			h := gen.SurfaceHeight(x)
+			lo, hi = min(lo, h), max(hi, h)
+		}
+		return hi - lo
+	}
+	if d, a := spread(def), spread(amp); a <= d {
+		t.Errorf("amplified height range %d is not larger than default %d", a, d)
+	}
+
+	desert, _ := NewChunkGenerator("single:desert", TerrainSeed)
+	ocean, _ := NewChunkGenerator("single:ocean", TerrainSeed)
+	for x := -500; x < 500; x += 7 {
+		if b := desert.Biome(x); b != TerrainTypeDesert {
+			t.Fatalf("single:desert biome at %d = %s", x, b)
+		}
+		if h := ocean.SurfaceHeight(x); h <= SeaLevel {
+			t.Fatalf("single:ocean surface at %d is %d, above sea level", x, h)
+		}
+	}
+
+	for _, bad := range []string{"single:volcano", "nether"} {
+		if _, err := NewChunkGenerator(bad, TerrainSeed); err == nil {
+			t.Errorf("NewChunkGenerator(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// 地图相关常量（界面逻辑像素）
+const (
+	MinimapWidth   = 160 // 小地图宽度
+	MinimapHeight  = 120 // 小地图高度
+	MinimapScale   = 2.0 // 小地图每个方块的像素数
+	MinimapMargin  = 10  // 小地图与屏幕边缘的距离
+	MapMinScale    = 0.25
+	MapMaxScale    = 8.0
+	MapPanSpeed    = 8.0 // 键盘平移速度（屏幕像素/帧）
+	MapMarkerSize  = 4.0
+	MapDefaultZoom = 2.0 // 打开全屏地图时每个方块的像素数
+)
+
+// 地图配色
+var (
+	mapBackground = color.RGBA{20, 24, 40, 255}
+	mapBorder     = color.RGBA{0, 0, 0, 200}
+	mapPlayer     = color.RGBA{255, 0, 0, 255}
+	mapSpawn      = color.RGBA{255, 220, 0, 255}
+)
+
+// mapTile 一个区块在地图上的贴图（每个方块一个像素）
+type mapTile struct {
+	pixels []byte // 预乘alpha的RGBA像素，dirty时需要从方块网格重新生成
+	dirty  bool
+	image  *ebiten.Image
+	upload bool // pixels已更新，绘制前需要写入image
+}
+
+// WorldMap 由方块网格生成的世界地图，按区块缓存，方块变化时重新生成
+//
+// 区块卸载后贴图保留为探索过的地图，并随存档保存（见WorldSave.Map），
+// 因此全屏地图可以浏览所有加载过的区块。
+type WorldMap struct {
+	tiles map[GridPos]*mapTile
+
+	// 全屏地图状态
+	Open             bool
+	centerX, centerY float64 // 地图中心（方块坐标）
+	scale            float64 // 每个方块的像素数
+	dragging         bool
+	dragX, dragY     int
+
+	minimap *ebiten.Image // 小地图离屏图像（用于裁剪）
+}
+
+// NewWorldMap 创建空地图
+func NewWorldMap() *WorldMap {
+	return &WorldMap{
+		tiles: make(map[GridPos]*mapTile),
+		scale: MapDefaultZoom,
+	}
+}
+
+// MarkDirty 标记包含这些格子的区块需要重新生成（没有贴图的区块会新建）
+//
+// 只应标记已加载的区块，否则贴图会按空的方块网格重新生成。
+func (m *WorldMap) MarkDirty(cells []GridPos) {
+	for _, p := range cells {
+		pos := chunkOf(p.X, p.Y)
+		if tile, ok := m.tiles[pos]; ok {
+			tile.dirty = true
+		} else {
+			m.tiles[pos] = &mapTile{dirty: true}
+		}
+	}
+}
+
+// mapTilePixels 生成区块的RGBA像素，方块使用注册表颜色，空气透明
+func mapTilePixels(grid blockGrid, chunk GridPos) []byte {
+	pixels := make([]byte, ChunkSize*ChunkSize*4)
+	for ly := 0; ly < ChunkSize; ly++ {
+		for lx := 0; lx < ChunkSize; lx++ {
+			blockType, ok := grid[GridPos{chunk.X*ChunkSize + lx, chunk.Y*ChunkSize + ly}]
+			if !ok {
+				continue
+			}
+			c := color.RGBA{100, 200, 100, 255} // 默认绿色
+			if item, exists := itemRegistry[blockType]; exists {
+				c = item.Color
+			}
+			// WritePixels需要预乘alpha的颜色
+			i := (ly*ChunkSize + lx) * 4
+			pixels[i] = uint8(uint16(c.R) * uint16(c.A) / 255)
+			pixels[i+1] = uint8(uint16(c.G) * uint16(c.A) / 255)
+			pixels[i+2] = uint8(uint16(c.B) * uint16(c.A) / 255)
+			pixels[i+3] = c.A
+		}
+	}
+	return pixels
+}
+
+// refreshTile 按方块网格重新生成被标记的贴图像素
+func (m *WorldMap) refreshTile(grid blockGrid, pos GridPos, tile *mapTile) {
+	if tile.dirty {
+		tile.pixels = mapTilePixels(grid, pos)
+		tile.dirty = false
+		tile.upload = true
+	}
+}
+
+// Refresh 按方块网格重新生成区块被标记的贴图，在区块卸载前调用以保留探索过的地图
+func (m *WorldMap) Refresh(grid blockGrid, chunk GridPos) {
+	if tile, ok := m.tiles[chunk]; ok {
+		m.refreshTile(grid, chunk, tile)
+	}
+}
+
+// MapTileSave 存档中一个探索过的区块的地图贴图
+type MapTileSave struct {
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Pixels []byte `json:"pixels"` // 预乘alpha的RGBA像素（JSON中为base64）
+}
+
+// SavedTiles 返回按坐标排序的所有贴图，被标记的贴图先按方块网格重新生成
+func (m *WorldMap) SavedTiles(grid blockGrid) []MapTileSave {
+	tiles := make([]MapTileSave, 0, len(m.tiles))
+	for pos, tile := range m.tiles {
+		m.refreshTile(grid, pos, tile)
+		tiles = append(tiles, MapTileSave{X: pos.X, Y: pos.Y, Pixels: tile.pixels})
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		if tiles[i].X != tiles[j].X {
+			return tiles[i].X < tiles[j].X
+		}
+		return tiles[i].Y < tiles[j].Y
+	})
+	return tiles
+}
+
+// LoadTiles 加入存档中的贴图，像素大小不符的贴图被忽略
+func (m *WorldMap) LoadTiles(tiles []MapTileSave) {
+	for _, t := range tiles {
+		if len(t.Pixels) != ChunkSize*ChunkSize*4 {
+			continue
+		}
+		m.tiles[GridPos{t.X, t.Y}] = &mapTile{pixels: t.Pixels, upload: true}
+	}
+}
+
+// drawRegion 将以(centerX, centerY)方块坐标为中心、每方块scale像素的区域绘制到dst
+func (m *WorldMap) drawRegion(dst *ebiten.Image, grid blockGrid, centerX, centerY, scale float64) {
+	w, h := float64(dst.Bounds().Dx()), float64(dst.Bounds().Dy())
+	minBX, minBY := centerX-w/2/scale, centerY-h/2/scale
+	from := chunkOf(int(math.Floor(minBX)), int(math.Floor(minBY)))
+	to := chunkOf(int(math.Ceil(minBX+w/scale)), int(math.Ceil(minBY+h/scale)))
+
+	draw := func(pos GridPos, tile *mapTile) {
+		if pos.X < from.X || pos.X > to.X || pos.Y < from.Y || pos.Y > to.Y {
+			return
+		}
+		m.refreshTile(grid, pos, tile)
+		if tile.upload {
+			if tile.image == nil {
+				tile.image = ebiten.NewImage(ChunkSize, ChunkSize)
+			}
+			tile.image.WritePixels(tile.pixels)
+			tile.upload = false
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(math.Round((float64(pos.X*ChunkSize)-minBX)*scale), math.Round((float64(pos.Y*ChunkSize)-minBY)*scale))
+		dst.DrawImage(tile.image, op)
+	}
+
+	// 视野很大（缩得很小）时直接遍历已有贴图
+	if (to.X-from.X+1)*(to.Y-from.Y+1) > len(m.tiles) {
+		for pos, tile := range m.tiles {
+			draw(pos, tile)
+		}
+		return
+	}
+	for cy := from.Y; cy <= to.Y; cy++ {
+		for cx := from.X; cx <= to.X; cx++ {
+			if tile, ok := m.tiles[GridPos{cx, cy}]; ok {
+				draw(GridPos{cx, cy}, tile)
+			}
+		}
+	}
+}
+
+// drawMarker 在地图上标记世界坐标(worldX, worldY)
+func drawMarker(dst *ebiten.Image, worldX, worldY, centerX, centerY, scale float64, clr color.Color) (float64, float64) {
+	w, h := float64(dst.Bounds().Dx()), float64(dst.Bounds().Dy())
+	x := (worldX/BlockSize-centerX)*scale + w/2
+	y := (worldY/BlockSize-centerY)*scale + h/2
+	ebitenutil.DrawRect(dst, x-MapMarkerSize/2, y-MapMarkerSize/2, MapMarkerSize, MapMarkerSize, clr)
+	return x, y
+}
+
+// toggleMap 打开或关闭全屏地图，打开时以玩家为中心
+func (g *Game) toggleMap() {
+	m := g.worldMap
+	m.Open = !m.Open
+	m.dragging = false
+	if m.Open {
+		m.centerX = g.player.CenterX() / BlockSize
+		m.centerY = g.player.CenterY() / BlockSize
+	}
+}
+
+// updateMapView 全屏地图的平移（拖动或方向键）与缩放（滚轮，以光标为中心）
+func (g *Game) updateMapView() {
+	m := g.worldMap
+	scale := g.pixelScale()
+	cx, cy := ebiten.CursorPosition()
+
+	// 拖动平移
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		m.dragging = true
+		m.dragX, m.dragY = cx, cy
+	} else if m.dragging && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		m.centerX -= float64(cx-m.dragX) / scale / m.scale
+		m.centerY -= float64(cy-m.dragY) / scale / m.scale
+		m.dragX, m.dragY = cx, cy
+	} else {
+		m.dragging = false
+	}
+
+	// 键盘平移
+	step := MapPanSpeed / m.scale
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) || ebiten.IsKeyPressed(ebiten.KeyA) {
+		m.centerX -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) || ebiten.IsKeyPressed(ebiten.KeyD) {
+		m.centerX += step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowUp) || ebiten.IsKeyPressed(ebiten.KeyW) {
+		m.centerY -= step
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		m.centerY += step
+	}
+
+	// 滚轮缩放，光标下的方块保持不动
+	if _, wheelY := ebiten.Wheel(); wheelY != 0 {
+		w, h := g.viewSize()
+		offX := (float64(cx) - w/2) / scale
+		offY := (float64(cy) - h/2) / scale
+		bx, by := m.centerX+offX/m.scale, m.centerY+offY/m.scale
+		m.scale = math.Max(MapMinScale, math.Min(MapMaxScale, m.scale*math.Pow(ZoomStep, wheelY)))
+		m.centerX, m.centerY = bx-offX/m.scale, by-offY/m.scale
+	}
+}
+
+// drawMinimap 在界面右上角绘制以玩家为中心的小地图
+func (g *Game) drawMinimap(hud *ebiten.Image) {
+	m := g.worldMap
+	if m.minimap == nil {
+		m.minimap = ebiten.NewImage(MinimapWidth, MinimapHeight)
+	}
+	m.minimap.Fill(mapBackground)
+	centerX, centerY := g.player.CenterX()/BlockSize, g.player.CenterY()/BlockSize
+	m.drawRegion(m.minimap, g.pathGrid(), centerX, centerY, MinimapScale)
+	drawMarker(m.minimap, g.spawnX, g.spawnY, centerX, centerY, MinimapScale, mapSpawn)
+	drawMarker(m.minimap, g.player.CenterX(), g.player.CenterY(), centerX, centerY, MinimapScale, mapPlayer)
+
+	x := float64(hud.Bounds().Dx() - MinimapWidth - MinimapMargin)
+	y := float64(MinimapMargin)
+	ebitenutil.DrawRect(hud, x-2, y-2, MinimapWidth+4, MinimapHeight+4, mapBorder)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(x, y)
+	hud.DrawImage(m.minimap, op)
+}
+
+// drawFullMap 绘制覆盖整个界面的全屏地图
+func (g *Game) drawFullMap(hud *ebiten.Image) {
+	m := g.worldMap
+	hud.Fill(mapBackground)
+	m.drawRegion(hud, g.pathGrid(), m.centerX, m.centerY, m.scale)
+
+	sx, sy := drawMarker(hud, g.spawnX, g.spawnY, m.centerX, m.centerY, m.scale, mapSpawn)
+	ebitenutil.DebugPrintAt(hud, "Spawn", int(sx)+6, int(sy)-8)
+	px, py := drawMarker(hud, g.player.CenterX(), g.player.CenterY(), m.centerX, m.centerY, m.scale, mapPlayer)
+	ebitenutil.DebugPrintAt(hud, "You", int(px)+6, int(py)-8)
+
+	ebitenutil.DebugPrintAt(hud, fmt.Sprintf("Map (%.0f, %.0f) %.2fpx/block, %d chunks",
+		m.centerX, m.centerY, m.scale, len(m.tiles)), 10, 10)
+	ebitenutil.DebugPrintAt(hud, "Tab: Close  Drag/Arrows: Pan  Wheel: Zoom", 10, 30)
+}
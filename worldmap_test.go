@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+// mapTileAt 返回地图中区块的贴图像素
+func mapTileAt(t *testing.T, g *Game, chunk GridPos) []byte {
+	t.Helper()
+	for _, tile := range g.worldMap.SavedTiles(g.pathGrid()) {
+		if tile.X == chunk.X && tile.Y == chunk.Y {
+			return tile.Pixels
+		}
+	}
+	t.Fatalf("no map tile for chunk %v", chunk)
+	return nil
+}
+
+func TestMapKeepsUnloadedChunks(t *testing.T) {
+	g := newStreamingGame()
+	g.worldMap = NewWorldMap()
+	surface := g.terrain().SurfaceHeight(0)
+	spawn := chunkOf(0, surface)
+	g.moveTo(0, surface-1)
+	explored := append([]byte(nil), mapTileAt(t, g, spawn)...)
+	if bytes.Count(explored, []byte{0}) == len(explored) {
+		t.Fatal("spawn chunk map tile is empty")
+	}
+
+	// 区块卸载后地图仍然显示探索过的区块
+	g.moveTo(1000, surface-1)
+	if _, ok := g.chunks[chunkKey(spawn.X, spawn.Y)]; ok {
+		t.Fatal("spawn chunk still loaded")
+	}
+	if got := mapTileAt(t, g, spawn); !bytes.Equal(got, explored) {
+		t.Error("map tile changed after the chunk was unloaded")
+	}
+
+	// 探索过的地图随存档保存和读取
+	path := filepath.Join(t.TempDir(), "world.json")
+	if err := g.saveWorld(path); err != nil {
+		t.Fatal(err)
+	}
+	save, err := loadWorldSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := newStreamingGame()
+	loaded.worldMap = NewWorldMap()
+	loaded.applyWorldSave(save)
+	if got := mapTileAt(t, loaded, spawn); !bytes.Equal(got, explored) {
+		t.Error("map tile differs after loading the save")
+	}
+	if a, b := len(g.worldMap.SavedTiles(g.pathGrid())), len(loaded.worldMap.SavedTiles(loaded.pathGrid())); a != b {
+		t.Errorf("loaded %d map tiles, saved %d", b, a)
+	}
+}